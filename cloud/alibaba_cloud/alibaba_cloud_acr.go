@@ -0,0 +1,318 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+// acrTokenDefaultLifetime temporary login token is valid for 1 hour, used when response has no expire time
+const acrTokenDefaultLifetime = 1 * time.Hour
+
+type FetchAcrTokenOptions struct {
+	ForceNew bool
+}
+
+// AcrToken temporary login token of Container Registry
+type AcrToken struct {
+	Registry   string `json:"registry"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Expiration string `json:"expiration"`
+}
+
+// acrPersonalTokenResponse GetAuthorizationToken of Personal Edition
+// reference: https://help.aliyun.com/zh/acr/developer-reference/api-cr-2016-06-07-getauthorizationtoken
+type acrPersonalTokenResponse struct {
+	Data struct {
+		TempUserName       string `json:"tempUserName"`
+		AuthorizationToken string `json:"authorizationToken"`
+		ExpireDate         int64  `json:"expireDate"` // Unix Epoch(milliseconds)
+	} `json:"data"`
+}
+
+// acrEnterpriseTokenResponse GetAuthorizationToken of Enterprise Edition
+// reference: https://help.aliyun.com/zh/acr/developer-reference/api-cr-2018-12-01-getauthorizationtoken
+type acrEnterpriseTokenResponse struct {
+	IsSuccess          bool   `json:"IsSuccess"`
+	Code               string `json:"Code"`
+	TempUsername       string `json:"TempUsername"`
+	AuthorizationToken string `json:"AuthorizationToken"`
+	ExpireTime         int64  `json:"ExpireTime"` // Unix Epoch(milliseconds)
+}
+
+func (t *AcrToken) Marshal() (string, error) {
+	if t == nil {
+		return "null", nil
+	}
+	tokenBytes, err := json.Marshal(t)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal acr token failed")
+	}
+	return string(tokenBytes), nil
+}
+
+func UnmarshalAcrToken(token string) (*AcrToken, error) {
+	var acrToken AcrToken
+	err := json.Unmarshal([]byte(token), &acrToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal acr token failed")
+	}
+	return &acrToken, nil
+}
+
+func (t *AcrToken) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
+	idaaslog.Debug.PrintfLn("Check is valid, expiration: %s, threshold: %d ms",
+		t.Expiration, thresholdDuration.Milliseconds())
+	expiration, err := time.Parse(time.RFC3339Nano, t.Expiration)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error parsing expiration: %s", t.Expiration)
+		return false
+	}
+	valid := utils.UntilFor(serverHost, expiration) > thresholdDuration
+	idaaslog.Info.PrintfLn("Check is valid: %s", valid)
+	return valid
+}
+
+// FetchAcrToken fetch ACR login token of registry with profile's STS token, cached like cloud tokens
+func FetchAcrToken(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig, registry string,
+	options *FetchAcrTokenOptions) (*AcrToken, error) {
+	acrConfig := alibabaCloudStsConfig.Acr
+	if acrConfig == nil {
+		return nil, errors.Errorf("ACR is not configured in profile: %s", profile)
+	}
+	digest := utils.Sha256ToHex(alibabaCloudStsConfig.Digest() + acrConfig.Digest() + registry)
+	endpoint, err := getAcrEndpoint(alibabaCloudStsConfig, registry)
+	if err != nil {
+		return nil, err
+	}
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile":  profile,
+			"digest":   digest,
+			"registry": registry,
+			"config":   acrConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchAcrTokenContent(ctx, profile, alibabaCloudStsConfig, endpoint, registry, options)
+		},
+		ForceNew: options.ForceNew,
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isAcrTokenExpiringOrExpired(endpoint, s, 20*time.Minute)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isAcrTokenExpiringOrExpired(endpoint, s, 3*time.Minute)
+		},
+	}
+	cacheKey := GetAcrTokenCacheKey(profile, digest)
+	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryCloudToken, cacheKey)
+	acrTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch acr token: %v", err)
+		return nil, err
+	}
+	return UnmarshalAcrToken(acrTokenStr)
+}
+
+// RemoveAcrToken remove cached ACR login token of registry
+func RemoveAcrToken(profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig, registry string) error {
+	digest := utils.Sha256ToHex(alibabaCloudStsConfig.Digest() + alibabaCloudStsConfig.Acr.Digest() + registry)
+	return utils.RemoveCacheFile(constants.CategoryCloudToken, GetAcrTokenCacheKey(profile, digest))
+}
+
+func GetAcrTokenCacheKey(profile, digest string) string {
+	return fmt.Sprintf("%s_acr_%s", profile, digest[0:32])
+}
+
+func fetchAcrTokenContent(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	endpoint, registry string, options *FetchAcrTokenOptions) (int, string, error) {
+	stsToken, err := FetchStsWithOidcConfig(ctx, profile, alibabaCloudStsConfig, &FetchStsWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
+	})
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetching sts token: %v", err)
+		return 600, "", err
+	}
+	acrToken, err := getAcrAuthorizationToken(stsToken, alibabaCloudStsConfig.Acr, endpoint,
+		getAcrRegion(alibabaCloudStsConfig, registry), registry)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error get acr authorization token: %v", err)
+		return 600, "", err
+	}
+	acrTokenJson, err := acrToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling acr token: %v", err)
+		return 600, "", err
+	}
+	return 200, acrTokenJson, nil
+}
+
+// getAcrRegion returns configured ACR region, or region parsed from registry, or region of STS
+func getAcrRegion(alibabaCloudStsConfig *config.AlibabaCloudStsConfig, registry string) string {
+	region := alibabaCloudStsConfig.Acr.Region
+	if region == "" {
+		region = ParseAcrRegistryRegion(registry)
+	}
+	if region == "" {
+		region = alibabaCloudStsConfig.Region
+	}
+	return region
+}
+
+// getAcrEndpoint returns configured ACR endpoint, or endpoint of region which is parsed from registry when absent
+func getAcrEndpoint(alibabaCloudStsConfig *config.AlibabaCloudStsConfig, registry string) (string, error) {
+	if alibabaCloudStsConfig.Acr.Endpoint != "" {
+		return alibabaCloudStsConfig.Acr.Endpoint, nil
+	}
+	region := getAcrRegion(alibabaCloudStsConfig, registry)
+	if region == "" {
+		return "", errors.Errorf("ACR Endpoint or Region at least one is required, registry: %s", registry)
+	}
+	return fmt.Sprintf("cr.%s.aliyuncs.com", region), nil
+}
+
+func getAcrAuthorizationToken(stsToken *StsToken, acrConfig *config.AlibabaCloudAcrConfig,
+	endpoint, region, registry string) (*AcrToken, error) {
+	client, err := createAcrClient(endpoint, region, stsToken)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &openapi.Params{
+		Action:      tea.String("GetAuthorizationToken"),
+		Protocol:    tea.String("HTTPS"),
+		AuthType:    tea.String("AK"),
+		BodyType:    tea.String("json"),
+		ReqBodyType: tea.String("json"),
+	}
+	request := &openapi.OpenApiRequest{}
+	if acrConfig.InstanceId != "" {
+		params.Version = tea.String("2018-12-01")
+		params.Style = tea.String("RPC")
+		params.Method = tea.String("POST")
+		params.Pathname = tea.String("/")
+		params.ReqBodyType = tea.String("formData")
+		request.Query = map[string]*string{
+			"InstanceId": tea.String(acrConfig.InstanceId),
+		}
+	} else {
+		params.Version = tea.String("2016-06-07")
+		params.Style = tea.String("ROA")
+		params.Method = tea.String("GET")
+		params.Pathname = tea.String("/tokens")
+	}
+	runtime := &util.RuntimeOptions{}
+	runtime.SetAutoretry(true)
+	idaaslog.Debug.PrintfLn("Get ACR authorization token, endpoint: %s, instance: %s, registry: %s",
+		endpoint, acrConfig.InstanceId, registry)
+	requestTime := time.Now()
+	response, err := client.CallApi(params, request, runtime)
+	if err != nil {
+		return nil, errors.Wrap(err, "get ACR authorization token failed")
+	}
+	if headers, ok := response["headers"].(map[string]*string); ok {
+		utils.ObserveServerDateHeader(tea.StringValue(headers["date"]), requestTime, time.Now(), endpoint)
+	}
+	bodyBytes, err := json.Marshal(response["body"])
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal ACR response failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Get ACR authorization token response: %s", string(bodyBytes))
+
+	var username, password string
+	var expireTime int64
+	if acrConfig.InstanceId != "" {
+		var enterpriseTokenResponse acrEnterpriseTokenResponse
+		if err = json.Unmarshal(bodyBytes, &enterpriseTokenResponse); err != nil {
+			return nil, errors.Wrap(err, "parse ACR response failed")
+		}
+		if !enterpriseTokenResponse.IsSuccess {
+			return nil, errors.Errorf("get ACR authorization token failed, code: %s", enterpriseTokenResponse.Code)
+		}
+		username = enterpriseTokenResponse.TempUsername
+		password = enterpriseTokenResponse.AuthorizationToken
+		expireTime = enterpriseTokenResponse.ExpireTime
+	} else {
+		var personalTokenResponse acrPersonalTokenResponse
+		if err = json.Unmarshal(bodyBytes, &personalTokenResponse); err != nil {
+			return nil, errors.Wrap(err, "parse ACR response failed")
+		}
+		username = personalTokenResponse.Data.TempUserName
+		password = personalTokenResponse.Data.AuthorizationToken
+		expireTime = personalTokenResponse.Data.ExpireDate
+	}
+	if password == "" {
+		return nil, errors.New("get ACR authorization token failed, empty token")
+	}
+	expiration := time.UnixMilli(expireTime)
+	if expireTime <= 0 {
+		// 1970 would be always expired and fetched on every call
+		idaaslog.Warn.PrintfLn("ACR authorization token has no expire time, assume expires in: %s",
+			acrTokenDefaultLifetime)
+		expiration = utils.NowFor(endpoint).Add(acrTokenDefaultLifetime)
+	}
+	return &AcrToken{
+		Registry:   registry,
+		Username:   username,
+		Password:   password,
+		Expiration: expiration.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// ParseAcrRegistryRegion parse region from registry hostname, e.g. registry.cn-hangzhou.aliyuncs.com,
+// registry-vpc.cn-hangzhou.aliyuncs.com, example-registry.cn-hangzhou.cr.aliyuncs.com
+func ParseAcrRegistryRegion(registry string) string {
+	hostname, _, _ := strings.Cut(registry, ":")
+	parts := strings.Split(hostname, ".")
+	if strings.HasSuffix(hostname, ".cr.aliyuncs.com") && len(parts) == 5 {
+		return parts[1]
+	}
+	if strings.HasSuffix(hostname, ".aliyuncs.com") && len(parts) == 4 && strings.HasPrefix(parts[0], "registry") {
+		return parts[1]
+	}
+	return ""
+}
+
+func isAcrTokenExpiringOrExpired(serverHost string, s *utils.StringWithTime, thresholdDuration time.Duration) bool {
+	acrToken, err := UnmarshalAcrToken(s.Content)
+	if err != nil {
+		return true
+	}
+	return !acrToken.IsValidAtLeastThreshold(serverHost, thresholdDuration)
+}
+
+func createAcrClient(endpoint, region string, stsToken *StsToken) (*openapi.Client, error) {
+	openapiConfig := &openapi.Config{
+		AccessKeyId:     tea.String(stsToken.AccessKeyId),
+		AccessKeySecret: tea.String(stsToken.AccessKeySecret),
+	}
+	if stsToken.StsToken != "" {
+		openapiConfig.SecurityToken = tea.String(stsToken.StsToken)
+	}
+	// endpoint with scheme, e.g. http://127.0.0.1:8080 for offline testing
+	if scheme, host, found := strings.Cut(endpoint, "://"); found {
+		openapiConfig.Protocol = tea.String(scheme)
+		endpoint = host
+	}
+	openapiConfig.Endpoint = tea.String(endpoint)
+	if region != "" {
+		openapiConfig.RegionId = tea.String(region)
+	}
+	client, err := openapi.NewClient(openapiConfig)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error create acr client: %v", err)
+	}
+	return client, err
+}
@@ -0,0 +1,82 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSigninEndpoint = "https://signin.aliyun.com/federation"
+	DefaultConsoleUrl     = "https://home.console.aliyun.com"
+	IntlConsoleUrl        = "https://home.console.alibabacloud.com"
+)
+
+type BuildConsoleSigninUrlOptions struct {
+	SigninEndpoint string // optional, default DefaultSigninEndpoint, international site: https://signin.alibabacloud.com/federation
+	Destination    string // optional, full URL or path of console URL
+	LoginUrl       string // optional, redirect when sign in token expired
+}
+
+type getSigninTokenResponse struct {
+	RequestId   string `json:"RequestId"`
+	SigninToken string `json:"SigninToken"`
+	Message     string `json:"Message"`
+}
+
+// BuildConsoleSigninUrl builds federated console sign in URL with STS token
+// reference: https://help.aliyun.com/zh/ram/user-guide/access-alibaba-cloud-console-via-sts-token
+func (t *StsToken) BuildConsoleSigninUrl(ctx context.Context, options *BuildConsoleSigninUrlOptions) (string, error) {
+	signinEndpoint := options.SigninEndpoint
+	if signinEndpoint == "" {
+		signinEndpoint = DefaultSigninEndpoint
+	}
+	parameters := map[string]string{
+		"Action":          "GetSigninToken",
+		"AccessKeyId":     t.AccessKeyId,
+		"AccessKeySecret": t.AccessKeySecret,
+		"SecurityToken":   t.StsToken,
+	}
+	idaaslog.Debug.PrintfLn("Get signin token, endpoint: %s", signinEndpoint)
+	statusCode, response, err := utils.PostHttp(ctx, signinEndpoint, parameters)
+	if err != nil {
+		return "", errors.Wrap(err, "get signin token failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Get signin token, status: %d, response: %s", statusCode, response)
+	var signinTokenResponse getSigninTokenResponse
+	err = json.Unmarshal([]byte(response), &signinTokenResponse)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse get signin token response failed, status: %d", statusCode)
+	}
+	if statusCode != 200 || signinTokenResponse.SigninToken == "" {
+		return "", errors.Errorf("get signin token failed, status: %d, message: %s", statusCode, signinTokenResponse.Message)
+	}
+
+	query := url.Values{}
+	query.Set("Action", "Login")
+	consoleUrl := DefaultConsoleUrl
+	if strings.Contains(signinEndpoint, "alibabacloud.com") {
+		consoleUrl = IntlConsoleUrl
+	}
+	query.Set("Destination", buildDestination(consoleUrl, options.Destination))
+	query.Set("SigninToken", signinTokenResponse.SigninToken)
+	if options.LoginUrl != "" {
+		query.Set("LoginUrl", options.LoginUrl)
+	}
+	return signinEndpoint + "?" + query.Encode(), nil
+}
+
+func buildDestination(consoleUrl, destination string) string {
+	if destination == "" {
+		return consoleUrl
+	}
+	if strings.HasPrefix(destination, "https://") || strings.HasPrefix(destination, "http://") {
+		return destination
+	}
+	return consoleUrl + "/" + strings.TrimPrefix(destination, "/")
+}
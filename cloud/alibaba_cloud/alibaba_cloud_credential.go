@@ -0,0 +1,148 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+)
+
+var _ cloud_common.Credential = (*StsToken)(nil)
+
+// CredentialEnvironments credential environments read by Alibaba Cloud SDKs and tools, including environments
+// not set by execute, which may override or mix with execute's credentials when inherited
+var CredentialEnvironments = []string{
+	"ALIBABA_CLOUD_ACCESS_KEY_ID", "ALIBABACLOUD_ACCESS_KEY_ID", "ALICLOUD_ACCESS_KEY_ID", "ALICLOUD_ACCESS_KEY",
+	"ACCESS_KEY_ID", "OSS_ACCESS_KEY_ID",
+	"ALICLOUD_SECRET_KEY", "ALIBABA_CLOUD_ACCESS_KEY_SECRET", "ALIBABACLOUD_ACCESS_KEY_SECRET",
+	"ALICLOUD_ACCESS_KEY_SECRET", "ACCESS_KEY_SECRET", "OSS_ACCESS_KEY_SECRET",
+	"ALIBABA_CLOUD_SECURITY_TOKEN", "ALIBABACLOUD_SECURITY_TOKEN", "ALICLOUD_SECURITY_TOKEN", "SECURITY_TOKEN",
+	"OSS_SESSION_TOKEN",
+	"ALIBABA_CLOUD_CREDENTIALS_URI", "ALIBABA_CLOUD_CREDENTIALS_FILE", "ALIBABA_CLOUD_CONFIG_FILE",
+	"ALIBABA_CLOUD_PROFILE", "ALICLOUD_PROFILE",
+	"ALIBABA_CLOUD_ROLE_ARN", "ALIBABA_CLOUD_OIDC_PROVIDER_ARN", "ALIBABA_CLOUD_OIDC_TOKEN_FILE",
+	"ALIBABA_CLOUD_ROLE_SESSION_NAME", "ALIBABA_CLOUD_ECS_METADATA",
+}
+
+func (t *StsToken) CloudName() string {
+	return "Alibaba Cloud"
+}
+
+func (t *StsToken) GetExpiration() *time.Time {
+	expiration, err := time.Parse(time.RFC3339Nano, t.Expiration)
+	if err != nil {
+		return nil
+	}
+	return &expiration
+}
+
+// Environ
+// Alibaba Cloud Terraform plugin credential order:
+// 静态配置 > 环境变量 > Profile 静态配置 > ECS 服务角色 > Profile ECS 服务角色 > OIDC 角色扮演 > 角色扮演
+// reference: https://help.aliyun.com/zh/terraform/terraform-authentication
+// reference: https://help.aliyun.com/zh/sdk/developer-reference/v2-manage-access-credentials
+func (t *StsToken) Environ(ctx context.Context, options *cloud_common.EnvironOptions) ([]string, error) {
+	var env []string
+	if options.CredentialEndpoint != nil {
+		credentialsUri := options.CredentialEndpoint.Url + cloud_common.CredentialEndpointPathAlibabaCloud +
+			options.CredentialEndpoint.Token
+		env = append(env, "ALIBABA_CLOUD_CREDENTIALS_URI="+credentialsUri)
+		return AppendRegionEnviron(env, options.Region), nil
+	}
+	idaaslog.Debug.PrintfLn("Found access key ID: %s", t.AccessKeyId)
+	env = append(env, "ALIBABA_CLOUD_ACCESS_KEY_ID="+t.AccessKeyId)
+	env = append(env, "ALIBABACLOUD_ACCESS_KEY_ID="+t.AccessKeyId)
+	env = append(env, "ALICLOUD_ACCESS_KEY_ID="+t.AccessKeyId)
+	env = append(env, "ALICLOUD_ACCESS_KEY="+t.AccessKeyId)
+	env = append(env, "ACCESS_KEY_ID="+t.AccessKeyId)
+	env = append(env, "OSS_ACCESS_KEY_ID="+t.AccessKeyId)
+
+	env = append(env, "ALICLOUD_SECRET_KEY="+t.AccessKeySecret)
+	env = append(env, "ALIBABA_CLOUD_ACCESS_KEY_SECRET="+t.AccessKeySecret)
+	env = append(env, "ALIBABACLOUD_ACCESS_KEY_SECRET="+t.AccessKeySecret)
+	env = append(env, "ALICLOUD_ACCESS_KEY_SECRET="+t.AccessKeySecret)
+	env = append(env, "ACCESS_KEY_SECRET="+t.AccessKeySecret)
+	env = append(env, "OSS_ACCESS_KEY_SECRET="+t.AccessKeySecret)
+
+	env = append(env, "ALIBABA_CLOUD_SECURITY_TOKEN="+t.StsToken)
+	env = append(env, "ALIBABACLOUD_SECURITY_TOKEN="+t.StsToken)
+	env = append(env, "ALICLOUD_SECURITY_TOKEN="+t.StsToken)
+	env = append(env, "SECURITY_TOKEN="+t.StsToken)
+	env = append(env, "OSS_SESSION_TOKEN="+t.StsToken)
+
+	if options.CredentialFiles {
+		credentialFileEnv, err := t.writeCredentialFiles(options)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, credentialFileEnv...)
+	}
+	return AppendRegionEnviron(env, options.Region), nil
+}
+
+// writeCredentialFiles writes credential files for tools which read files only,
+// aliyun CLI and ossutil do not read file path from environments, use `aliyun --config-path` and `ossutil --config-file`
+func (t *StsToken) writeCredentialFiles(options *cloud_common.EnvironOptions) ([]string, error) {
+	credentialDir, err := options.CredentialDir("alibaba-cloud")
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	credentialsFile, err := WriteCredentialsFile(credentialDir, t)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found credentials file: %s", credentialsFile)
+	env = append(env, "ALIBABA_CLOUD_CREDENTIALS_FILE="+credentialsFile)
+
+	aliyunCliConfigFile, err := WriteAliyunCliConfigFile(credentialDir, t, options.Region)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found aliyun CLI config file: %s", aliyunCliConfigFile)
+	env = append(env, "ALIBABA_CLOUD_CONFIG_FILE="+aliyunCliConfigFile)
+
+	ossutilConfigFile, err := WriteOssutilConfigFile(credentialDir, t, options.Region)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found ossutil config file: %s", ossutilConfigFile)
+	return env, nil
+}
+
+// AppendRegionEnviron region environments read by Alibaba Cloud SDKs and tools
+func AppendRegionEnviron(env []string, region string) []string {
+	if region != "" {
+		idaaslog.Debug.PrintfLn("Set region: %s", region)
+		env = append(env, "ALICLOUD_REGION="+region)
+		env = append(env, "ALIYUN_DEFAULT_REGION="+region)
+		env = append(env, "DEFAULT_REGION="+region)
+		env = append(env, "ALIBABA_CLOUD_DEFAULT_REGION="+region)
+		env = append(env, "REGION="+region)
+		env = append(env, "OSS_REGION="+region)
+	}
+	return env
+}
+
+func (t *StsToken) Output(options *cloud_common.FormatOptions) (*cloud_common.CredentialOutput, error) {
+	content, err := t.MarshalWithFormat(options.Format)
+	if err != nil {
+		return nil, err
+	}
+	return &cloud_common.CredentialOutput{Content: content}, nil
+}
+
+func (t *StsToken) DisplayRows(options *cloud_common.FormatOptions) []*cloud_common.DisplayRow {
+	rows := []*cloud_common.DisplayRow{
+		cloud_common.NewDisplayRow("Access Key ID", t.AccessKeyId),
+		cloud_common.NewDisplayRow("Access Key Secret", t.AccessKeySecret),
+		cloud_common.NewDisplayRow("Security Token", t.StsToken),
+	}
+	if expiration := t.GetExpiration(); expiration != nil {
+		rows = append(rows, cloud_common.NewExpirationDisplayRow(*expiration))
+	} else {
+		rows = append(rows, cloud_common.NewDisplayRow("Expiration", t.Expiration))
+	}
+	return rows
+}
@@ -0,0 +1,96 @@
+package alibaba_cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	CredentialsFileName     = "credentials"
+	AliyunCliConfigFileName = "config.json"
+	OssutilConfigFileName   = "ossutilconfig"
+)
+
+// WriteCredentialsFile writes Alibaba Cloud credentials file with default sts profile,
+// used via ALIBABA_CLOUD_CREDENTIALS_FILE by Alibaba Cloud SDKs
+// reference: https://github.com/aliyun/credentials-go
+func WriteCredentialsFile(dir string, stsToken *StsToken) (string, error) {
+	var lines []string
+	lines = append(lines, "[default]")
+	lines = append(lines, "type = sts")
+	lines = append(lines, "access_key_id = "+stsToken.AccessKeyId)
+	lines = append(lines, "access_key_secret = "+stsToken.AccessKeySecret)
+	lines = append(lines, "security_token = "+stsToken.StsToken)
+	return writeCredentialFile(dir, CredentialsFileName, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// aliyunCliConfig aliyun CLI config.json with StsToken mode profile
+// reference: https://help.aliyun.com/zh/cli/configure-credentials
+type aliyunCliConfig struct {
+	Current  string              `json:"current"`
+	Profiles []*aliyunCliProfile `json:"profiles"`
+	MetaPath string              `json:"meta_path"`
+}
+
+type aliyunCliProfile struct {
+	Name            string `json:"name"`
+	Mode            string `json:"mode"`
+	AccessKeyId     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	StsToken        string `json:"sts_token"`
+	RegionId        string `json:"region_id,omitempty"` // omitted when region is not set
+	OutputFormat    string `json:"output_format"`
+	Language        string `json:"language"`
+}
+
+// WriteAliyunCliConfigFile writes aliyun CLI config file, used via `aliyun --config-path`
+func WriteAliyunCliConfigFile(dir string, stsToken *StsToken, region string) (string, error) {
+	config := &aliyunCliConfig{
+		Current: "default",
+		Profiles: []*aliyunCliProfile{{
+			Name:            "default",
+			Mode:            "StsToken",
+			AccessKeyId:     stsToken.AccessKeyId,
+			AccessKeySecret: stsToken.AccessKeySecret,
+			StsToken:        stsToken.StsToken,
+			RegionId:        region,
+			OutputFormat:    "json",
+			Language:        "en",
+		}},
+	}
+	configBytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "marshal aliyun CLI config failed")
+	}
+	return writeCredentialFile(dir, AliyunCliConfigFileName, append(configBytes, '\n'))
+}
+
+// WriteOssutilConfigFile writes ossutil config file next to credentials file, used via `ossutil --config-file`,
+// ossutil does not read config file path from environments
+// reference: https://help.aliyun.com/zh/oss/developer-reference/configure-ossutil
+func WriteOssutilConfigFile(dir string, stsToken *StsToken, region string) (string, error) {
+	var lines []string
+	lines = append(lines, "[Credentials]")
+	lines = append(lines, "language=EN")
+	if region != "" {
+		lines = append(lines, fmt.Sprintf("endpoint=oss-%s.aliyuncs.com", region))
+	}
+	lines = append(lines, "accessKeyID="+stsToken.AccessKeyId)
+	lines = append(lines, "accessKeySecret="+stsToken.AccessKeySecret)
+	lines = append(lines, "stsToken="+stsToken.StsToken)
+	return writeCredentialFile(dir, OssutilConfigFileName, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+func writeCredentialFile(dir, filename string, content []byte) (string, error) {
+	credentialFile := filepath.Join(dir, filename)
+	err := os.WriteFile(credentialFile, content, 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write credential file: %s failed", credentialFile)
+	}
+	return credentialFile, nil
+}
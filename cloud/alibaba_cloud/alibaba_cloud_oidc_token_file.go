@@ -0,0 +1,166 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+)
+
+const (
+	OidcTokenFileName = "oidc_token"
+
+	// oidcTokenRefreshBefore OIDC token cache fetches new token when expiring in 2 minutes
+	oidcTokenRefreshBefore    = 2 * time.Minute
+	oidcTokenMinRefreshWait   = 10 * time.Second
+	oidcTokenRetryWaitOnError = 30 * time.Second
+)
+
+// OidcTokenFileWriter keeps OIDC token file of profile up to date for SDK-native OIDC credential providers,
+// SDKs and Terraform assume role with OIDC token file by themselves
+// reference: https://help.aliyun.com/zh/sdk/developer-reference/v2-manage-access-credentials
+type OidcTokenFileWriter struct {
+	profile               string
+	alibabaCloudStsConfig *config.AlibabaCloudStsConfig
+	tokenFile             string
+	forceNew              bool
+	writtenToken          string
+}
+
+func NewOidcTokenFileWriter(profile string, cloudStsConfig *config.CloudStsConfig, tokenFile string,
+	forceNew bool) (*OidcTokenFileWriter, error) {
+	alibabaCloudStsConfig := cloudStsConfig.AlibabaCloud
+	if alibabaCloudStsConfig == nil {
+		return nil, fmt.Errorf("profile: %s is not Alibaba Cloud", profile)
+	}
+	if alibabaCloudStsConfig.CredentialSource != nil {
+		return nil, fmt.Errorf("profile: %s with credential_source has no OIDC token", profile)
+	}
+	if alibabaCloudStsConfig.OidcTokenProvider == nil {
+		return nil, fmt.Errorf("profile: %s oidc_token_provider is required", profile)
+	}
+	if alibabaCloudStsConfig.OidcProviderArn == "" || alibabaCloudStsConfig.RoleArn == "" {
+		return nil, fmt.Errorf("profile: %s oidc_provider_arn and role_arn are required", profile)
+	}
+	if len(alibabaCloudStsConfig.AssumeRoleChain) > 0 {
+		return nil, fmt.Errorf("profile: %s assume_role_chain is not supported, SDKs only assume role with OIDC",
+			profile)
+	}
+	return &OidcTokenFileWriter{
+		profile:               profile,
+		alibabaCloudStsConfig: alibabaCloudStsConfig,
+		tokenFile:             tokenFile,
+		forceNew:              forceNew,
+	}, nil
+}
+
+// Environ environments of SDK-native OIDC credential provider, SDKs build STS endpoint from region and
+// VPC flag, so only STS endpoints of public or VPC network are exported
+func (w *OidcTokenFileWriter) Environ() []string {
+	var env []string
+	env = append(env, "ALIBABA_CLOUD_ROLE_ARN="+w.alibabaCloudStsConfig.RoleArn)
+	env = append(env, "ALIBABA_CLOUD_OIDC_PROVIDER_ARN="+w.alibabaCloudStsConfig.OidcProviderArn)
+	env = append(env, "ALIBABA_CLOUD_OIDC_TOKEN_FILE="+w.tokenFile)
+	if w.alibabaCloudStsConfig.RoleSessionName != "" {
+		env = append(env, "ALIBABA_CLOUD_ROLE_SESSION_NAME="+w.alibabaCloudStsConfig.RoleSessionName)
+	}
+	region, network := w.alibabaCloudStsConfig.Region, w.alibabaCloudStsConfig.Network
+	if w.alibabaCloudStsConfig.StsEndpoint != "" {
+		region, network = parseStsEndpoint(w.alibabaCloudStsConfig.StsEndpoint)
+	}
+	if region == "" || network == NetworkDualStack {
+		idaaslog.Warn.PrintfLn("STS endpoint: %s of profile: %s is not supported by SDKs, SDKs use default endpoint",
+			w.alibabaCloudStsConfig.StsEndpoint, w.profile)
+		return env
+	}
+	env = append(env, "ALIBABA_CLOUD_STS_REGION="+region)
+	if network == NetworkVpc {
+		env = append(env, "ALIBABA_CLOUD_VPC_ENDPOINT_ENABLED=true")
+	}
+	return env
+}
+
+// parseStsEndpoint parse region and network from STS endpoint, e.g. sts.cn-hangzhou.aliyuncs.com,
+// sts-vpc.cn-hangzhou.aliyuncs.com, returns empty region when endpoint is not an official one
+func parseStsEndpoint(stsEndpoint string) (string, string) {
+	parts := strings.Split(stsEndpoint, ".")
+	if len(parts) != 4 || parts[2] != "aliyuncs" || parts[3] != "com" {
+		return "", ""
+	}
+	switch parts[0] {
+	case "sts":
+		return parts[1], NetworkPublic
+	case "sts-" + NetworkVpc:
+		return parts[1], NetworkVpc
+	case "sts-" + NetworkDualStack:
+		return parts[1], NetworkDualStack
+	}
+	return "", ""
+}
+
+// Write fetch OIDC token and write token file with mode 0600 when token changed, returns token expiration
+func (w *OidcTokenFileWriter) Write(ctx context.Context) (time.Time, error) {
+	fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
+		ForceNew: w.forceNew,
+	}
+	oidcToken, err := idp.FetchOidcToken(ctx, w.profile, w.alibabaCloudStsConfig.OidcTokenProvider,
+		fetchOidcTokenOptions)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// force new only for the first token
+	w.forceNew = false
+	claims, err := idp.ParseJwtTokenClaim(oidcToken)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.ExpirationAt <= 0 {
+		// STS requires exp claim, token without exp would be refreshed in a busy loop
+		return time.Time{}, fmt.Errorf("OIDC token of profile: %s has no exp claim", w.profile)
+	}
+	expiration := time.Unix(claims.ExpirationAt, 0)
+	if oidcToken == w.writtenToken {
+		return expiration, nil
+	}
+	err = utils.WriteFileAtomic(w.tokenFile, []byte(oidcToken), 0600)
+	if err != nil {
+		return time.Time{}, err
+	}
+	w.writtenToken = oidcToken
+	idaaslog.Info.PrintfLn("OIDC token file: %s written, expiration: %s", w.tokenFile, expiration)
+	return expiration, nil
+}
+
+// Run refresh token file before token expiring until context is done, errors are logged and retried,
+// the first token should be written by Write before Run
+func (w *OidcTokenFileWriter) Run(ctx context.Context, expiration time.Time) {
+	// expiration is issued by IdP, compare with local time compensated with its clock skew
+	serverHost := idp.GetTokenServerHost(w.alibabaCloudStsConfig.OidcTokenProvider)
+	for {
+		wait := utils.UntilFor(serverHost, expiration) - oidcTokenRefreshBefore
+		if wait < oidcTokenMinRefreshWait {
+			wait = oidcTokenMinRefreshWait
+		}
+		idaaslog.Debug.PrintfLn("Refresh OIDC token file: %s in: %s", w.tokenFile, wait)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		newExpiration, err := w.Write(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			idaaslog.Error.PrintfLn("Refresh OIDC token file: %s failed: %v", w.tokenFile, err)
+			expiration = utils.NowFor(serverHost).Add(oidcTokenRetryWaitOnError + oidcTokenRefreshBefore)
+			continue
+		}
+		expiration = newExpiration
+	}
+}
@@ -0,0 +1,219 @@
+package alibaba_cloud
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	OssSignatureVersionV1 = "v1"
+	OssSignatureVersionV4 = "v4"
+
+	// OssMaxPresignExpiresV4 max validity of V4 presigned URL
+	OssMaxPresignExpiresV4 = 7 * 24 * time.Hour
+
+	ossV4Algorithm = "OSS4-HMAC-SHA256"
+	ossV4Request   = "aliyun_v4_request"
+)
+
+type PresignOssUrlOptions struct {
+	Bucket           string
+	Key              string
+	Method           string        // GET or PUT
+	Expires          time.Duration // capped at STS token expiration
+	SignatureVersion string        // v4(default) or v1
+	Region           string        // required by v4, e.g. cn-hangzhou
+	Endpoint         string        // optional, default oss-<region>.aliyuncs.com, supports scheme
+	ContentType      string        // optional, signed, PUT request must send the same Content-Type
+}
+
+// ParseOssUrl parse oss://bucket/key
+func ParseOssUrl(ossUrl string) (string, string, error) {
+	path, found := strings.CutPrefix(ossUrl, "oss://")
+	if !found {
+		return "", "", errors.Errorf("invalid OSS URL: %s, requires oss://bucket/key", ossUrl)
+	}
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket == "" || key == "" {
+		return "", "", errors.Errorf("invalid OSS URL: %s, bucket and key are required", ossUrl)
+	}
+	return bucket, key, nil
+}
+
+// PresignOssUrl compute OSS presigned URL locally, no network calls, returns URL and its expiration
+// reference: https://help.aliyun.com/zh/oss/developer-reference/add-signatures-to-urls
+func (t *StsToken) PresignOssUrl(options *PresignOssUrlOptions) (string, time.Time, error) {
+	if options.Bucket == "" || options.Key == "" {
+		return "", time.Time{}, errors.New("bucket and key are required")
+	}
+	method := strings.ToUpper(options.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if method != "GET" && method != "PUT" {
+		return "", time.Time{}, errors.Errorf("invalid method: %s, supports GET or PUT", options.Method)
+	}
+	if options.Expires <= 0 {
+		return "", time.Time{}, errors.New("expires must be positive")
+	}
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		if options.Region == "" {
+			return "", time.Time{}, errors.New("OSS Endpoint or Region at least one is required")
+		}
+		endpoint = fmt.Sprintf("oss-%s.aliyuncs.com", options.Region)
+	}
+	// OSS rejects request signed with time skewed more than 15 minutes
+	now := utils.NowFor(endpoint).UTC().Truncate(time.Second)
+	expiration := now.Add(options.Expires)
+	if t.Expiration != "" {
+		stsExpiration, err := time.Parse(time.RFC3339Nano, t.Expiration)
+		if err != nil {
+			return "", time.Time{}, errors.Wrapf(err, "parse STS token expiration: %s failed", t.Expiration)
+		}
+		if stsExpiration.Before(expiration) {
+			idaaslog.Info.PrintfLn("Presign expiration capped at STS token expiration: %s", t.Expiration)
+			expiration = stsExpiration.UTC().Truncate(time.Second)
+		}
+	}
+	if !expiration.After(now) {
+		return "", time.Time{}, errors.New("STS token is expired")
+	}
+
+	scheme := "https"
+	if s, host, found := strings.Cut(endpoint, "://"); found {
+		scheme = s
+		endpoint = host
+	}
+	baseUrl := fmt.Sprintf("%s://%s.%s/%s", scheme, options.Bucket, endpoint, ossEscape(options.Key, false))
+
+	var query string
+	var err error
+	switch options.SignatureVersion {
+	case "", OssSignatureVersionV4:
+		query, err = t.presignOssQueryV4(method, now, expiration, options)
+	case OssSignatureVersionV1:
+		query = t.presignOssQueryV1(method, expiration, options)
+	default:
+		err = errors.Errorf("invalid signature version: %s, supports v4 or v1", options.SignatureVersion)
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return baseUrl + "?" + query, expiration, nil
+}
+
+func (t *StsToken) presignOssQueryV1(method string, expiration time.Time, options *PresignOssUrlOptions) string {
+	expires := fmt.Sprintf("%d", expiration.Unix())
+	canonicalizedResource := "/" + options.Bucket + "/" + options.Key
+	if t.StsToken != "" {
+		canonicalizedResource += "?security-token=" + t.StsToken
+	}
+	// VERB, Content-MD5, Content-Type, Expires, CanonicalizedOSSHeaders + CanonicalizedResource
+	stringToSign := strings.Join([]string{method, "", options.ContentType, expires, canonicalizedResource}, "\n")
+	idaaslog.Debug.PrintfLn("OSS V1 string to sign: %s", stringToSign)
+	mac := hmac.New(sha1.New, []byte(t.AccessKeySecret))
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	query := map[string]string{
+		"OSSAccessKeyId": t.AccessKeyId,
+		"Expires":        expires,
+		"Signature":      signature,
+	}
+	if t.StsToken != "" {
+		query["security-token"] = t.StsToken
+	}
+	return canonicalOssQuery(query)
+}
+
+func (t *StsToken) presignOssQueryV4(method string, now, expiration time.Time, options *PresignOssUrlOptions) (
+	string, error) {
+	if options.Region == "" {
+		return "", errors.New("Region is required by OSS V4 signature")
+	}
+	expires := expiration.Sub(now)
+	if expires > OssMaxPresignExpiresV4 {
+		return "", errors.Errorf("expires: %s exceeds OSS V4 max: %s", expires, OssMaxPresignExpiresV4)
+	}
+	date := now.Format("20060102")
+	datetime := now.Format("20060102T150405Z")
+	scope := fmt.Sprintf("%s/%s/oss/%s", date, options.Region, ossV4Request)
+	query := map[string]string{
+		"x-oss-signature-version": ossV4Algorithm,
+		"x-oss-credential":        t.AccessKeyId + "/" + scope,
+		"x-oss-date":              datetime,
+		"x-oss-expires":           fmt.Sprintf("%d", int64(expires.Seconds())),
+	}
+	if t.StsToken != "" {
+		query["x-oss-security-token"] = t.StsToken
+	}
+	canonicalQuery := canonicalOssQuery(query)
+	canonicalHeaders := ""
+	if options.ContentType != "" {
+		canonicalHeaders = "content-type:" + strings.TrimSpace(options.ContentType) + "\n"
+	}
+	// HTTP Verb, Canonical URI, Canonical Query String, Canonical Headers, Additional Headers, Hashed PayLoad
+	canonicalRequest := strings.Join([]string{
+		method,
+		ossEscape("/"+options.Bucket+"/"+options.Key, false),
+		canonicalQuery,
+		canonicalHeaders,
+		"",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	idaaslog.Debug.PrintfLn("OSS V4 canonical request: %s", canonicalRequest)
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		ossV4Algorithm, datetime, scope, hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSha256([]byte("aliyun_v4"+t.AccessKeySecret), date)
+	signingKey = hmacSha256(signingKey, options.Region)
+	signingKey = hmacSha256(signingKey, "oss")
+	signingKey = hmacSha256(signingKey, ossV4Request)
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+	return canonicalQuery + "&x-oss-signature=" + signature, nil
+}
+
+// canonicalOssQuery sorted and escaped query string
+func canonicalOssQuery(query map[string]string) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		params = append(params, ossEscape(k, true)+"="+ossEscape(query[k], true))
+	}
+	return strings.Join(params, "&")
+}
+
+// ossEscape escape except unreserved characters(RFC 3986), `/` is kept when encodeSlash is false
+func ossEscape(s string, encodeSlash bool) string {
+	escaped := strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	escaped = strings.ReplaceAll(escaped, "%7E", "~")
+	if !encodeSlash {
+		escaped = strings.ReplaceAll(escaped, "%2F", "/")
+	}
+	return escaped
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,181 @@
+package alibaba_cloud
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+)
+
+func TestPresignOssQuery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiration := now.Add(time.Hour)
+	tests := []struct {
+		name     string
+		version  string
+		method   string
+		options  *PresignOssUrlOptions
+		stsToken string
+		expected string
+	}{
+		{
+			name:     "v1 GET with STS token",
+			version:  OssSignatureVersionV1,
+			method:   "GET",
+			options:  &PresignOssUrlOptions{Bucket: "bucket1", Key: "dir/a b.txt"},
+			stsToken: "tok1/+=",
+			expected: "Expires=1704070800&OSSAccessKeyId=ak1&Signature=i2D27L6FF1nYa%2F%2Bm1fb1KF0U0Z8%3D" +
+				"&security-token=tok1%2F%2B%3D",
+		},
+		{
+			name:     "v1 PUT with content type",
+			version:  OssSignatureVersionV1,
+			method:   "PUT",
+			options:  &PresignOssUrlOptions{Bucket: "bucket1", Key: "dir/a b.txt", ContentType: "text/plain"},
+			expected: "Expires=1704070800&OSSAccessKeyId=ak1&Signature=qN8mbfLyNBpNd%2Fk7A28%2FLs%2BAxvU%3D",
+		},
+		{
+			name:     "v4 GET with STS token",
+			version:  OssSignatureVersionV4,
+			method:   "GET",
+			options:  &PresignOssUrlOptions{Bucket: "bucket1", Key: "dir/a b.txt", Region: "cn-hangzhou"},
+			stsToken: "tok1/+=",
+			expected: "x-oss-credential=ak1%2F20240101%2Fcn-hangzhou%2Foss%2Faliyun_v4_request" +
+				"&x-oss-date=20240101T000000Z&x-oss-expires=3600&x-oss-security-token=tok1%2F%2B%3D" +
+				"&x-oss-signature-version=OSS4-HMAC-SHA256" +
+				"&x-oss-signature=c29bef5a59cfa6836cd6cd4e873367b32eba6f020b0086c2527dd35568b413d2",
+		},
+		{
+			name:    "v4 PUT with content type",
+			version: OssSignatureVersionV4,
+			method:  "PUT",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "dir/a b.txt", Region: "cn-hangzhou",
+				ContentType: "text/plain"},
+			expected: "x-oss-credential=ak1%2F20240101%2Fcn-hangzhou%2Foss%2Faliyun_v4_request" +
+				"&x-oss-date=20240101T000000Z&x-oss-expires=3600&x-oss-signature-version=OSS4-HMAC-SHA256" +
+				"&x-oss-signature=4c421e083341391b374da62c2f41ab9d995759f903d4b0e1f43127de5bf32ca9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stsToken := &StsToken{AccessKeyId: "ak1", AccessKeySecret: "sk1", StsToken: tt.stsToken}
+			var query string
+			var err error
+			if tt.version == OssSignatureVersionV1 {
+				query = stsToken.presignOssQueryV1(tt.method, expiration, tt.options)
+			} else {
+				query, err = stsToken.presignOssQueryV4(tt.method, now, expiration, tt.options)
+			}
+			if err != nil {
+				t.Fatalf("presign failed: %v", err)
+			}
+			if query != tt.expected {
+				t.Errorf("expected query: %s, got: %s", tt.expected, query)
+			}
+		})
+	}
+}
+
+func TestPresignOssUrl(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(constants.EnvDisableClockSkew, "true")
+	stsExpiration := time.Now().Add(30 * time.Minute).UTC().Truncate(time.Second)
+	tests := []struct {
+		name       string
+		expiration string
+		options    *PresignOssUrlOptions
+		prefix     string
+		expires    time.Duration // expected validity, 0 when stsExpiration is expected
+		err        string
+	}{
+		{
+			name:    "default v4 and endpoint",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "dir/a b.txt", Region: "cn-hangzhou", Expires: time.Minute},
+			prefix:  "https://bucket1.oss-cn-hangzhou.aliyuncs.com/dir/a%20b.txt?x-oss-credential=ak1%2F",
+			expires: time.Minute,
+		},
+		{
+			name: "v1 with endpoint scheme",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Endpoint: "http://oss.example.com",
+				Expires: time.Minute, SignatureVersion: OssSignatureVersionV1, Method: "put"},
+			prefix:  "http://bucket1.oss.example.com/a.txt?Expires=",
+			expires: time.Minute,
+		},
+		{
+			name:       "capped at STS token expiration",
+			expiration: stsExpiration.Format(time.RFC3339),
+			options:    &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou", Expires: time.Hour},
+			prefix:     "https://bucket1.oss-cn-hangzhou.aliyuncs.com/a.txt?",
+		},
+		{
+			name:       "STS token expired",
+			expiration: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+			options:    &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou", Expires: time.Hour},
+			err:        "STS token is expired",
+		},
+		{
+			name:    "bucket required",
+			options: &PresignOssUrlOptions{Key: "a.txt", Region: "cn-hangzhou", Expires: time.Hour},
+			err:     "bucket and key are required",
+		},
+		{
+			name: "invalid method",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou", Method: "DELETE",
+				Expires: time.Hour},
+			err: "invalid method: DELETE, supports GET or PUT",
+		},
+		{
+			name:    "expires required",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou"},
+			err:     "expires must be positive",
+		},
+		{
+			name:    "endpoint or region required",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Expires: time.Hour},
+			err:     "OSS Endpoint or Region at least one is required",
+		},
+		{
+			name:    "v4 region required",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Endpoint: "oss.example.com", Expires: time.Hour},
+			err:     "Region is required by OSS V4 signature",
+		},
+		{
+			name:    "v4 max expires",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou", Expires: 8 * 24 * time.Hour},
+			err:     "expires: 192h0m0s exceeds OSS V4 max: 168h0m0s",
+		},
+		{
+			name: "invalid signature version",
+			options: &PresignOssUrlOptions{Bucket: "bucket1", Key: "a.txt", Region: "cn-hangzhou", Expires: time.Hour,
+				SignatureVersion: "v2"},
+			err: "invalid signature version: v2, supports v4 or v1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stsToken := &StsToken{AccessKeyId: "ak1", AccessKeySecret: "sk1", StsToken: "tok1", Expiration: tt.expiration}
+			startTime := time.Now().UTC().Truncate(time.Second)
+			presignedUrl, expiration, err := stsToken.PresignOssUrl(tt.options)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error: %s, got: %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("presign failed: %v", err)
+			}
+			if !strings.HasPrefix(presignedUrl, tt.prefix) {
+				t.Errorf("expected URL prefix: %s, got: %s", tt.prefix, presignedUrl)
+			}
+			if tt.expires == 0 {
+				if !expiration.Equal(stsExpiration) {
+					t.Errorf("expected expiration: %s, got: %s", stsExpiration, expiration)
+				}
+			} else if validity := expiration.Sub(startTime); validity < tt.expires || validity > tt.expires+time.Second {
+				t.Errorf("expected validity: %s, got: %s", tt.expires, validity)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	sts20150401 "github.com/alibabacloud-go/sts-20150401/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+type FetchStsWithAssumeRoleOptions struct {
+	Endpoints             []*StsEndpoint // primary endpoint first, then fallback endpoints
+	RoleArn               string
+	DurationSeconds       int64
+	Policy                string
+	ExternalId            string
+	RoleSessionName       string
+	FetchPreviousStsToken func() (*StsToken, error)
+	ForceNew              bool
+}
+
+// StsTokenHop STS token of one hop in the assume role chain
+type StsTokenHop struct {
+	RoleArn  string
+	StsToken *StsToken
+}
+
+// FetchStsChainWithOidcConfig fetch STS tokens of all hops, AssumeRoleWithOIDC (or AssumeRole with credential source)
+// first, then AssumeRole chain in order
+func FetchStsChainWithOidcConfig(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	configOptions *FetchStsWithOidcConfigOptions) (
+	[]*StsTokenHop, error) {
+
+	fetchStsTokens, err := buildFetchStsTokenChain(ctx, profile, alibabaCloudStsConfig, configOptions)
+	if err != nil {
+		return nil, err
+	}
+	var stsTokenHops []*StsTokenHop
+	for i, fetchStsToken := range fetchStsTokens {
+		stsToken, err := fetchStsToken()
+		if err != nil {
+			return nil, err
+		}
+		roleArn := alibabaCloudStsConfig.RoleArn
+		if i > 0 {
+			roleArn = alibabaCloudStsConfig.AssumeRoleChain[i-1].RoleArn
+		}
+		stsTokenHops = append(stsTokenHops, &StsTokenHop{
+			RoleArn:  roleArn,
+			StsToken: stsToken,
+		})
+	}
+	return stsTokenHops, nil
+}
+
+// buildFetchStsTokenChain returns fetch functions for every hop, each hop only fetches previous hop when
+// its own cache is expiring or expired
+func buildFetchStsTokenChain(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	configOptions *FetchStsWithOidcConfigOptions) (
+	[]func() (*StsToken, error), error) {
+
+	if alibabaCloudStsConfig.OidcTokenProvider == nil && alibabaCloudStsConfig.CredentialSource == nil {
+		return nil, errors.New("OidcTokenProvider or CredentialSource is required")
+	}
+	if alibabaCloudStsConfig.OidcTokenProvider != nil && alibabaCloudStsConfig.CredentialSource != nil {
+		return nil, errors.New("only one may be specified; OidcTokenProvider or CredentialSource")
+	}
+	if alibabaCloudStsConfig.CredentialSource != nil {
+		err := checkCredentialSource(alibabaCloudStsConfig.CredentialSource)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stsEndpoints, err := getStsEndpoints(alibabaCloudStsConfig)
+	if err != nil {
+		return nil, err
+	}
+	var policies []string
+	for i, assumeRoleConfig := range alibabaCloudStsConfig.AssumeRoleChain {
+		if assumeRoleConfig == nil || assumeRoleConfig.RoleArn == "" {
+			return nil, errors.Errorf("AssumeRoleChain #%d RoleArn is required", i)
+		}
+		policy, err := ReadPolicy(assumeRoleConfig.Policy, assumeRoleConfig.PolicyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "AssumeRoleChain #%d", i)
+		}
+		policies = append(policies, policy)
+	}
+
+	var fetchStsTokens []func() (*StsToken, error)
+	fetchStsTokens = append(fetchStsTokens, memoizeFetchStsToken(func() (*StsToken, error) {
+		if alibabaCloudStsConfig.CredentialSource != nil {
+			return fetchStsWithCredentialSource(ctx, profile, stsEndpoints, alibabaCloudStsConfig, configOptions)
+		}
+		return fetchStsWithOidcConfig(ctx, profile, stsEndpoints, alibabaCloudStsConfig, configOptions)
+	}))
+	for i, assumeRoleConfig := range alibabaCloudStsConfig.AssumeRoleChain {
+		hop := i + 1
+		options := &FetchStsWithAssumeRoleOptions{
+			Endpoints:             stsEndpoints,
+			RoleArn:               assumeRoleConfig.RoleArn,
+			DurationSeconds:       assumeRoleConfig.DurationSeconds,
+			Policy:                policies[i],
+			ExternalId:            assumeRoleConfig.ExternalId,
+			RoleSessionName:       assumeRoleConfig.RoleSessionName,
+			FetchPreviousStsToken: fetchStsTokens[i],
+			ForceNew:              configOptions.ForceNew,
+		}
+		fetchStsTokens = append(fetchStsTokens, memoizeFetchStsToken(func() (*StsToken, error) {
+			return FetchStsWithAssumeRole(ctx, profile, hop, alibabaCloudStsConfig, options)
+		}))
+	}
+	return fetchStsTokens, nil
+}
+
+// FetchStsWithAssumeRole fetch STS token via AssumeRole with previous hop's STS token, hop starts from 1
+func FetchStsWithAssumeRole(ctx context.Context, profile string, hop int, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	options *FetchStsWithAssumeRoleOptions) (*StsToken, error) {
+	digest := alibabaCloudStsConfig.AssumeRoleChainDigest(hop)
+	serverHost := getStsServerHost(options.Endpoints)
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"hop":     hop,
+			"config":  alibabaCloudStsConfig.AssumeRoleChain[hop-1],
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchAssumeRoleContent(ctx, options)
+		},
+		ForceNew: options.ForceNew,
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s, hop: %d", constants.CategoryCloudToken, cacheKey, hop)
+	stsTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with assume role, hop: %d: %v", hop, err)
+		return nil, err
+	}
+	return UnmarshalStsToken(stsTokenStr)
+}
+
+func fetchAssumeRoleContent(ctx context.Context, options *FetchStsWithAssumeRoleOptions) (int, string, error) {
+	previousStsToken, err := options.FetchPreviousStsToken()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetching previous sts token: %v", err)
+		return 600, "", err
+	}
+	var stsResponse *sts20150401.AssumeRoleResponse
+	serverHost, err := callStsWithFailover(ctx, options.Endpoints, previousStsToken,
+		func(client *sts20150401.Client, runtime *util.RuntimeOptions) (err error) {
+			stsResponse, err = assumeRole(client, runtime, options)
+			return err
+		})
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error assuming role: %v", err)
+		return 600, "", err
+	}
+	if *stsResponse.StatusCode != 200 {
+		idaaslog.Error.PrintfLn("failed assume role, status: %v", *stsResponse.StatusCode)
+		return int(*stsResponse.StatusCode), "", errors.Errorf(
+			"failed assume role, status: %d", *stsResponse.StatusCode)
+	}
+	credentials := stsResponse.Body.Credentials
+	stsToken := &StsToken{
+		Mode:            "StsToken",
+		AccessKeyId:     *credentials.AccessKeyId,
+		AccessKeySecret: *credentials.AccessKeySecret,
+		StsToken:        *credentials.SecurityToken,
+		Expiration:      *credentials.Expiration,
+		ServerHost:      serverHost,
+	}
+	stsTokenJson, err := stsToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling sts token: %v", err)
+		return 600, "", err
+	}
+	return 200, stsTokenJson, nil
+}
+
+func assumeRole(client *sts20150401.Client, runtime *util.RuntimeOptions, options *FetchStsWithAssumeRoleOptions) (
+	*sts20150401.AssumeRoleResponse, error) {
+
+	var roleSessionName string
+	if options.RoleSessionName != "" {
+		roleSessionName = options.RoleSessionName
+	} else {
+		roleSessionName = cloud_common.GenerateRoleSessionName("")
+		idaaslog.Info.PrintfLn(
+			"Assume role session name not specified, use role session name %s", roleSessionName)
+	}
+	idaaslog.Debug.PrintfLn("Assume role, RoleArn: %s, RoleSessionName: %s",
+		options.RoleArn, roleSessionName)
+	assumeRoleRequest := &sts20150401.AssumeRoleRequest{
+		RoleArn:         tea.String(options.RoleArn),
+		RoleSessionName: tea.String(roleSessionName),
+	}
+	if options.DurationSeconds > 0 {
+		assumeRoleRequest.DurationSeconds = tea.Int64(options.DurationSeconds)
+	}
+	if options.Policy != "" {
+		assumeRoleRequest.Policy = tea.String(options.Policy)
+	}
+	if options.ExternalId != "" {
+		assumeRoleRequest.ExternalId = tea.String(options.ExternalId)
+	}
+	stsResponse, err := client.AssumeRoleWithOptions(assumeRoleRequest, runtime)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error assume role: %v", err)
+	}
+	return stsResponse, err
+}
+
+// memoizeFetchStsToken fetch at most once, hops share the same previous hop
+func memoizeFetchStsToken(fetchStsToken func() (*StsToken, error)) func() (*StsToken, error) {
+	var once sync.Once
+	var stsToken *StsToken
+	var err error
+	return func() (*StsToken, error) {
+		once.Do(func() {
+			stsToken, err = fetchStsToken()
+		})
+		return stsToken, err
+	}
+}
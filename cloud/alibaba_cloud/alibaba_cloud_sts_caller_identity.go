@@ -0,0 +1,54 @@
+package alibaba_cloud
+
+import (
+	"context"
+
+	sts20150401 "github.com/alibabacloud-go/sts-20150401/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/pkg/errors"
+)
+
+const DefaultStsEndpoint = "sts.aliyuncs.com"
+
+// GetCallerIdentity verify STS token and get caller identity, endpoint is optional,
+// by default use profile's STS endpoint and fallback endpoints
+// reference: https://api.aliyun.com/document/Sts/2015-04-01/GetCallerIdentity
+func GetCallerIdentity(ctx context.Context, stsToken *StsToken, alibabaCloudStsConfig *config.AlibabaCloudStsConfig, endpoint string) (
+	*cloud_common.CallerIdentity, error) {
+	stsEndpoints := []*StsEndpoint{{Endpoint: DefaultStsEndpoint}}
+	if endpoint != "" {
+		stsEndpoints = []*StsEndpoint{{Endpoint: endpoint}}
+	} else if alibabaCloudStsConfig != nil {
+		profileStsEndpoints, err := getStsEndpoints(alibabaCloudStsConfig)
+		if err == nil {
+			stsEndpoints = profileStsEndpoints
+		}
+	}
+	var response *sts20150401.GetCallerIdentityResponse
+	_, err := callStsWithFailover(ctx, stsEndpoints, stsToken,
+		func(client *sts20150401.Client, runtime *util.RuntimeOptions) (err error) {
+			idaaslog.Debug.PrintfLn("Get caller identity, endpoint: %s", tea.StringValue(client.Endpoint))
+			response, err = client.GetCallerIdentityWithOptions(runtime)
+			return err
+		})
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error get caller identity: %v", err)
+		return nil, errors.Wrap(err, "get caller identity failed")
+	}
+	if response.StatusCode == nil || *response.StatusCode != 200 || response.Body == nil {
+		return nil, errors.Errorf("get caller identity failed, status: %d", tea.Int32Value(response.StatusCode))
+	}
+	body := response.Body
+	arn := tea.StringValue(body.Arn)
+	return &cloud_common.CallerIdentity{
+		Account:      tea.StringValue(body.AccountId),
+		Arn:          arn,
+		PrincipalId:  tea.StringValue(body.PrincipalId),
+		IdentityType: tea.StringValue(body.IdentityType),
+		SessionName:  cloud_common.ParseSessionNameFromArn(arn),
+	}, nil
+}
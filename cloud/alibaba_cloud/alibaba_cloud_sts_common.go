@@ -3,6 +3,7 @@ package alibaba_cloud
 import (
 	"encoding/json"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
 	"time"
 )
@@ -18,6 +19,8 @@ type StsToken struct {
 	AccessKeySecret string `json:"access_key_secret"`
 	StsToken        string `json:"sts_token"`
 	Expiration      string `json:"expiration"`
+	// ServerHost host of STS endpoint issued the token, empty for source credentials, not in output formats
+	ServerHost string `json:"server_host,omitempty"`
 }
 
 // StsTokenOssutilv2
@@ -78,7 +81,9 @@ func (t *StsToken) Marshal() (string, error) {
 func (t *StsToken) MarshalWithFormat(format string) (string, error) {
 	var token any
 	if format == "" || format == FormatAliyuncli {
-		token = t
+		aliyuncliToken := *t
+		aliyuncliToken.ServerHost = ""
+		token = &aliyuncliToken
 	} else if format == FormatOssutilv2 {
 		token = t.ConvertToOssutilv2()
 	} else {
@@ -100,7 +105,11 @@ func UnmarshalStsToken(token string) (*StsToken, error) {
 	return &stsToken, nil
 }
 
-func (t *StsToken) IsValidAtLeastThreshold(thresholdDuration time.Duration) bool {
+// IsValidAtLeastThreshold serverHost is used when token has no ServerHost, e.g. cached by older versions
+func (t *StsToken) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
+	if t.ServerHost != "" {
+		serverHost = t.ServerHost
+	}
 	idaaslog.Debug.PrintfLn("Check is valid, expiration: %s, threshold: %d ms",
 		t.Expiration, thresholdDuration.Milliseconds())
 	expiration, err := time.Parse(time.RFC3339Nano, t.Expiration)
@@ -108,7 +117,7 @@ func (t *StsToken) IsValidAtLeastThreshold(thresholdDuration time.Duration) bool
 		idaaslog.Error.PrintfLn("Error parsing expiration: %s", t.Expiration)
 		return false
 	}
-	valid := time.Until(expiration) > thresholdDuration
+	valid := utils.UntilFor(serverHost, expiration) > thresholdDuration
 	idaaslog.Info.PrintfLn("Check is valid: %s", valid)
 	return valid
 }
@@ -0,0 +1,199 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const DefaultEcsMetadataEndpoint = "http://100.100.100.200"
+
+// ecsRamRoleCredentials
+// reference: https://help.aliyun.com/zh/ecs/user-guide/attach-an-instance-ram-role-to-an-ecs-instance
+type ecsRamRoleCredentials struct {
+	Code            string `json:"Code"`
+	AccessKeyId     string `json:"AccessKeyId"`
+	AccessKeySecret string `json:"AccessKeySecret"`
+	SecurityToken   string `json:"SecurityToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// fetchStsWithCredentialSource fetch STS token via AssumeRole with credential source, cached as AssumeRoleWithOIDC
+func fetchStsWithCredentialSource(ctx context.Context, profile string, stsEndpoints []*StsEndpoint, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	configOptions *FetchStsWithOidcConfigOptions) (*StsToken, error) {
+	policy, err := ReadPolicy(alibabaCloudStsConfig.Policy, alibabaCloudStsConfig.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	options := &FetchStsWithAssumeRoleOptions{
+		Endpoints:       stsEndpoints,
+		RoleArn:         alibabaCloudStsConfig.RoleArn,
+		DurationSeconds: alibabaCloudStsConfig.DurationSeconds,
+		Policy:          policy,
+		RoleSessionName: alibabaCloudStsConfig.RoleSessionName,
+		FetchPreviousStsToken: func() (*StsToken, error) {
+			return FetchCredentialSource(ctx, alibabaCloudStsConfig.CredentialSource)
+		},
+		ForceNew: configOptions.ForceNew,
+	}
+
+	digest := alibabaCloudStsConfig.AssumeRoleChainDigest(0)
+	serverHost := getStsServerHost(options.Endpoints)
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"config":  alibabaCloudStsConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchAssumeRoleContent(ctx, options)
+		},
+		ForceNew: options.ForceNew,
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryCloudToken, cacheKey)
+	stsTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with credential source: %v", err)
+		return nil, err
+	}
+	return UnmarshalStsToken(stsTokenStr)
+}
+
+// FetchCredentialSource fetch source credentials, static AccessKey has no STS token and expiration
+func FetchCredentialSource(ctx context.Context, credentialSource *config.AlibabaCloudCredentialSource) (*StsToken, error) {
+	if credentialSource.EcsRamRole != nil {
+		return fetchEcsRamRoleCredentials(ctx, credentialSource.EcsRamRole)
+	}
+	if credentialSource.AccessKey != nil {
+		return readAccessKey(credentialSource.AccessKey)
+	}
+	return nil, errors.New("CredentialSource requires one; ecs_ram_role or access_key")
+}
+
+func checkCredentialSource(credentialSource *config.AlibabaCloudCredentialSource) error {
+	if credentialSource.EcsRamRole != nil && credentialSource.AccessKey != nil {
+		return errors.New("CredentialSource only one may be specified; ecs_ram_role or access_key")
+	}
+	if credentialSource.EcsRamRole == nil && credentialSource.AccessKey == nil {
+		return errors.New("CredentialSource requires one; ecs_ram_role or access_key")
+	}
+	return nil
+}
+
+func readAccessKey(accessKeyConfig *config.AlibabaCloudAccessKeyConfig) (*StsToken, error) {
+	if accessKeyConfig.AccessKeyId == "" {
+		return nil, errors.New("AccessKeyId is required")
+	}
+	accessKeySecret := accessKeyConfig.AccessKeySecret
+	if accessKeySecret == "" && accessKeyConfig.AccessKeySecretFile != "" {
+		accessKeySecretBytes, err := os.ReadFile(accessKeyConfig.AccessKeySecretFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read access key secret file: %s failed", accessKeyConfig.AccessKeySecretFile)
+		}
+		accessKeySecret = strings.TrimSpace(string(accessKeySecretBytes))
+	}
+	if accessKeySecret == "" && accessKeyConfig.AccessKeySecretEnv != "" {
+		accessKeySecret = os.Getenv(accessKeyConfig.AccessKeySecretEnv)
+	}
+	if accessKeySecret == "" && accessKeyConfig.AccessKeySecretEncrypted != "" {
+		decryptedAccessKeySecret, err := DecryptAccessKeySecret(accessKeyConfig.AccessKeyId,
+			accessKeyConfig.AccessKeySecretEncrypted)
+		if err != nil {
+			return nil, err
+		}
+		accessKeySecret = decryptedAccessKeySecret
+	}
+	if accessKeySecret == "" {
+		return nil, errors.New(
+			"AccessKeySecret, AccessKeySecretFile, AccessKeySecretEnv or AccessKeySecretEncrypted is required")
+	}
+	idaaslog.Debug.PrintfLn("Use access key: %s", accessKeyConfig.AccessKeyId)
+	return &StsToken{
+		Mode:            "AK",
+		AccessKeyId:     accessKeyConfig.AccessKeyId,
+		AccessKeySecret: accessKeySecret,
+	}, nil
+}
+
+// EncryptAccessKeySecret encrypt access key secret with local encryption key, bound to access key id
+func EncryptAccessKeySecret(accessKeyId, accessKeySecret string) (string, error) {
+	return utils.EncryptText(accessKeySecret, []byte("access_key:"+accessKeyId))
+}
+
+func DecryptAccessKeySecret(accessKeyId, accessKeySecretEncrypted string) (string, error) {
+	accessKeySecret, err := utils.DecryptText(accessKeySecretEncrypted, []byte("access_key:"+accessKeyId))
+	if err != nil {
+		return "", errors.Wrapf(err, "decrypt access key secret of: %s failed, it can only be decrypted on the machine "+
+			"where it was encrypted", accessKeyId)
+	}
+	return accessKeySecret, nil
+}
+
+func fetchEcsRamRoleCredentials(ctx context.Context, ecsRamRoleConfig *config.AlibabaCloudEcsRamRoleConfig) (
+	*StsToken, error) {
+	metadataEndpoint := strings.TrimSuffix(ecsRamRoleConfig.MetadataEndpoint, "/")
+	if metadataEndpoint == "" {
+		metadataEndpoint = DefaultEcsMetadataEndpoint
+	}
+	client := utils.GetHttpClient(ctx)
+	headers := map[string]string{}
+	token, err := utils.FetchAsString(ctx, client, utils.HttpMethodPut, metadataEndpoint+"/latest/api/token",
+		map[string]string{
+			"X-aliyun-ecs-metadata-token-ttl-seconds": "3600",
+		})
+	if err != nil {
+		if ecsRamRoleConfig.DisableImdsV1 {
+			return nil, errors.Wrap(err, "fetch ECS metadata token failed")
+		}
+		idaaslog.Warn.PrintfLn("Fetch ECS metadata token failed, fallback to IMDSv1: %v", err)
+	} else {
+		headers["X-aliyun-ecs-metadata-token"] = token
+	}
+
+	securityCredentialsEndpoint := metadataEndpoint + "/latest/meta-data/ram/security-credentials/"
+	roleName := ecsRamRoleConfig.RoleName
+	if roleName == "" {
+		roleName, err = utils.FetchAsString(ctx, client, utils.HttpMethodGet, securityCredentialsEndpoint, headers)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch ECS RAM role name failed")
+		}
+		roleName = strings.TrimSpace(roleName)
+		idaaslog.Debug.PrintfLn("Found ECS RAM role name: %s", roleName)
+	}
+	credentialsBytes, err := utils.Fetch(ctx, client, utils.HttpMethodGet, securityCredentialsEndpoint+roleName, headers)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetch ECS RAM role: %s credentials failed", roleName)
+	}
+	var credentials ecsRamRoleCredentials
+	err = json.Unmarshal(credentialsBytes, &credentials)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse ECS RAM role credentials failed")
+	}
+	if credentials.Code != "Success" {
+		return nil, errors.Errorf("fetch ECS RAM role: %s credentials failed, code: %s", roleName, credentials.Code)
+	}
+	return &StsToken{
+		Mode:            "StsToken",
+		AccessKeyId:     credentials.AccessKeyId,
+		AccessKeySecret: credentials.AccessKeySecret,
+		StsToken:        credentials.SecurityToken,
+		Expiration:      credentials.Expiration,
+	}, nil
+}
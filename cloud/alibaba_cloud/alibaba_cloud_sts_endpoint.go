@@ -0,0 +1,188 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	sts20150401 "github.com/alibabacloud-go/sts-20150401/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	NetworkPublic    = "public"
+	NetworkVpc       = "vpc"
+	NetworkDualStack = "dualstack"
+)
+
+const (
+	defaultStsMaxAttempts = 3
+	stsRetryBackoff       = 200 * time.Millisecond
+)
+
+// StsEndpoint STS endpoint with its own timeouts(milliseconds) and retry budget, 0 means default
+type StsEndpoint struct {
+	Endpoint       string
+	ConnectTimeout int
+	ReadTimeout    int
+	MaxAttempts    int
+}
+
+func getStsEndpoint(alibabaCloudStsConfig *config.AlibabaCloudStsConfig) (string, error) {
+	stsEndpoint := alibabaCloudStsConfig.StsEndpoint
+	if stsEndpoint == "" {
+		if alibabaCloudStsConfig.Region == "" {
+			return "", errors.New("StsEndpoint or Region at least one is required")
+		}
+		// Endpoint referer: https://api.aliyun.com/product/Sts
+		switch alibabaCloudStsConfig.Network {
+		case "", NetworkPublic:
+			stsEndpoint = fmt.Sprintf("sts.%s.aliyuncs.com", alibabaCloudStsConfig.Region)
+		case NetworkVpc, NetworkDualStack:
+			stsEndpoint = fmt.Sprintf("sts-%s.%s.aliyuncs.com",
+				alibabaCloudStsConfig.Network, alibabaCloudStsConfig.Region)
+		default:
+			return "", errors.Errorf("invalid network: %s, supports public, vpc or dualstack",
+				alibabaCloudStsConfig.Network)
+		}
+		idaaslog.Debug.PrintfLn("Get sts endpoint: %s", stsEndpoint)
+	}
+	return stsEndpoint, nil
+}
+
+// getStsEndpoints returns primary STS endpoint and fallback endpoints in order
+func getStsEndpoints(alibabaCloudStsConfig *config.AlibabaCloudStsConfig) ([]*StsEndpoint, error) {
+	stsEndpoint, err := getStsEndpoint(alibabaCloudStsConfig)
+	if err != nil {
+		return nil, err
+	}
+	stsEndpoints := []*StsEndpoint{{
+		Endpoint:       stsEndpoint,
+		ConnectTimeout: alibabaCloudStsConfig.StsConnectTimeout,
+		ReadTimeout:    alibabaCloudStsConfig.StsReadTimeout,
+		MaxAttempts:    alibabaCloudStsConfig.StsMaxAttempts,
+	}}
+	for i, fallbackEndpoint := range alibabaCloudStsConfig.FallbackEndpoints {
+		if fallbackEndpoint == nil || fallbackEndpoint.Endpoint == "" {
+			return nil, errors.Errorf("FallbackEndpoints #%d Endpoint is required", i)
+		}
+		stsEndpoints = append(stsEndpoints, &StsEndpoint{
+			Endpoint:       fallbackEndpoint.Endpoint,
+			ConnectTimeout: orDefault(fallbackEndpoint.ConnectTimeout, alibabaCloudStsConfig.StsConnectTimeout),
+			ReadTimeout:    orDefault(fallbackEndpoint.ReadTimeout, alibabaCloudStsConfig.StsReadTimeout),
+			MaxAttempts:    orDefault(fallbackEndpoint.MaxAttempts, alibabaCloudStsConfig.StsMaxAttempts),
+		})
+	}
+	return stsEndpoints, nil
+}
+
+// getStsServerHost returns host of primary STS endpoint, its clock skew is used to check expiry of STS token
+// cached without server host
+func getStsServerHost(stsEndpoints []*StsEndpoint) string {
+	if len(stsEndpoints) == 0 {
+		return ""
+	}
+	return stsEndpoints[0].Endpoint
+}
+
+func (e *StsEndpoint) getMaxAttempts() int {
+	return orDefault(e.MaxAttempts, defaultStsMaxAttempts)
+}
+
+// buildHttpClient HTTP client carried by ctx(see utils.WithHttpClient), timeouts of endpoint are summed up
+// as timeout of whole request
+func (e *StsEndpoint) buildHttpClient(ctx context.Context) *http.Client {
+	httpClient := utils.GetHttpClient(ctx)
+	if e.ConnectTimeout <= 0 && e.ReadTimeout <= 0 {
+		return httpClient
+	}
+	endpointHttpClient := *httpClient
+	endpointHttpClient.Timeout = time.Duration(e.ConnectTimeout+e.ReadTimeout) * time.Millisecond
+	return &endpointHttpClient
+}
+
+// stsHttpClient implements HttpClient of tea SDK, sends requests with ctx and observes clock skew of endpoint
+type stsHttpClient struct {
+	ctx        context.Context
+	httpClient *http.Client
+}
+
+// Call transport built by tea SDK is ignored, transport of httpClient is used
+func (c *stsHttpClient) Call(request *http.Request, _ *http.Transport) (*http.Response, error) {
+	requestTime := time.Now()
+	response, err := c.httpClient.Do(request.WithContext(c.ctx))
+	if err == nil {
+		utils.ObserveServerDate(response, requestTime)
+	}
+	return response, err
+}
+
+// callStsWithFailover call STS endpoints in order, each endpoint is tried at most MaxAttempts times(default 3),
+// retry and fail over to next endpoint only on network or 5xx errors, other errors(e.g. 4xx) are returned
+// immediately, stsToken is required when call AssumeRole.
+// Returns host of the endpoint succeeded, tokens issued by it are checked with its clock skew.
+func callStsWithFailover(ctx context.Context, stsEndpoints []*StsEndpoint, stsToken *StsToken,
+	callSts func(client *sts20150401.Client, runtime *util.RuntimeOptions) error) (string, error) {
+	if len(stsEndpoints) == 0 {
+		return "", errors.New("no STS endpoint")
+	}
+	var err error
+	for i, stsEndpoint := range stsEndpoints {
+		client, createClientErr := createStsClientWithStsToken(ctx, stsEndpoint, stsToken)
+		if createClientErr != nil {
+			return "", createClientErr
+		}
+		maxAttempts := stsEndpoint.getMaxAttempts()
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			if attempt > 0 {
+				idaaslog.Debug.PrintfLn("Retry STS endpoint %s, attempt: %d, last error: %v",
+					stsEndpoint.Endpoint, attempt+1, err)
+				if waitErr := waitStsRetryBackoff(ctx, attempt); waitErr != nil {
+					return "", waitErr
+				}
+			} else if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", errors.Wrap(ctxErr, "call STS canceled")
+			}
+			err = callSts(client, &util.RuntimeOptions{})
+			if err == nil {
+				return stsEndpoint.Endpoint, nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", errors.Wrap(ctxErr, "call STS canceled")
+			}
+			if !tea.BoolValue(tea.Retryable(err)) {
+				return "", err
+			}
+		}
+		if i < len(stsEndpoints)-1 {
+			idaaslog.Warn.PrintfLn("Call STS endpoint %s failed: %v, fail over to %s",
+				stsEndpoint.Endpoint, err, stsEndpoints[i+1].Endpoint)
+		}
+	}
+	return "", err
+}
+
+// waitStsRetryBackoff waits attempt * stsRetryBackoff before next attempt, returns error when ctx is done
+func waitStsRetryBackoff(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(time.Duration(attempt) * stsRetryBackoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "call STS canceled")
+	case <-timer.C:
+		return nil
+	}
+}
+
+func orDefault(value, defaultValue int) int {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,159 @@
+package alibaba_cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	sts20150401 "github.com/alibabacloud-go/sts-20150401/v2/client"
+	util "github.com/alibabacloud-go/tea-utils/v2/service"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+)
+
+const testCallerIdentityResponse = `{"RequestId":"r1","AccountId":"123","Arn":"acs:ram::123:user/u1",` +
+	`"PrincipalId":"p1","IdentityType":"RAMUser","UserId":"u1"}`
+
+// newTestStsServer responds statusCodes in order, the last one is repeated
+func newTestStsServer(t *testing.T, statusCodes ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		statusCode := statusCodes[min(call, len(statusCodes))-1]
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		if statusCode == http.StatusOK {
+			_, _ = fmt.Fprint(w, testCallerIdentityResponse)
+		} else {
+			_, _ = fmt.Fprintf(w, `{"RequestId":"r1","Code":"Error%d","Message":"test error"}`, statusCode)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func callTestSts(ctx context.Context, stsEndpoints []*StsEndpoint) (string, error) {
+	stsToken := &StsToken{AccessKeyId: "ak1", AccessKeySecret: "sk1"}
+	return callStsWithFailover(ctx, stsEndpoints, stsToken,
+		func(client *sts20150401.Client, runtime *util.RuntimeOptions) error {
+			_, err := client.GetCallerIdentityWithOptions(runtime)
+			return err
+		})
+}
+
+func TestCallStsWithFailover(t *testing.T) {
+	tests := []struct {
+		name                string
+		primaryStatusCodes  []int
+		fallbackStatusCodes []int
+		maxAttempts         int
+		expectedFallback    bool
+		expectedErr         bool
+		expectedCalls       int32
+		expectedFallbacks   int32
+	}{
+		{name: "success", primaryStatusCodes: []int{200}, expectedCalls: 1},
+		{name: "retry 5xx", primaryStatusCodes: []int{500, 503, 200}, expectedCalls: 3},
+		{
+			name:                "fail over after max attempts",
+			primaryStatusCodes:  []int{500},
+			fallbackStatusCodes: []int{200},
+			expectedFallback:    true,
+			expectedCalls:       3,
+			expectedFallbacks:   1,
+		},
+		{
+			name:                "fail over with custom max attempts",
+			primaryStatusCodes:  []int{502},
+			fallbackStatusCodes: []int{200},
+			maxAttempts:         1,
+			expectedFallback:    true,
+			expectedCalls:       1,
+			expectedFallbacks:   1,
+		},
+		{
+			name:                "no retry on 4xx",
+			primaryStatusCodes:  []int{403, 200},
+			fallbackStatusCodes: []int{200},
+			expectedErr:         true,
+			expectedCalls:       1,
+		},
+		{
+			name:                "all endpoints failed",
+			primaryStatusCodes:  []int{500},
+			fallbackStatusCodes: []int{500},
+			maxAttempts:         2,
+			expectedErr:         true,
+			expectedCalls:       2,
+			expectedFallbacks:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, primaryCalls := newTestStsServer(t, tt.primaryStatusCodes...)
+			stsEndpoints := []*StsEndpoint{{Endpoint: primary.URL, MaxAttempts: tt.maxAttempts}}
+			fallbackCalls := new(int32)
+			var fallback *httptest.Server
+			if tt.fallbackStatusCodes != nil {
+				fallback, fallbackCalls = newTestStsServer(t, tt.fallbackStatusCodes...)
+				stsEndpoints = append(stsEndpoints, &StsEndpoint{Endpoint: fallback.URL, MaxAttempts: tt.maxAttempts})
+			}
+			serverHost, err := callTestSts(context.Background(), stsEndpoints)
+			if tt.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedErr, err)
+			}
+			expectedServerHost := primary.URL
+			if tt.expectedErr {
+				expectedServerHost = ""
+			} else if tt.expectedFallback {
+				expectedServerHost = fallback.URL
+			}
+			if serverHost != expectedServerHost {
+				t.Errorf("expected server host: %s, got: %s", expectedServerHost, serverHost)
+			}
+			if calls := atomic.LoadInt32(primaryCalls); calls != tt.expectedCalls {
+				t.Errorf("expected primary calls: %d, got: %d", tt.expectedCalls, calls)
+			}
+			if calls := atomic.LoadInt32(fallbackCalls); calls != tt.expectedFallbacks {
+				t.Errorf("expected fallback calls: %d, got: %d", tt.expectedFallbacks, calls)
+			}
+		})
+	}
+}
+
+type countingTransport struct {
+	calls int32
+}
+
+func (t *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestCallStsWithFailoverHttpClient(t *testing.T) {
+	server, calls := newTestStsServer(t, http.StatusOK)
+	transport := &countingTransport{}
+	ctx := utils.WithHttpClient(context.Background(), &http.Client{Transport: transport})
+	_, err := callTestSts(ctx, []*StsEndpoint{{Endpoint: server.URL}})
+	if err != nil {
+		t.Fatalf("call STS failed: %v", err)
+	}
+	if atomic.LoadInt32(&transport.calls) != 1 || atomic.LoadInt32(calls) != 1 {
+		t.Errorf("expected HTTP client of context called once, got: %d", atomic.LoadInt32(&transport.calls))
+	}
+}
+
+func TestCallStsWithFailoverCanceled(t *testing.T) {
+	server, calls := newTestStsServer(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := callTestSts(ctx, []*StsEndpoint{{Endpoint: server.URL}})
+	if err == nil {
+		t.Fatal("expected error of canceled context")
+	}
+	if atomic.LoadInt32(calls) != 0 {
+		t.Errorf("expected no STS call, got: %d", atomic.LoadInt32(calls))
+	}
+}
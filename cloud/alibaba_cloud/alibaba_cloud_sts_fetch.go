@@ -1,6 +1,7 @@
 package alibaba_cloud
 
 import (
+	"context"
 	"fmt"
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	sts20150401 "github.com/alibabacloud-go/sts-20150401/v2/client"
@@ -13,6 +14,7 @@ import (
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
+	"strings"
 	"time"
 )
 
@@ -21,49 +23,57 @@ type FetchStsWithOidcConfigOptions struct {
 }
 
 type FetchStsWithOidcOptions struct {
-	Endpoint        string
+	Endpoints       []*StsEndpoint // primary endpoint first, then fallback endpoints
 	OidcProviderArn string
 	RoleArn         string
 	DurationSeconds int64
 	RoleSessionName string
+	Policy          string
 	FetchOidcToken  func() (string, error)
 	ForceNew        bool
 }
 
-func FetchStsWithOidcConfig(profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+func FetchStsWithOidcConfig(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
 	configOptions *FetchStsWithOidcConfigOptions) (
 	*StsToken, error) {
 
-	if alibabaCloudStsConfig.OidcTokenProvider == nil {
-		return nil, errors.New("OidcTokenProvider is required")
+	fetchStsTokens, err := buildFetchStsTokenChain(ctx, profile, alibabaCloudStsConfig, configOptions)
+	if err != nil {
+		return nil, err
 	}
-	stsEndpoint := alibabaCloudStsConfig.StsEndpoint
-	if stsEndpoint == "" {
-		if alibabaCloudStsConfig.Region == "" {
-			return nil, errors.New("StsEndpoint or Region at least one is required")
-		}
-		stsEndpoint = fmt.Sprintf("sts.%s.aliyuncs.com", alibabaCloudStsConfig.Region)
-		idaaslog.Debug.PrintfLn("Get sts endpoint: %s", stsEndpoint)
+	// only the last hop is required, previous hops are fetched on demand
+	return fetchStsTokens[len(fetchStsTokens)-1]()
+}
+
+func fetchStsWithOidcConfig(ctx context.Context, profile string, stsEndpoints []*StsEndpoint, alibabaCloudStsConfig *config.AlibabaCloudStsConfig,
+	configOptions *FetchStsWithOidcConfigOptions) (
+	*StsToken, error) {
+
+	policy, err := ReadPolicy(alibabaCloudStsConfig.Policy, alibabaCloudStsConfig.PolicyFile)
+	if err != nil {
+		return nil, err
 	}
 	options := &FetchStsWithOidcOptions{
-		Endpoint:        stsEndpoint,
+		Endpoints:       stsEndpoints,
 		OidcProviderArn: alibabaCloudStsConfig.OidcProviderArn,
 		RoleArn:         alibabaCloudStsConfig.RoleArn,
 		RoleSessionName: alibabaCloudStsConfig.RoleSessionName,
 		DurationSeconds: alibabaCloudStsConfig.DurationSeconds,
+		Policy:          policy,
 		FetchOidcToken: func() (string, error) {
 			fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
 				ForceNew: configOptions.ForceNew,
 			}
-			return idp.FetchOidcToken(profile, alibabaCloudStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
+			return idp.FetchOidcToken(ctx, profile, alibabaCloudStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
 		},
 		ForceNew: configOptions.ForceNew,
 	}
-	return FetchStsWithOidc(profile, alibabaCloudStsConfig, options)
+	return FetchStsWithOidc(ctx, profile, alibabaCloudStsConfig, options)
 }
 
-func FetchStsWithOidc(profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig, options *FetchStsWithOidcOptions) (*StsToken, error) {
-	digest := alibabaCloudStsConfig.Digest()
+func FetchStsWithOidc(ctx context.Context, profile string, alibabaCloudStsConfig *config.AlibabaCloudStsConfig, options *FetchStsWithOidcOptions) (*StsToken, error) {
+	digest := alibabaCloudStsConfig.AssumeRoleChainDigest(0)
+	serverHost := getStsServerHost(options.Endpoints)
 	readCacheFileOptions := &utils.ReadCacheOptions{
 		Context: map[string]interface{}{
 			"profile": profile,
@@ -71,14 +81,14 @@ func FetchStsWithOidc(profile string, alibabaCloudStsConfig *config.AlibabaCloud
 			"config":  alibabaCloudStsConfig,
 		},
 		FetchContent: func() (int, string, error) {
-			return fetchContent(options)
+			return fetchContent(ctx, options)
 		},
 		ForceNew: options.ForceNew,
 		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpiringOrExpired(s)
+			return isContentExpiringOrExpired(serverHost, s)
 		},
 		IsContentExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpired(s)
+			return isContentExpired(serverHost, s)
 		},
 	}
 
@@ -93,18 +103,18 @@ func FetchStsWithOidc(profile string, alibabaCloudStsConfig *config.AlibabaCloud
 	return UnmarshalStsToken(stsTokenStr)
 }
 
-func fetchContent(options *FetchStsWithOidcOptions) (int, string, error) {
-	client, err := createStsClient(options.Endpoint)
-	if err != nil {
-		idaaslog.Error.PrintfLn("Error creating sts client: %v", err)
-		return 600, "", err
-	}
+func fetchContent(ctx context.Context, options *FetchStsWithOidcOptions) (int, string, error) {
 	oidcToken, err := options.FetchOidcToken()
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error fetching oidc token: %v", err)
 		return 600, "", err
 	}
-	stsResponse, err := assumeRoleWithOidc(client, oidcToken, options)
+	var stsResponse *sts20150401.AssumeRoleWithOIDCResponse
+	serverHost, err := callStsWithFailover(ctx, options.Endpoints, nil,
+		func(client *sts20150401.Client, runtime *util.RuntimeOptions) (err error) {
+			stsResponse, err = assumeRoleWithOidc(client, runtime, oidcToken, options)
+			return err
+		})
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error assuming role: %v", err)
 		return 600, "", err
@@ -112,7 +122,7 @@ func fetchContent(options *FetchStsWithOidcOptions) (int, string, error) {
 	if *stsResponse.StatusCode != 200 {
 		idaaslog.Error.PrintfLn("failed assume role with OIDC, status: %v", stsResponse.StatusCode)
 		return int(*stsResponse.StatusCode), "", errors.Errorf(
-			"failed assume role with OIDC, status: %s", *stsResponse.StatusCode)
+			"failed assume role with OIDC, status: %d", *stsResponse.StatusCode)
 	}
 	credentials := stsResponse.Body.Credentials
 	stsToken := &StsToken{
@@ -121,6 +131,7 @@ func fetchContent(options *FetchStsWithOidcOptions) (int, string, error) {
 		AccessKeySecret: *credentials.AccessKeySecret,
 		StsToken:        *credentials.SecurityToken,
 		Expiration:      *credentials.Expiration,
+		ServerHost:      serverHost,
 	}
 	stsTokenJson, err := stsToken.Marshal()
 	if err != nil {
@@ -130,27 +141,28 @@ func fetchContent(options *FetchStsWithOidcOptions) (int, string, error) {
 	return 200, stsTokenJson, nil
 }
 
-func isContentExpiringOrExpired(s *utils.StringWithTime) bool {
+func isContentExpiringOrExpired(serverHost string, s *utils.StringWithTime) bool {
 	stsToken, err := UnmarshalStsToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := stsToken.IsValidAtLeastThreshold(20 * time.Minute)
+	valid := stsToken.IsValidAtLeastThreshold(serverHost, 20*time.Minute)
 	idaaslog.Debug.PrintfLn("Check STS is expiring or expired: %s", !valid)
 	return !valid
 }
 
-func isContentExpired(s *utils.StringWithTime) bool {
+func isContentExpired(serverHost string, s *utils.StringWithTime) bool {
 	stsToken, err := UnmarshalStsToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := stsToken.IsValidAtLeastThreshold(3 * time.Minute)
+	valid := stsToken.IsValidAtLeastThreshold(serverHost, 3*time.Minute)
 	idaaslog.Debug.PrintfLn("Check STS is expired: %s", !valid)
 	return !valid
 }
 
-func assumeRoleWithOidc(client *sts20150401.Client, oidcToken string, options *FetchStsWithOidcOptions) (
+func assumeRoleWithOidc(client *sts20150401.Client, runtime *util.RuntimeOptions, oidcToken string,
+	options *FetchStsWithOidcOptions) (
 	*sts20150401.AssumeRoleWithOIDCResponse, error) {
 
 	var roleSessionName string
@@ -172,8 +184,9 @@ func assumeRoleWithOidc(client *sts20150401.Client, oidcToken string, options *F
 	if options.DurationSeconds > 0 {
 		assumeRoleWithOidcRequest.DurationSeconds = tea.Int64(options.DurationSeconds)
 	}
-	runtime := &util.RuntimeOptions{}
-	runtime.SetAutoretry(true)
+	if options.Policy != "" {
+		assumeRoleWithOidcRequest.Policy = tea.String(options.Policy)
+	}
 	stsResponse, err := client.AssumeRoleWithOIDCWithOptions(assumeRoleWithOidcRequest, runtime)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error assume role with OIDC: %v", err)
@@ -181,10 +194,28 @@ func assumeRoleWithOidc(client *sts20150401.Client, oidcToken string, options *F
 	return stsResponse, err
 }
 
-func createStsClient(endpoint string) (*sts20150401.Client, error) {
-	openapiConfig := &openapi.Config{}
+// createStsClientWithStsToken creates STS client sends requests with ctx, stsToken is required when call AssumeRole
+func createStsClientWithStsToken(ctx context.Context, stsEndpoint *StsEndpoint, stsToken *StsToken) (
+	*sts20150401.Client, error) {
+	openapiConfig := &openapi.Config{
+		HttpClient: &stsHttpClient{ctx: ctx, httpClient: stsEndpoint.buildHttpClient(ctx)},
+	}
+	endpoint := stsEndpoint.Endpoint
 	// Endpoint referer: https://api.aliyun.com/product/Sts
+	// endpoint with scheme, e.g. http://127.0.0.1:8080 for offline testing
+	if scheme, host, found := strings.Cut(endpoint, "://"); found {
+		openapiConfig.Protocol = tea.String(scheme)
+		endpoint = host
+	}
 	openapiConfig.Endpoint = tea.String(endpoint)
+	if stsToken != nil {
+		openapiConfig.AccessKeyId = tea.String(stsToken.AccessKeyId)
+		openapiConfig.AccessKeySecret = tea.String(stsToken.AccessKeySecret)
+		// static AccessKey from credential source has no STS token
+		if stsToken.StsToken != "" {
+			openapiConfig.SecurityToken = tea.String(stsToken.StsToken)
+		}
+	}
 	client, err := sts20150401.NewClient(openapiConfig)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error create alibaba_cloud client: %v", err)
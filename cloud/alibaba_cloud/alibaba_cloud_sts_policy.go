@@ -0,0 +1,53 @@
+package alibaba_cloud
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/pkg/errors"
+)
+
+// ReadPolicy read session policy from inline policy or policy file, returns empty when both absent
+// reference: https://help.aliyun.com/zh/ram/user-guide/policy-structure-and-syntax
+func ReadPolicy(policy, policyFile string) (string, error) {
+	if policy != "" && policyFile != "" {
+		return "", errors.New("Policy and PolicyFile cannot both be set")
+	}
+	if policyFile != "" {
+		policyBytes, err := os.ReadFile(policyFile)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read policy file %s", policyFile)
+		}
+		policy = strings.TrimSpace(string(policyBytes))
+	}
+	if policy != "" && !json.Valid([]byte(policy)) {
+		return "", errors.Errorf("policy is not valid JSON: %s", policy)
+	}
+	return policy, nil
+}
+
+// OverridePolicyFile returns a copy of config with policy file override, the policy file applies to
+// the last hop, so the returned STS token is scoped; digest changes accordingly, scoped STS token never
+// shares cache with unscoped one
+func OverridePolicyFile(alibabaCloudStsConfig *config.AlibabaCloudStsConfig, policyFile string) *config.AlibabaCloudStsConfig {
+	if policyFile == "" {
+		return alibabaCloudStsConfig
+	}
+	overrideConfig := *alibabaCloudStsConfig
+	assumeRoleChainLen := len(overrideConfig.AssumeRoleChain)
+	if assumeRoleChainLen == 0 {
+		overrideConfig.Policy = ""
+		overrideConfig.PolicyFile = policyFile
+		return &overrideConfig
+	}
+	overrideAssumeRoleChain := make([]*config.AlibabaCloudAssumeRoleConfig, assumeRoleChainLen)
+	copy(overrideAssumeRoleChain, overrideConfig.AssumeRoleChain)
+	lastAssumeRoleConfig := *overrideAssumeRoleChain[assumeRoleChainLen-1]
+	lastAssumeRoleConfig.Policy = ""
+	lastAssumeRoleConfig.PolicyFile = policyFile
+	overrideAssumeRoleChain[assumeRoleChainLen-1] = &lastAssumeRoleConfig
+	overrideConfig.AssumeRoleChain = overrideAssumeRoleChain
+	return &overrideConfig
+}
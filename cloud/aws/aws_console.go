@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultSigninEndpoint = "https://signin.aws.amazon.com/federation"
+	DefaultConsoleUrl     = "https://console.aws.amazon.com"
+	CnSigninEndpoint      = "https://signin.amazonaws.cn/federation"
+	CnConsoleUrl          = "https://console.amazonaws.cn"
+	UsGovSigninEndpoint   = "https://signin.amazonaws-us-gov.com/federation"
+	UsGovConsoleUrl       = "https://console.amazonaws-us-gov.com"
+	DefaultConsoleIssuer  = "alibaba-cloud-idaas"
+)
+
+type BuildConsoleSigninUrlOptions struct {
+	Region          string // optional, choose partition endpoints by region
+	SigninEndpoint  string // optional, override signin endpoint
+	Destination     string // optional, full URL or path of console URL
+	Issuer          string // optional, default DefaultConsoleIssuer
+	SessionDuration int32  // optional, 900 ~ 43200 seconds, not allowed for role chaining
+}
+
+type getSigninTokenResponse struct {
+	SigninToken string `json:"SigninToken"`
+}
+
+// BuildConsoleSigninUrl builds federated console sign in URL with STS token
+// reference: https://docs.aws.amazon.com/IAM/latest/UserGuide/id_roles_providers_enable-console-custom-url.html
+func (t *AwsStsToken) BuildConsoleSigninUrl(ctx context.Context, options *BuildConsoleSigninUrlOptions) (string, error) {
+	signinEndpoint, consoleUrl := getConsoleEndpoints(options.Region)
+	if options.SigninEndpoint != "" {
+		signinEndpoint = options.SigninEndpoint
+	}
+	session, err := json.Marshal(map[string]string{
+		"sessionId":    t.AccessKeyId,
+		"sessionKey":   t.SecretAccessKey,
+		"sessionToken": t.SessionToken,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "marshal session failed")
+	}
+	parameters := map[string]string{
+		"Action":  "getSigninToken",
+		"Session": string(session),
+	}
+	if options.SessionDuration > 0 {
+		parameters["SessionDuration"] = fmt.Sprintf("%d", options.SessionDuration)
+	}
+	idaaslog.Debug.PrintfLn("Get signin token, endpoint: %s", signinEndpoint)
+	statusCode, response, err := utils.PostHttp(ctx, signinEndpoint, parameters)
+	if err != nil {
+		return "", errors.Wrap(err, "get signin token failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Get signin token, status: %d, response: %s", statusCode, response)
+	if statusCode != 200 {
+		return "", errors.Errorf("get signin token failed, status: %d, response: %s", statusCode, response)
+	}
+	var signinTokenResponse getSigninTokenResponse
+	err = json.Unmarshal([]byte(response), &signinTokenResponse)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse get signin token response failed, status: %d", statusCode)
+	}
+	if signinTokenResponse.SigninToken == "" {
+		return "", errors.New("get signin token failed, no signin token in response")
+	}
+
+	issuer := options.Issuer
+	if issuer == "" {
+		issuer = DefaultConsoleIssuer
+	}
+	query := url.Values{}
+	query.Set("Action", "login")
+	query.Set("Issuer", issuer)
+	query.Set("Destination", buildDestination(consoleUrl, options.Destination))
+	query.Set("SigninToken", signinTokenResponse.SigninToken)
+	return signinEndpoint + "?" + query.Encode(), nil
+}
+
+func getConsoleEndpoints(region string) (string, string) {
+	if strings.HasPrefix(region, "cn-") {
+		return CnSigninEndpoint, CnConsoleUrl
+	}
+	if strings.HasPrefix(region, "us-gov-") {
+		return UsGovSigninEndpoint, UsGovConsoleUrl
+	}
+	return DefaultSigninEndpoint, DefaultConsoleUrl
+}
+
+func buildDestination(consoleUrl, destination string) string {
+	if destination == "" {
+		return consoleUrl
+	}
+	if strings.HasPrefix(destination, "https://") || strings.HasPrefix(destination, "http://") {
+		return destination
+	}
+	return consoleUrl + "/" + strings.TrimPrefix(destination, "/")
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+)
+
+var _ cloud_common.Credential = (*AwsStsToken)(nil)
+
+// CredentialEnvironments credential environments read by AWS SDKs and CLI, including environments not set by execute,
+// which may override or mix with execute's credentials when inherited
+var CredentialEnvironments = []string{
+	"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_SECURITY_TOKEN",
+	"AWS_PROFILE", "AWS_DEFAULT_PROFILE", "AWS_SHARED_CREDENTIALS_FILE", "AWS_CONFIG_FILE",
+	"AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+	"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+	"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME",
+}
+
+func (t *AwsStsToken) CloudName() string {
+	return "AWS"
+}
+
+func (t *AwsStsToken) GetExpiration() *time.Time {
+	return &t.Expiration
+}
+
+// Environ
+// reference: https://docs.aws.amazon.com/cli/v1/userguide/cli-configure-envvars.html
+func (t *AwsStsToken) Environ(ctx context.Context, options *cloud_common.EnvironOptions) ([]string, error) {
+	var env []string
+	if options.CredentialEndpoint != nil {
+		// reference: https://docs.aws.amazon.com/sdkref/latest/guide/feature-container-credentials.html
+		env = append(env, "AWS_CONTAINER_CREDENTIALS_FULL_URI="+
+			options.CredentialEndpoint.Url+cloud_common.CredentialEndpointPathAws)
+		env = append(env, "AWS_CONTAINER_AUTHORIZATION_TOKEN="+options.CredentialEndpoint.Token)
+	} else {
+		idaaslog.Debug.PrintfLn("Found access key ID: %s", t.AccessKeyId)
+		env = append(env, "AWS_ACCESS_KEY_ID="+t.AccessKeyId)
+
+		env = append(env, "AWS_SECRET_ACCESS_KEY="+t.SecretAccessKey)
+
+		env = append(env, "AWS_SESSION_TOKEN="+t.SessionToken)
+
+		if options.CredentialFiles {
+			credentialFileEnv, err := t.writeCredentialFiles(options)
+			if err != nil {
+				return nil, err
+			}
+			env = append(env, credentialFileEnv...)
+		}
+	}
+
+	if options.Region != "" {
+		idaaslog.Debug.PrintfLn("Set region: %s", options.Region)
+		env = append(env, "AWS_DEFAULT_REGION="+options.Region)
+		env = append(env, "AWS_REGION="+options.Region)
+	}
+	return env, nil
+}
+
+// writeCredentialFiles writes shared credentials file, and shared config file when region is set,
+// for tools which read files only
+func (t *AwsStsToken) writeCredentialFiles(options *cloud_common.EnvironOptions) ([]string, error) {
+	credentialDir, err := options.CredentialDir("aws")
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	sharedCredentialsFile, err := WriteSharedCredentialsFile(credentialDir, t)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found shared credentials file: %s", sharedCredentialsFile)
+	env = append(env, "AWS_SHARED_CREDENTIALS_FILE="+sharedCredentialsFile)
+
+	if options.Region != "" {
+		sharedConfigFile, err := WriteSharedConfigFile(credentialDir, options.Region)
+		if err != nil {
+			return nil, err
+		}
+		idaaslog.Debug.PrintfLn("Found shared config file: %s", sharedConfigFile)
+		env = append(env, "AWS_CONFIG_FILE="+sharedConfigFile)
+	}
+	return env, nil
+}
+
+func (t *AwsStsToken) Output(options *cloud_common.FormatOptions) (*cloud_common.CredentialOutput, error) {
+	content, err := t.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &cloud_common.CredentialOutput{Content: content}, nil
+}
+
+func (t *AwsStsToken) DisplayRows(options *cloud_common.FormatOptions) []*cloud_common.DisplayRow {
+	return []*cloud_common.DisplayRow{
+		cloud_common.NewDisplayRow("Access Key ID", t.AccessKeyId),
+		cloud_common.NewDisplayRow("Secret Access Key", t.SecretAccessKey),
+		cloud_common.NewDisplayRow("Session Token", t.SessionToken),
+		cloud_common.NewExpirationDisplayRow(t.Expiration),
+	}
+}
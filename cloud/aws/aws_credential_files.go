@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SharedCredentialsFileName = "credentials"
+	SharedConfigFileName      = "config"
+)
+
+// WriteSharedCredentialsFile writes shared credentials file with default profile
+// reference: https://docs.aws.amazon.com/sdkref/latest/guide/file-format.html
+func WriteSharedCredentialsFile(dir string, awsStsToken *AwsStsToken) (string, error) {
+	var lines []string
+	lines = append(lines, "[default]")
+	lines = append(lines, "aws_access_key_id = "+awsStsToken.AccessKeyId)
+	lines = append(lines, "aws_secret_access_key = "+awsStsToken.SecretAccessKey)
+	lines = append(lines, "aws_session_token = "+awsStsToken.SessionToken)
+	return writeCredentialFile(dir, SharedCredentialsFileName, lines)
+}
+
+// WriteSharedConfigFile writes shared config file with default profile's region
+func WriteSharedConfigFile(dir, region string) (string, error) {
+	var lines []string
+	lines = append(lines, "[default]")
+	if region != "" {
+		lines = append(lines, "region = "+region)
+	}
+	return writeCredentialFile(dir, SharedConfigFileName, lines)
+}
+
+func writeCredentialFile(dir, filename string, lines []string) (string, error) {
+	credentialFile := filepath.Join(dir, filename)
+	err := os.WriteFile(credentialFile, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write credential file: %s failed", credentialFile)
+	}
+	return credentialFile, nil
+}
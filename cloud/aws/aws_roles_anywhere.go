@@ -0,0 +1,364 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/signer"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	RolesAnywhereService        = "rolesanywhere"
+	RolesAnywhereAlgorithmRsa   = "AWS4-X509-RSA-SHA256"
+	RolesAnywhereAlgorithmEcdsa = "AWS4-X509-ECDSA-SHA256"
+
+	rolesAnywhereAmzDateFormat = "20060102T150405Z"
+	rolesAnywhereDateFormat    = "20060102"
+)
+
+type FetchAwsStsWithRolesAnywhereOptions struct {
+	ForceNew bool
+}
+
+// createSessionResponse
+// reference: https://docs.aws.amazon.com/rolesanywhere/latest/userguide/authentication-create-session.html
+type createSessionResponse struct {
+	CredentialSet []*struct {
+		Credentials *struct {
+			AccessKeyId     string `json:"accessKeyId"`
+			SecretAccessKey string `json:"secretAccessKey"`
+			SessionToken    string `json:"sessionToken"`
+			Expiration      string `json:"expiration"`
+		} `json:"credentials"`
+	} `json:"credentialSet"`
+	SubjectArn string `json:"subjectArn"`
+	Message    string `json:"message"`
+}
+
+// FetchAwsStsWithRolesAnywhere fetch AWS STS token via IAM Roles Anywhere CreateSession, signed by X.509 certificate key
+func FetchAwsStsWithRolesAnywhere(ctx context.Context, profile string, rolesAnywhereConfig *config.AwsRolesAnywhereConfig,
+	options *FetchAwsStsWithRolesAnywhereOptions) (*AwsStsToken, error) {
+	err := checkRolesAnywhereConfig(rolesAnywhereConfig)
+	if err != nil {
+		return nil, err
+	}
+	digest := rolesAnywhereConfig.Digest()
+	serverHost := getRolesAnywhereEndpoint(rolesAnywhereConfig)
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"config":  rolesAnywhereConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchRolesAnywhereContent(ctx, rolesAnywhereConfig)
+		},
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+		ForceNew: options.ForceNew,
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryCloudToken, cacheKey)
+	stsTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with roles anywhere: %v", err)
+		return nil, err
+	}
+	return UnmarshalStsToken(stsTokenStr)
+}
+
+func checkRolesAnywhereConfig(rolesAnywhereConfig *config.AwsRolesAnywhereConfig) error {
+	if rolesAnywhereConfig.Region == "" {
+		return errors.New("Region is required")
+	}
+	if rolesAnywhereConfig.TrustAnchorArn == "" {
+		return errors.New("TrustAnchorArn is required")
+	}
+	if rolesAnywhereConfig.ProfileArn == "" {
+		return errors.New("ProfileArn is required")
+	}
+	if rolesAnywhereConfig.RoleArn == "" {
+		return errors.New("RoleArn is required")
+	}
+	if rolesAnywhereConfig.X509Certificate == nil {
+		return errors.New("X509Certificate is required")
+	}
+	if rolesAnywhereConfig.X509Certificate.CertificateKeySigner == nil {
+		return errors.New("X509Certificate CertificateKeySigner is required")
+	}
+	return nil
+}
+
+func getRolesAnywhereEndpoint(rolesAnywhereConfig *config.AwsRolesAnywhereConfig) string {
+	if rolesAnywhereConfig.Endpoint != "" {
+		return strings.TrimSuffix(rolesAnywhereConfig.Endpoint, "/")
+	}
+	endpoint := fmt.Sprintf("https://rolesanywhere.%s.amazonaws.com", rolesAnywhereConfig.Region)
+	if strings.HasPrefix(rolesAnywhereConfig.Region, "cn-") {
+		endpoint += ".cn"
+	}
+	return endpoint
+}
+
+func fetchRolesAnywhereContent(ctx context.Context, rolesAnywhereConfig *config.AwsRolesAnywhereConfig) (int, string, error) {
+	response, err := createSession(ctx, rolesAnywhereConfig)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error create roles anywhere session: %v", err)
+		return 600, "", err
+	}
+	if len(response.CredentialSet) == 0 || response.CredentialSet[0].Credentials == nil {
+		return 600, "", errors.New("create roles anywhere session failed, no credentials in response")
+	}
+	credentials := response.CredentialSet[0].Credentials
+	expiration, err := time.Parse(time.RFC3339, credentials.Expiration)
+	if err != nil {
+		return 600, "", errors.Wrapf(err, "parse expiration: %s failed", credentials.Expiration)
+	}
+	awsStsToken := &AwsStsToken{
+		Version:         1,
+		AccessKeyId:     credentials.AccessKeyId,
+		SecretAccessKey: credentials.SecretAccessKey,
+		SessionToken:    credentials.SessionToken,
+		Expiration:      expiration,
+	}
+	stsTokenJson, err := awsStsToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling sts aws token: %v", err)
+		return 600, "", err
+	}
+	return 200, stsTokenJson, nil
+}
+
+func createSession(ctx context.Context, rolesAnywhereConfig *config.AwsRolesAnywhereConfig) (*createSessionResponse, error) {
+	x509Certificate := rolesAnywhereConfig.X509Certificate
+	certificate, err := readX509Certificate(x509Certificate.Certificate, x509Certificate.CertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(certificate) == 0 {
+		return nil, errors.New("no certificate provided")
+	}
+	certificateChain, err := readX509Certificate(x509Certificate.CertificateChain, x509Certificate.CertificateChainFile)
+	if err != nil {
+		return nil, err
+	}
+	jwtSigner, err := config.NewExJwtSignerFromConfig(x509Certificate.CertificateKeySigner)
+	if err != nil {
+		return nil, errors.Wrap(err, "new signer failed")
+	}
+	signAlgorithm, err := signer.ParseJwtSignAlgorithm(x509Certificate.CertificateKeySigner.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"trustAnchorArn": rolesAnywhereConfig.TrustAnchorArn,
+		"profileArn":     rolesAnywhereConfig.ProfileArn,
+		"roleArn":        rolesAnywhereConfig.RoleArn,
+	}
+	if rolesAnywhereConfig.DurationSeconds > 0 {
+		body["durationSeconds"] = rolesAnywhereConfig.DurationSeconds
+	}
+	if rolesAnywhereConfig.RoleSessionName != "" {
+		body["roleSessionName"] = rolesAnywhereConfig.RoleSessionName
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal create session body failed")
+	}
+
+	endpoint := getRolesAnywhereEndpoint(rolesAnywhereConfig)
+	endpointUrl, err := url.Parse(endpoint + "/sessions")
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse endpoint: %s failed", endpoint)
+	}
+	req, err := http.NewRequestWithContext(ctx, utils.HttpMethodPost, endpointUrl.String(), bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "new create session request failed")
+	}
+	req.Header.Set("User-Agent", utils.UserAgent)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Host", endpointUrl.Host)
+	req.Header.Set("X-Amz-X509", base64.StdEncoding.EncodeToString(certificate[0].Raw))
+	if len(certificateChain) > 0 {
+		var chain []string
+		for _, c := range certificateChain {
+			chain = append(chain, base64.StdEncoding.EncodeToString(c.Raw))
+		}
+		req.Header.Set("X-Amz-X509-Chain", strings.Join(chain, ","))
+	}
+	err = signRolesAnywhereRequest(req, endpointUrl, bodyBytes, rolesAnywhereConfig.Region,
+		certificate[0], signAlgorithm, jwtSigner.GetExtSinger(), utils.NowFor(endpointUrl.Host).UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	idaaslog.Debug.PrintfLn("Create roles anywhere session, RoleArn: %s, Endpoint: %s",
+		rolesAnywhereConfig.RoleArn, endpointUrl.String())
+	requestTime := time.Now()
+	resp, err := utils.GetHttpClient(ctx).Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "create session failed")
+	}
+	defer resp.Body.Close()
+	utils.ObserveServerDate(resp, requestTime)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read create session response failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Create session, status: %d, response: %s", resp.StatusCode, string(respBody))
+	var response createSessionResponse
+	err = json.Unmarshal(respBody, &response)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse create session response failed, status: %d", resp.StatusCode)
+	}
+	if resp.StatusCode != 201 && resp.StatusCode != 200 {
+		return nil, errors.Errorf("create session failed, status: %d, message: %s", resp.StatusCode, response.Message)
+	}
+	return &response, nil
+}
+
+// signRolesAnywhereRequest signs request with X.509 SigV4, only SHA256 is supported
+// reference: https://docs.aws.amazon.com/rolesanywhere/latest/userguide/authentication-sign-process.html
+func signRolesAnywhereRequest(req *http.Request, endpointUrl *url.URL, body []byte, region string,
+	certificate *x509.Certificate, signAlgorithm signer.JwtSignAlgorithm, exSigner signer.ExSigner, now time.Time) error {
+	var algorithm string
+	switch signAlgorithm {
+	case signer.RS256:
+		algorithm = RolesAnywhereAlgorithmRsa
+	case signer.ES256:
+		algorithm = RolesAnywhereAlgorithmEcdsa
+	default:
+		return errors.Errorf("unsupported algorithm: %s, roles anywhere requires RS256 or ES256", signAlgorithm.ToString())
+	}
+
+	amzDate := now.Format(rolesAnywhereAmzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	canonicalRequest, signedHeaders := buildRolesAnywhereCanonicalRequest(req, endpointUrl, body)
+	idaaslog.Debug.PrintfLn("Roles anywhere canonical request: %s", canonicalRequest)
+
+	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", now.Format(rolesAnywhereDateFormat), region, RolesAnywhereService)
+	stringToSign := buildRolesAnywhereStringToSign(algorithm, amzDate, credentialScope, canonicalRequest)
+
+	signature, err := exSigner.Sign(rand.Reader, signAlgorithm, []byte(stringToSign))
+	if err != nil {
+		return errors.Wrap(err, "sign roles anywhere request failed")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, certificate.SerialNumber.String(), credentialScope, signedHeaders, hex.EncodeToString(signature)))
+	return nil
+}
+
+// buildRolesAnywhereCanonicalRequest headers except User-Agent are signed, returns canonical request and signed headers
+func buildRolesAnywhereCanonicalRequest(req *http.Request, endpointUrl *url.URL, body []byte) (string, string) {
+	var signedHeaderNames []string
+	canonicalHeaders := map[string]string{}
+	for name, values := range req.Header {
+		lowerName := strings.ToLower(name)
+		if lowerName == "user-agent" {
+			continue
+		}
+		signedHeaderNames = append(signedHeaderNames, lowerName)
+		canonicalHeaders[lowerName] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	sort.Strings(signedHeaderNames)
+	var canonicalHeadersBuilder strings.Builder
+	for _, name := range signedHeaderNames {
+		canonicalHeadersBuilder.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+	signedHeaders := strings.Join(signedHeaderNames, ";")
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		endpointUrl.EscapedPath(),
+		endpointUrl.RawQuery,
+		canonicalHeadersBuilder.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	return canonicalRequest, signedHeaders
+}
+
+func buildRolesAnywhereStringToSign(algorithm, amzDate, credentialScope, canonicalRequest string) string {
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	return strings.Join([]string{
+		algorithm,
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+}
+
+// readX509Certificate reads certificates, PEM or base64 DER separated by ","
+func readX509Certificate(certificate, certificateFile string) ([]*x509.Certificate, error) {
+	if certificate == "" && certificateFile != "" {
+		content, err := os.ReadFile(certificateFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read certificate file %s failed", certificateFile)
+		}
+		certificate = string(content)
+	}
+	certificate = strings.TrimSpace(certificate)
+	if certificate == "" {
+		return nil, nil
+	}
+	var certificateDers [][]byte
+	if strings.Contains(certificate, "-----BEGIN") {
+		rest := []byte(certificate)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type == "CERTIFICATE" {
+				certificateDers = append(certificateDers, block.Bytes)
+			}
+		}
+	} else {
+		for _, c := range strings.Split(certificate, ",") {
+			der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(c))
+			if err != nil {
+				return nil, errors.Wrap(err, "decode certificate failed")
+			}
+			certificateDers = append(certificateDers, der)
+		}
+	}
+	var certificates []*x509.Certificate
+	for _, der := range certificateDers {
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse certificate failed")
+		}
+		certificates = append(certificates, c)
+	}
+	return certificates, nil
+}
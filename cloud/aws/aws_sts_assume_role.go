@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/pkg/errors"
+)
+
+type FetchAwsStsWithAssumeRoleOptions struct {
+	Region                   string
+	StsEndpoint              string
+	AssumeRoleConfig         *config.AwsAssumeRoleConfig
+	FetchPreviousAwsStsToken func() (*AwsStsToken, error)
+	ForceNew                 bool
+}
+
+// AwsStsTokenHop AWS STS token of one hop in the assume role chain
+type AwsStsTokenHop struct {
+	RoleArn     string
+	AwsStsToken *AwsStsToken
+}
+
+// FetchAwsStsChainWithOidcConfig fetch AWS STS tokens of all hops, AssumeRoleWithWebIdentity first,
+// then AssumeRole chain in order
+func FetchAwsStsChainWithOidcConfig(ctx context.Context, profile string, awsCloudStsConfig *config.AwsCloudStsConfig,
+	configOptions *FetchAwsStsWithOidcConfigOptions) (
+	[]*AwsStsTokenHop, error) {
+
+	fetchAwsStsTokens, err := buildFetchAwsStsTokenChain(ctx, profile, awsCloudStsConfig, configOptions)
+	if err != nil {
+		return nil, err
+	}
+	var awsStsTokenHops []*AwsStsTokenHop
+	for i, fetchAwsStsToken := range fetchAwsStsTokens {
+		awsStsToken, err := fetchAwsStsToken()
+		if err != nil {
+			return nil, err
+		}
+		roleArn := awsCloudStsConfig.RoleArn
+		if i > 0 {
+			roleArn = awsCloudStsConfig.AssumeRole[i-1].RoleArn
+		}
+		awsStsTokenHops = append(awsStsTokenHops, &AwsStsTokenHop{
+			RoleArn:     roleArn,
+			AwsStsToken: awsStsToken,
+		})
+	}
+	return awsStsTokenHops, nil
+}
+
+// buildFetchAwsStsTokenChain returns fetch functions for every hop, each hop only fetches previous hop when
+// its own cache is expiring or expired
+func buildFetchAwsStsTokenChain(ctx context.Context, profile string, awsCloudStsConfig *config.AwsCloudStsConfig,
+	configOptions *FetchAwsStsWithOidcConfigOptions) (
+	[]func() (*AwsStsToken, error), error) {
+
+	if awsCloudStsConfig.OidcTokenProvider == nil {
+		return nil, errors.New("OidcTokenProvider is required")
+	}
+	for i, assumeRoleConfig := range awsCloudStsConfig.AssumeRole {
+		if assumeRoleConfig == nil || assumeRoleConfig.RoleArn == "" {
+			return nil, errors.Errorf("AssumeRole #%d RoleArn is required", i)
+		}
+	}
+	if err := checkPartition(awsCloudStsConfig); err != nil {
+		return nil, err
+	}
+
+	var fetchAwsStsTokens []func() (*AwsStsToken, error)
+	fetchAwsStsTokens = append(fetchAwsStsTokens, memoizeFetchAwsStsToken(func() (*AwsStsToken, error) {
+		return fetchAwsStsWithOidcConfig(ctx, profile, awsCloudStsConfig, configOptions)
+	}))
+	for i, assumeRoleConfig := range awsCloudStsConfig.AssumeRole {
+		hop := i + 1
+		options := &FetchAwsStsWithAssumeRoleOptions{
+			Region:                   awsCloudStsConfig.Region,
+			StsEndpoint:              awsCloudStsConfig.StsEndpoint,
+			AssumeRoleConfig:         assumeRoleConfig,
+			FetchPreviousAwsStsToken: fetchAwsStsTokens[i],
+			ForceNew:                 configOptions.ForceNew,
+		}
+		fetchAwsStsTokens = append(fetchAwsStsTokens, memoizeFetchAwsStsToken(func() (*AwsStsToken, error) {
+			return FetchStsWithAssumeRole(ctx, profile, hop, awsCloudStsConfig, options)
+		}))
+	}
+	return fetchAwsStsTokens, nil
+}
+
+// FetchStsWithAssumeRole fetch AWS STS token via AssumeRole with previous hop's STS token, hop starts from 1
+func FetchStsWithAssumeRole(ctx context.Context, profile string, hop int, awsCloudStsConfig *config.AwsCloudStsConfig,
+	options *FetchAwsStsWithAssumeRoleOptions) (*AwsStsToken, error) {
+	digest := awsCloudStsConfig.AssumeRoleChainDigest(hop)
+	serverHost := getStsHost(options.Region, options.StsEndpoint)
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"hop":     hop,
+			"config":  options.AssumeRoleConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchAssumeRoleContent(ctx, options)
+		},
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+		ForceNew: options.ForceNew,
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s, hop: %d", constants.CategoryCloudToken, cacheKey, hop)
+	stsTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with assume role, hop: %d: %v", hop, err)
+		return nil, err
+	}
+	return UnmarshalStsToken(stsTokenStr)
+}
+
+func fetchAssumeRoleContent(ctx context.Context, options *FetchAwsStsWithAssumeRoleOptions) (int, string, error) {
+	previousAwsStsToken, err := options.FetchPreviousAwsStsToken()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetching previous aws sts token: %v", err)
+		return 600, "", err
+	}
+	client, err := createAwsStsClient(ctx, options.Region, options.StsEndpoint, previousAwsStsToken)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error creating aws sts client: %v", err)
+		return 600, "", err
+	}
+	stsResponse, err := assumeRole(ctx, client, getStsHost(options.Region, options.StsEndpoint), options.AssumeRoleConfig)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error assuming role: %v", err)
+		return 600, "", err
+	}
+	credentials := stsResponse.Credentials
+	awsStsToken := &AwsStsToken{
+		Version:         1,
+		AccessKeyId:     *credentials.AccessKeyId,
+		SecretAccessKey: *credentials.SecretAccessKey,
+		SessionToken:    *credentials.SessionToken,
+		Expiration:      *credentials.Expiration,
+	}
+	stsTokenJson, err := awsStsToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling sts aws token: %v", err)
+		return 600, "", err
+	}
+	return 200, stsTokenJson, nil
+}
+
+func assumeRole(ctx context.Context, client *sts.Client, stsHost string, assumeRoleConfig *config.AwsAssumeRoleConfig) (
+	*sts.AssumeRoleOutput, error) {
+	var roleSessionName string
+	if assumeRoleConfig.RoleSessionName != "" {
+		roleSessionName = assumeRoleConfig.RoleSessionName
+	} else {
+		roleSessionName = cloud_common.GenerateRoleSessionName("")
+		idaaslog.Info.PrintfLn(
+			"Assume role session name not specified, use role session name %s", roleSessionName)
+	}
+	idaaslog.Debug.PrintfLn("Assume role, RoleArn: %s, RoleSessionName: %s",
+		assumeRoleConfig.RoleArn, roleSessionName)
+	assumeRoleInput := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(assumeRoleConfig.RoleArn),
+		RoleSessionName: aws.String(roleSessionName),
+	}
+	if assumeRoleConfig.DurationSeconds > 0 {
+		assumeRoleInput.DurationSeconds = aws.Int32(assumeRoleConfig.DurationSeconds)
+	}
+	if assumeRoleConfig.ExternalId != "" {
+		assumeRoleInput.ExternalId = aws.String(assumeRoleConfig.ExternalId)
+	}
+	if assumeRoleConfig.SourceIdentity != "" {
+		assumeRoleInput.SourceIdentity = aws.String(assumeRoleConfig.SourceIdentity)
+	}
+	if assumeRoleConfig.Policy != "" {
+		assumeRoleInput.Policy = aws.String(assumeRoleConfig.Policy)
+	}
+	for _, policyArn := range assumeRoleConfig.PolicyArns {
+		assumeRoleInput.PolicyArns = append(assumeRoleInput.PolicyArns, types.PolicyDescriptorType{
+			Arn: aws.String(policyArn),
+		})
+	}
+	for key, value := range assumeRoleConfig.Tags {
+		assumeRoleInput.Tags = append(assumeRoleInput.Tags, types.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(value),
+		})
+	}
+	assumeRoleInput.TransitiveTagKeys = assumeRoleConfig.TransitiveTagKeys
+	if assumeRoleConfig.MfaSerialNumber != "" {
+		tokenCode, err := cloud_common.PromptMfaTokenCode(assumeRoleConfig.MfaSerialNumber)
+		if err != nil {
+			return nil, err
+		}
+		assumeRoleInput.SerialNumber = aws.String(assumeRoleConfig.MfaSerialNumber)
+		assumeRoleInput.TokenCode = aws.String(tokenCode)
+	}
+	idaaslog.Unsafe.PrintfLn("Assume role input: %+v", assumeRoleInput)
+	requestTime := time.Now()
+	stsResponse, err := client.AssumeRole(ctx, assumeRoleInput)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error assume role: %v", err)
+	} else if serverTime, ok := awsmiddleware.GetServerTime(stsResponse.ResultMetadata); ok {
+		utils.ObserveServerTime(serverTime, requestTime, time.Now(), stsHost)
+	}
+	return stsResponse, err
+}
+
+// memoizeFetchAwsStsToken fetch at most once, hops share the same previous hop
+func memoizeFetchAwsStsToken(fetchAwsStsToken func() (*AwsStsToken, error)) func() (*AwsStsToken, error) {
+	var once sync.Once
+	var awsStsToken *AwsStsToken
+	var err error
+	return func() (*AwsStsToken, error) {
+		once.Do(func() {
+			awsStsToken, err = fetchAwsStsToken()
+		})
+		return awsStsToken, err
+	}
+}
@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/pkg/errors"
+)
+
+const DefaultRegion = "us-east-1"
+
+// GetCallerIdentity verify STS token and get caller identity, region and stsEndpoint are optional
+// reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_GetCallerIdentity.html
+func GetCallerIdentity(ctx context.Context, awsStsToken *AwsStsToken, region, stsEndpoint string) (*cloud_common.CallerIdentity, error) {
+	if region == "" {
+		region = DefaultRegion
+	}
+	client, err := createAwsStsClient(ctx, region, stsEndpoint, awsStsToken)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Get caller identity, region: %s, endpoint: %s", region, stsEndpoint)
+	requestTime := time.Now()
+	output, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error get caller identity: %v", err)
+		return nil, errors.Wrap(err, "get caller identity failed")
+	}
+	if serverTime, ok := awsmiddleware.GetServerTime(output.ResultMetadata); ok {
+		utils.ObserveServerTime(serverTime, requestTime, time.Now(), getStsHost(region, stsEndpoint))
+	}
+	arn := aws.ToString(output.Arn)
+	return &cloud_common.CallerIdentity{
+		Account:     aws.ToString(output.Account),
+		Arn:         arn,
+		PrincipalId: aws.ToString(output.UserId),
+		SessionName: cloud_common.ParseSessionNameFromArn(arn),
+	}, nil
+}
@@ -3,6 +3,7 @@ package aws
 import (
 	"encoding/json"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
 	"time"
 )
@@ -15,6 +16,24 @@ type AwsStsToken struct {
 	Expiration      time.Time `json:"Expiration"`
 }
 
+// AwsContainerCredentials response of AWS_CONTAINER_CREDENTIALS_FULL_URI
+// reference: https://docs.aws.amazon.com/sdkref/latest/guide/feature-container-credentials.html
+type AwsContainerCredentials struct {
+	AccessKeyId     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func (t *AwsStsToken) ConvertToContainerCredentials() *AwsContainerCredentials {
+	return &AwsContainerCredentials{
+		AccessKeyId:     t.AccessKeyId,
+		SecretAccessKey: t.SecretAccessKey,
+		Token:           t.SessionToken,
+		Expiration:      t.Expiration,
+	}
+}
+
 func (t *AwsStsToken) Marshal() (string, error) {
 	if t == nil {
 		return "null", nil
@@ -35,10 +54,10 @@ func UnmarshalStsToken(token string) (*AwsStsToken, error) {
 	return &awsStsToken, nil
 }
 
-func (t *AwsStsToken) IsValidAtLeastThreshold(thresholdDuration time.Duration) bool {
+func (t *AwsStsToken) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
 	idaaslog.Debug.PrintfLn("Check is valid, expiration: %s, threshold: %d ms",
 		t.Expiration, thresholdDuration.Milliseconds())
-	valid := time.Until(t.Expiration) > thresholdDuration
+	valid := utils.UntilFor(serverHost, t.Expiration) > thresholdDuration
 	idaaslog.Info.PrintfLn("Check is valid: %s", valid)
 	return valid
 }
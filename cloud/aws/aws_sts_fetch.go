@@ -10,18 +10,29 @@ import (
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/pkg/errors"
+	"net/http"
+	"strings"
 	"time"
 )
 
+const (
+	PartitionAws      = "aws"
+	PartitionAwsCn    = "aws-cn"
+	PartitionAwsUsGov = "aws-us-gov"
+)
+
 type FetchAwsStsWithOidcConfigOptions struct {
 	ForceNew bool
 }
 
 type FetchAwsStsWithOidcOptions struct {
 	Region          string
+	StsEndpoint     string
 	RoleArn         string
 	DurationSeconds int32
 	RoleSessionName string
@@ -29,15 +40,25 @@ type FetchAwsStsWithOidcOptions struct {
 	ForceNew        bool
 }
 
-func FetchAwsStsWithOidcConfig(profile string, awsCloudStsConfig *config.AwsCloudStsConfig,
+func FetchAwsStsWithOidcConfig(ctx context.Context, profile string, awsCloudStsConfig *config.AwsCloudStsConfig,
 	configOptions *FetchAwsStsWithOidcConfigOptions) (
 	*AwsStsToken, error) {
 
-	if awsCloudStsConfig.OidcTokenProvider == nil {
-		return nil, errors.New("OidcTokenProvider is required")
+	fetchAwsStsTokens, err := buildFetchAwsStsTokenChain(ctx, profile, awsCloudStsConfig, configOptions)
+	if err != nil {
+		return nil, err
 	}
+	// only the last hop is required, previous hops are fetched on demand
+	return fetchAwsStsTokens[len(fetchAwsStsTokens)-1]()
+}
+
+func fetchAwsStsWithOidcConfig(ctx context.Context, profile string, awsCloudStsConfig *config.AwsCloudStsConfig,
+	configOptions *FetchAwsStsWithOidcConfigOptions) (
+	*AwsStsToken, error) {
+
 	options := &FetchAwsStsWithOidcOptions{
 		Region:          awsCloudStsConfig.Region,
+		StsEndpoint:     awsCloudStsConfig.StsEndpoint,
 		RoleArn:         awsCloudStsConfig.RoleArn,
 		RoleSessionName: awsCloudStsConfig.RoleSessionName,
 		DurationSeconds: awsCloudStsConfig.DurationSeconds,
@@ -45,15 +66,16 @@ func FetchAwsStsWithOidcConfig(profile string, awsCloudStsConfig *config.AwsClou
 			fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
 				ForceNew: configOptions.ForceNew,
 			}
-			return idp.FetchOidcToken(profile, awsCloudStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
+			return idp.FetchOidcToken(ctx, profile, awsCloudStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
 		},
 		ForceNew: configOptions.ForceNew,
 	}
-	return FetchStsWithOidc(profile, awsCloudStsConfig, options)
+	return FetchStsWithOidc(ctx, profile, awsCloudStsConfig, options)
 }
 
-func FetchStsWithOidc(profile string, awsCloudStsConfig *config.AwsCloudStsConfig, options *FetchAwsStsWithOidcOptions) (*AwsStsToken, error) {
-	digest := awsCloudStsConfig.Digest()
+func FetchStsWithOidc(ctx context.Context, profile string, awsCloudStsConfig *config.AwsCloudStsConfig, options *FetchAwsStsWithOidcOptions) (*AwsStsToken, error) {
+	digest := awsCloudStsConfig.AssumeRoleChainDigest(0)
+	serverHost := getStsHost(options.Region, options.StsEndpoint)
 	readCacheFileOptions := &utils.ReadCacheOptions{
 		Context: map[string]interface{}{
 			"profile": profile,
@@ -61,13 +83,13 @@ func FetchStsWithOidc(profile string, awsCloudStsConfig *config.AwsCloudStsConfi
 			"config":  awsCloudStsConfig,
 		},
 		FetchContent: func() (int, string, error) {
-			return fetchContent(options)
+			return fetchContent(ctx, options)
 		},
 		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpiringOrExpired(s)
+			return isContentExpiringOrExpired(serverHost, s)
 		},
 		IsContentExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpired(s)
+			return isContentExpired(serverHost, s)
 		},
 		ForceNew: options.ForceNew,
 	}
@@ -83,8 +105,8 @@ func FetchStsWithOidc(profile string, awsCloudStsConfig *config.AwsCloudStsConfi
 	return UnmarshalStsToken(stsTokenStr)
 }
 
-func fetchContent(options *FetchAwsStsWithOidcOptions) (int, string, error) {
-	client, err := createAwsStsClient(options.Region)
+func fetchContent(ctx context.Context, options *FetchAwsStsWithOidcOptions) (int, string, error) {
+	client, err := createAwsStsClient(ctx, options.Region, options.StsEndpoint, nil)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error creating aws sts client: %v", err)
 		return 600, "", err
@@ -94,7 +116,7 @@ func fetchContent(options *FetchAwsStsWithOidcOptions) (int, string, error) {
 		idaaslog.Error.PrintfLn("Error fetching oidc token: %v", err)
 		return 600, "", err
 	}
-	stsResponse, err := assumeRoleWithWebIdentity(client, oidcToken, options)
+	stsResponse, err := assumeRoleWithWebIdentity(ctx, client, oidcToken, options)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error assuming role: %v", err)
 		return 600, "", err
@@ -115,27 +137,27 @@ func fetchContent(options *FetchAwsStsWithOidcOptions) (int, string, error) {
 	return 200, stsTokenJson, nil
 }
 
-func isContentExpiringOrExpired(s *utils.StringWithTime) bool {
+func isContentExpiringOrExpired(serverHost string, s *utils.StringWithTime) bool {
 	stsToken, err := UnmarshalStsToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := stsToken.IsValidAtLeastThreshold(20 * time.Minute)
+	valid := stsToken.IsValidAtLeastThreshold(serverHost, 20*time.Minute)
 	idaaslog.Debug.PrintfLn("Check AWS STS is expiring or expired: %s", !valid)
 	return !valid
 }
 
-func isContentExpired(s *utils.StringWithTime) bool {
+func isContentExpired(serverHost string, s *utils.StringWithTime) bool {
 	stsToken, err := UnmarshalStsToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := stsToken.IsValidAtLeastThreshold(3 * time.Minute)
+	valid := stsToken.IsValidAtLeastThreshold(serverHost, 3*time.Minute)
 	idaaslog.Debug.PrintfLn("Check AWS STS is expired: %s", !valid)
 	return !valid
 }
 
-func assumeRoleWithWebIdentity(client *sts.Client, oidcToken string, options *FetchAwsStsWithOidcOptions) (
+func assumeRoleWithWebIdentity(ctx context.Context, client *sts.Client, oidcToken string, options *FetchAwsStsWithOidcOptions) (
 	*sts.AssumeRoleWithWebIdentityOutput, error) {
 
 	var roleSessionName string
@@ -158,23 +180,114 @@ func assumeRoleWithWebIdentity(client *sts.Client, oidcToken string, options *Fe
 	}
 	idaaslog.Unsafe.PrintfLn("Assume role with web identity input: %+v, OIDC Token: %s",
 		assumeRoleWithWebIdentityInput, oidcToken)
-	stsResponse, err := client.AssumeRoleWithWebIdentity(context.TODO(), assumeRoleWithWebIdentityInput)
+	requestTime := time.Now()
+	stsResponse, err := client.AssumeRoleWithWebIdentity(ctx, assumeRoleWithWebIdentityInput)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Error assume role with OIDC: %v", err)
+	} else if serverTime, ok := awsmiddleware.GetServerTime(stsResponse.ResultMetadata); ok {
+		utils.ObserveServerTime(serverTime, requestTime, time.Now(), getStsHost(options.Region, options.StsEndpoint))
 	}
 	return stsResponse, err
 }
 
-func createAwsStsClient(region string) (*sts.Client, error) {
+// createAwsStsClient creates STS client, awsStsToken is required when call AssumeRole,
+// HTTP client is injected from ctx when present
+func createAwsStsClient(ctx context.Context, region, stsEndpoint string, awsStsToken *AwsStsToken) (*sts.Client, error) {
 	if region == "" {
 		return nil, errors.New("no region specified")
 	}
 	cfg := aws.Config{
-		Region: region,
+		Region:     region,
+		HTTPClient: utils.GetHttpClient(ctx),
 		Retryer: func() aws.Retryer {
 			return retry.AddWithMaxAttempts(retry.NewStandard(), 3)
 		},
 	}
-	client := sts.NewFromConfig(cfg)
+	if stsEndpoint != "" {
+		idaaslog.Debug.PrintfLn("Use AWS STS endpoint: %s", stsEndpoint)
+		cfg.BaseEndpoint = aws.String(stsEndpoint)
+	}
+	stsHost := getStsHost(region, stsEndpoint)
+	if awsStsToken != nil {
+		cfg.Credentials = aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{
+				AccessKeyID:     awsStsToken.AccessKeyId,
+				SecretAccessKey: awsStsToken.SecretAccessKey,
+				SessionToken:    awsStsToken.SessionToken,
+				Source:          "AlibabaCloudIDaaS",
+				CanExpire:       true,
+				Expires:         awsStsToken.Expiration,
+			}, nil
+		})
+	}
+	client := sts.NewFromConfig(cfg, func(o *sts.Options) {
+		o.HTTPSignerV4 = &skewedHttpSignerV4{
+			signer:     v4.NewSigner(),
+			serverHost: stsHost,
+		}
+	})
 	return client, nil
 }
+
+// getStsHost returns host of STS endpoint, regional endpoint when not specified, used to estimate clock skew
+func getStsHost(region, stsEndpoint string) string {
+	if stsEndpoint != "" {
+		return stsEndpoint
+	}
+	if strings.HasPrefix(region, "cn-") {
+		return fmt.Sprintf("sts.%s.amazonaws.com.cn", region)
+	}
+	return fmt.Sprintf("sts.%s.amazonaws.com", region)
+}
+
+// skewedHttpSignerV4 signs request with local time compensated with clock skew of STS,
+// AWS rejects request when signing time differs from server time more than 15 minutes
+type skewedHttpSignerV4 struct {
+	signer     *v4.Signer
+	serverHost string
+}
+
+func (s *skewedHttpSignerV4) SignHTTP(ctx context.Context, credentials aws.Credentials, r *http.Request,
+	payloadHash string, service string, region string, signingTime time.Time,
+	optFns ...func(*v4.SignerOptions)) error {
+	signingTime = signingTime.Add(utils.GetClockSkew(s.serverHost))
+	return s.signer.SignHTTP(ctx, credentials, r, payloadHash, service, region, signingTime, optFns...)
+}
+
+// getRegionPartition returns partition of region, e.g. cn-north-1 is in aws-cn, us-gov-west-1 is in aws-us-gov
+func getRegionPartition(region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return PartitionAwsCn
+	}
+	if strings.HasPrefix(region, "us-gov-") {
+		return PartitionAwsUsGov
+	}
+	return PartitionAws
+}
+
+// checkPartition
+// reference: https://docs.aws.amazon.com/IAM/latest/UserGuide/reference-arns.html
+func checkPartition(awsCloudStsConfig *config.AwsCloudStsConfig) error {
+	partition := awsCloudStsConfig.Partition
+	if partition == "" {
+		return nil
+	}
+	switch partition {
+	case PartitionAws, PartitionAwsCn, PartitionAwsUsGov:
+	default:
+		return errors.Errorf("unknown partition: %s, must be aws, aws-cn or aws-us-gov", partition)
+	}
+	if getRegionPartition(awsCloudStsConfig.Region) != partition {
+		return errors.Errorf("region %s is not in partition %s", awsCloudStsConfig.Region, partition)
+	}
+	roleArns := []string{awsCloudStsConfig.RoleArn}
+	for _, assumeRoleConfig := range awsCloudStsConfig.AssumeRole {
+		roleArns = append(roleArns, assumeRoleConfig.RoleArn)
+	}
+	for _, roleArn := range roleArns {
+		if !strings.HasPrefix(roleArn, "arn:"+partition+":") {
+			return errors.Errorf("role arn %s is not in partition %s", roleArn, partition)
+		}
+	}
+	return nil
+}
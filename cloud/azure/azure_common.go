@@ -0,0 +1,45 @@
+package azure
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+type AzureToken struct {
+	Version     int       `json:"Version"`
+	AccessToken string    `json:"AccessToken"`
+	TokenType   string    `json:"TokenType"`
+	Expiration  time.Time `json:"Expiration"`
+}
+
+func (t *AzureToken) Marshal() (string, error) {
+	if t == nil {
+		return "null", nil
+	}
+	tokenBytes, err := json.Marshal(t)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal azure token failed")
+	}
+	return string(tokenBytes), nil
+}
+
+func UnmarshalAzureToken(token string) (*AzureToken, error) {
+	var azureToken AzureToken
+	err := json.Unmarshal([]byte(token), &azureToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal azure token: %s failed", token)
+	}
+	return &azureToken, nil
+}
+
+func (t *AzureToken) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
+	idaaslog.Debug.PrintfLn("Check is valid, expiration: %s, threshold: %d ms",
+		t.Expiration, thresholdDuration.Milliseconds())
+	valid := utils.UntilFor(serverHost, t.Expiration) > thresholdDuration
+	idaaslog.Info.PrintfLn("Check is valid: %s", valid)
+	return valid
+}
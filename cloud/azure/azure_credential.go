@@ -0,0 +1,94 @@
+package azure
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
+	"github.com/pkg/errors"
+)
+
+var _ cloud_common.Credential = (*AzureToken)(nil)
+
+// CredentialEnvironments credential environments read by Azure SDKs, az and Terraform,
+// including environments not set by execute
+var CredentialEnvironments = []string{
+	"AZURE_CLIENT_ID", "AZURE_TENANT_ID", "AZURE_FEDERATED_TOKEN_FILE", "AZURE_CLIENT_SECRET",
+	"AZURE_CLIENT_CERTIFICATE_PATH", "AZURE_CLIENT_CERTIFICATE_PASSWORD", "AZURE_USERNAME", "AZURE_PASSWORD",
+	"ARM_CLIENT_ID", "ARM_TENANT_ID", "ARM_CLIENT_SECRET", "ARM_OIDC_TOKEN", "ARM_OIDC_TOKEN_FILE_PATH",
+	"ARM_CLIENT_CERTIFICATE_PATH", "ARM_CLIENT_CERTIFICATE_PASSWORD",
+}
+
+func (t *AzureToken) CloudName() string {
+	return "Azure"
+}
+
+func (t *AzureToken) GetExpiration() *time.Time {
+	return &t.Expiration
+}
+
+// Environ federated token file for Azure SDKs and az, OIDC for Terraform azurerm
+// reference: https://learn.microsoft.com/en-us/azure/developer/go/sdk/authentication/credential-chains#environmentcredential-overview
+// reference: https://registry.terraform.io/providers/hashicorp/azurerm/latest/docs/guides/service_principal_oidc
+func (t *AzureToken) Environ(ctx context.Context, options *cloud_common.EnvironOptions) ([]string, error) {
+	if options.CredentialEndpoint != nil {
+		return nil, errors.New("Azure credential endpoint is not supported")
+	}
+	if options.CloudStsConfig == nil || options.CloudStsConfig.AzureAd == nil {
+		return nil, errors.New("Azure AD config is required")
+	}
+	azureAd := options.CloudStsConfig.AzureAd
+	var env []string
+
+	// OIDC token is cached, the same token is used as client assertion by Azure SDKs
+	fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
+		ForceNew: options.ForceNew,
+	}
+	oidcToken, err := idp.FetchOidcToken(ctx, options.Profile, azureAd.OidcTokenProvider, fetchOidcTokenOptions)
+	if err != nil {
+		return nil, err
+	}
+	credentialDir, err := options.CredentialDir("azure")
+	if err != nil {
+		return nil, err
+	}
+	federatedTokenFile, err := WriteFederatedTokenFile(credentialDir, oidcToken)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found federated token file: %s", federatedTokenFile)
+	env = append(env, "AZURE_FEDERATED_TOKEN_FILE="+federatedTokenFile)
+	env = append(env, "AZURE_CLIENT_ID="+azureAd.ClientId)
+	env = append(env, "AZURE_TENANT_ID="+azureAd.TenantId)
+	env = append(env, "AZURE_AUTHORITY_HOST="+GetAuthorityHost(azureAd))
+
+	env = append(env, "ARM_USE_OIDC=true")
+	env = append(env, "ARM_OIDC_TOKEN_FILE_PATH="+federatedTokenFile)
+	env = append(env, "ARM_CLIENT_ID="+azureAd.ClientId)
+	env = append(env, "ARM_TENANT_ID="+azureAd.TenantId)
+
+	idaaslog.Debug.PrintfLn("Azure access token expiration: %s", t.Expiration)
+	if options.Region != "" {
+		idaaslog.Debug.PrintfLn("Set region: %s", options.Region)
+		env = append(env, "AZURE_DEFAULTS_LOCATION="+options.Region)
+	}
+	return env, nil
+}
+
+func (t *AzureToken) Output(options *cloud_common.FormatOptions) (*cloud_common.CredentialOutput, error) {
+	content, err := t.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &cloud_common.CredentialOutput{Content: content}, nil
+}
+
+func (t *AzureToken) DisplayRows(options *cloud_common.FormatOptions) []*cloud_common.DisplayRow {
+	return []*cloud_common.DisplayRow{
+		cloud_common.NewDisplayRow("Access Token Type", t.TokenType),
+		cloud_common.NewDisplayRow("Access Token", t.AccessToken),
+		cloud_common.NewExpirationDisplayRow(t.Expiration),
+	}
+}
@@ -0,0 +1,20 @@
+package azure
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const FederatedTokenFileName = "azure_federated_token"
+
+// WriteFederatedTokenFile writes OIDC token for `AZURE_FEDERATED_TOKEN_FILE`, returns the file path
+func WriteFederatedTokenFile(dir, oidcToken string) (string, error) {
+	federatedTokenFile := filepath.Join(dir, FederatedTokenFileName)
+	err := os.WriteFile(federatedTokenFile, []byte(oidcToken), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write federated token file: %s failed", federatedTokenFile)
+	}
+	return federatedTokenFile, nil
+}
@@ -0,0 +1,204 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultAuthorityHost = "https://login.microsoftonline.com"
+	DefaultScope         = "https://management.azure.com/.default"
+
+	GrantTypeClientCredentials = "client_credentials"
+	ClientAssertionTypeJwt     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+type FetchAzureTokenWithOidcConfigOptions struct {
+	ForceNew bool
+}
+
+type FetchAzureTokenWithOidcOptions struct {
+	TokenEndpoint  string
+	ClientId       string
+	Scope          string
+	FetchOidcToken func() (string, error)
+	ForceNew       bool
+}
+
+// tokenResponse
+// reference: https://learn.microsoft.com/en-us/entra/identity-platform/v2-oauth2-client-creds-grant-flow#successful-response-1
+type tokenResponse struct {
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func FetchAzureTokenWithOidcConfig(ctx context.Context, profile string, azureAdConfig *config.AzureAdConfig,
+	configOptions *FetchAzureTokenWithOidcConfigOptions) (
+	*AzureToken, error) {
+	if azureAdConfig.TenantId == "" {
+		return nil, errors.New("TenantId is required")
+	}
+	if azureAdConfig.ClientId == "" {
+		return nil, errors.New("ClientId is required")
+	}
+	if azureAdConfig.OidcTokenProvider == nil {
+		return nil, errors.New("OidcTokenProvider is required")
+	}
+
+	options := &FetchAzureTokenWithOidcOptions{
+		TokenEndpoint: GetTokenEndpoint(azureAdConfig),
+		ClientId:      azureAdConfig.ClientId,
+		Scope:         getScope(azureAdConfig),
+		FetchOidcToken: func() (string, error) {
+			fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
+				ForceNew: configOptions.ForceNew,
+			}
+			return idp.FetchOidcToken(ctx, profile, azureAdConfig.OidcTokenProvider, fetchOidcTokenOptions)
+		},
+		ForceNew: configOptions.ForceNew,
+	}
+	return FetchAzureTokenWithOidc(ctx, profile, azureAdConfig, options)
+}
+
+func FetchAzureTokenWithOidc(ctx context.Context, profile string, azureAdConfig *config.AzureAdConfig, options *FetchAzureTokenWithOidcOptions) (*AzureToken, error) {
+	digest := azureAdConfig.Digest()
+	serverHost := options.TokenEndpoint
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"config":  azureAdConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchContent(ctx, options)
+		},
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+		ForceNew: options.ForceNew,
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryCloudToken, cacheKey)
+	azureTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with OIDC: %v", err)
+		return nil, err
+	}
+	return UnmarshalAzureToken(azureTokenStr)
+}
+
+// GetAuthorityHost returns Entra ID authority host, also exported as `AZURE_AUTHORITY_HOST`
+func GetAuthorityHost(azureAdConfig *config.AzureAdConfig) string {
+	if azureAdConfig.AuthorityHost != "" {
+		return strings.TrimSuffix(azureAdConfig.AuthorityHost, "/")
+	}
+	return DefaultAuthorityHost
+}
+
+// GetTokenEndpoint
+// reference: https://learn.microsoft.com/en-us/entra/identity-platform/v2-oauth2-client-creds-grant-flow#third-case-access-token-request-with-a-federated-credential
+func GetTokenEndpoint(azureAdConfig *config.AzureAdConfig) string {
+	return fmt.Sprintf("%s/%s/oauth2/v2.0/token", GetAuthorityHost(azureAdConfig), azureAdConfig.TenantId)
+}
+
+func getScope(azureAdConfig *config.AzureAdConfig) string {
+	if azureAdConfig.Scope != "" {
+		return azureAdConfig.Scope
+	}
+	return DefaultScope
+}
+
+func fetchContent(ctx context.Context, options *FetchAzureTokenWithOidcOptions) (int, string, error) {
+	oidcToken, err := options.FetchOidcToken()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetching oidc token: %v", err)
+		return 600, "", err
+	}
+	startTime := utils.NowFor(options.TokenEndpoint)
+	response, err := requestToken(ctx, oidcToken, options)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error requesting azure token: %v", err)
+		return 600, "", err
+	}
+	azureToken := &AzureToken{
+		Version:     1,
+		AccessToken: response.AccessToken,
+		TokenType:   response.TokenType,
+		Expiration:  startTime.Add(time.Duration(response.ExpiresIn) * time.Second),
+	}
+	azureTokenJson, err := azureToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling azure token: %v", err)
+		return 600, "", err
+	}
+	return 200, azureTokenJson, nil
+}
+
+// requestToken requests access token with OIDC token as client assertion
+func requestToken(ctx context.Context, oidcToken string, options *FetchAzureTokenWithOidcOptions) (*tokenResponse, error) {
+	parameters := map[string]string{
+		"grant_type":            GrantTypeClientCredentials,
+		"client_id":             options.ClientId,
+		"scope":                 options.Scope,
+		"client_assertion_type": ClientAssertionTypeJwt,
+		"client_assertion":      oidcToken,
+	}
+	idaaslog.Debug.PrintfLn("Request azure token, ClientId: %s, Endpoint: %s", options.ClientId, options.TokenEndpoint)
+	idaaslog.Unsafe.PrintfLn("Request azure token, OIDC Token: %s", oidcToken)
+	statusCode, response, err := utils.PostHttp(ctx, options.TokenEndpoint, parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "request azure token failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Request azure token, status: %d, response: %s", statusCode, response)
+	var azureTokenResponse tokenResponse
+	err = json.Unmarshal([]byte(response), &azureTokenResponse)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse azure token response failed, status: %d", statusCode)
+	}
+	if statusCode != 200 || azureTokenResponse.Error != "" {
+		return nil, errors.Errorf("request azure token failed, status: %d, error: %s, description: %s",
+			statusCode, azureTokenResponse.Error, azureTokenResponse.ErrorDescription)
+	}
+	if azureTokenResponse.AccessToken == "" {
+		return nil, errors.Errorf("request azure token failed, no access token in response, status: %d", statusCode)
+	}
+	return &azureTokenResponse, nil
+}
+
+func isContentExpiringOrExpired(serverHost string, s *utils.StringWithTime) bool {
+	azureToken, err := UnmarshalAzureToken(s.Content)
+	if err != nil {
+		return true
+	}
+	valid := azureToken.IsValidAtLeastThreshold(serverHost, 20*time.Minute)
+	idaaslog.Debug.PrintfLn("Check Azure token is expiring or expired: %s", !valid)
+	return !valid
+}
+
+func isContentExpired(serverHost string, s *utils.StringWithTime) bool {
+	azureToken, err := UnmarshalAzureToken(s.Content)
+	if err != nil {
+		return true
+	}
+	valid := azureToken.IsValidAtLeastThreshold(serverHost, 3*time.Minute)
+	idaaslog.Debug.PrintfLn("Check Azure token is expired: %s", !valid)
+	return !valid
+}
@@ -0,0 +1,28 @@
+package cloud_common
+
+import (
+	"strings"
+)
+
+// CallerIdentity identity of credentials, from Alibaba Cloud or AWS GetCallerIdentity
+type CallerIdentity struct {
+	Account      string
+	Arn          string
+	PrincipalId  string
+	IdentityType string
+	SessionName  string
+}
+
+// ParseSessionNameFromArn parse role session name from assumed role ARN, e.g.
+// acs:ram::<account>:assumed-role/<role>/<session> or arn:aws:sts::<account>:assumed-role/<role>/<session>
+func ParseSessionNameFromArn(arn string) string {
+	_, resource, found := strings.Cut(arn, ":assumed-role/")
+	if !found {
+		return ""
+	}
+	parts := strings.Split(resource, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
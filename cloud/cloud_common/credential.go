@@ -0,0 +1,81 @@
+package cloud_common
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+)
+
+// Credential cloud credential fetched by profile, implemented by Alibaba Cloud, AWS, GCP, Azure and OIDC tokens,
+// commands use it instead of type switches, so a new cloud does not touch every command
+type Credential interface {
+	// CloudName e.g. Alibaba Cloud, AWS
+	CloudName() string
+	// GetExpiration returns nil when expiration is unknown
+	GetExpiration() *time.Time
+	// Environ environment variables for SDKs and CLIs, os and profile environments are not included
+	Environ(ctx context.Context, options *EnvironOptions) ([]string, error)
+	// Output fetch-token output
+	Output(options *FormatOptions) (*CredentialOutput, error)
+	// DisplayRows show-token rows
+	DisplayRows(options *FormatOptions) []*DisplayRow
+}
+
+type EnvironOptions struct {
+	Profile        string
+	Region         string // optional, set region environments
+	ForceNew       bool
+	CloudStsConfig *config.CloudStsConfig
+	// CredentialDir returns private temp dir for credential files, created on demand and removed by caller
+	CredentialDir func(cloud string) (string, error)
+	// CredentialFiles optional, render tool-specific credential files into CredentialDir for tools ignore environments
+	CredentialFiles bool
+	// CredentialEndpoint optional, export local credential endpoint instead of static credentials
+	CredentialEndpoint *CredentialEndpoint
+}
+
+const (
+	// CredentialEndpointPathAlibabaCloud followed by token, ALIBABA_CLOUD_CREDENTIALS_URI does not support headers
+	CredentialEndpointPathAlibabaCloud = "/alibaba_cloud/"
+	// CredentialEndpointPathAws token in Authorization header
+	CredentialEndpointPathAws = "/aws"
+)
+
+// CredentialEndpoint local endpoint serves fresh credentials to long-running processes
+type CredentialEndpoint struct {
+	Url   string // e.g. http://127.0.0.1:12345
+	Token string
+}
+
+type FormatOptions struct {
+	Format    string // optional, only for Alibaba Cloud, aliyuncli(default) or ossutilv2
+	OidcField string // optional, only for OIDC token, id_token or access_token
+}
+
+type CredentialOutput struct {
+	Content string
+	Raw     bool // raw token, printed without new line
+}
+
+// DisplayRow is a header value row, or a blank line when Separator is true
+type DisplayRow struct {
+	Header     string
+	Value      string
+	Expiration *time.Time // optional, printed with expiration status instead of value
+	Separator  bool
+}
+
+func NewDisplayRow(header, value string) *DisplayRow {
+	return &DisplayRow{
+		Header: header,
+		Value:  value,
+	}
+}
+
+func NewExpirationDisplayRow(expiration time.Time) *DisplayRow {
+	return &DisplayRow{
+		Header:     "Expiration",
+		Expiration: &expiration,
+	}
+}
@@ -0,0 +1,26 @@
+package cloud_common
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+// PromptMfaTokenCode read MFA token code from stdin, prompt message is printed to stderr,
+// so it works when stdout is used as credential output
+func PromptMfaTokenCode(mfaSerialNumber string) (string, error) {
+	utils.Stderr.Fprintf("Enter MFA token code for %s: ", mfaSerialNumber)
+	reader := bufio.NewReader(os.Stdin)
+	tokenCode, err := reader.ReadString('\n')
+	if err != nil && tokenCode == "" {
+		return "", errors.Wrapf(err, "read MFA token code for %s failed", mfaSerialNumber)
+	}
+	tokenCode = strings.TrimSpace(tokenCode)
+	if tokenCode == "" {
+		return "", errors.Errorf("MFA token code for %s is empty", mfaSerialNumber)
+	}
+	return tokenCode, nil
+}
@@ -0,0 +1,171 @@
+package cloud
+
+import (
+	"context"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/azure"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/gcp"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+)
+
+// CloudProvider fetches credential for profile which has the cloud config set
+type CloudProvider struct {
+	Name              string // config name, e.g. AlibabaCloud
+	SupportPolicyFile bool
+	IsSet             func(cloudStsConfig *config.CloudStsConfig) bool
+	// CredentialEnvironments optional, inherited credential environments are unset by execute when conflicting
+	CredentialEnvironments []string
+	Fetch                  func(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+		options *FetchCloudStsOptions) (cloud_common.Credential, error)
+}
+
+var cloudProviders []*CloudProvider
+
+// RegisterCloudProvider register cloud provider, providers are matched in register order
+func RegisterCloudProvider(cloudProvider *CloudProvider) {
+	cloudProviders = append(cloudProviders, cloudProvider)
+}
+
+func GetCloudProviders() []*CloudProvider {
+	return cloudProviders
+}
+
+// FindCloudProvider returns the first cloud provider which is set in config, nil when no cloud is set
+func FindCloudProvider(cloudStsConfig *config.CloudStsConfig) *CloudProvider {
+	for _, cloudProvider := range cloudProviders {
+		if cloudProvider.IsSet(cloudStsConfig) {
+			return cloudProvider
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterCloudProvider(&CloudProvider{
+		Name:              "AlibabaCloud",
+		SupportPolicyFile: true,
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.AlibabaCloud != nil
+		},
+		CredentialEnvironments: alibaba_cloud.CredentialEnvironments,
+		Fetch:                  fetchAlibabaCloudSts,
+	})
+	RegisterCloudProvider(&CloudProvider{
+		Name: "Aws",
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.Aws != nil
+		},
+		CredentialEnvironments: aws.CredentialEnvironments,
+		Fetch:                  fetchAwsSts,
+	})
+	RegisterCloudProvider(&CloudProvider{
+		Name: "AwsRolesAnywhere",
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.AwsRolesAnywhere != nil
+		},
+		CredentialEnvironments: aws.CredentialEnvironments,
+		Fetch:                  fetchAwsStsWithRolesAnywhere,
+	})
+	RegisterCloudProvider(&CloudProvider{
+		Name: "Gcp",
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.Gcp != nil
+		},
+		CredentialEnvironments: gcp.CredentialEnvironments,
+		Fetch:                  fetchGcpToken,
+	})
+	RegisterCloudProvider(&CloudProvider{
+		Name: "AzureAd",
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.AzureAd != nil
+		},
+		CredentialEnvironments: azure.CredentialEnvironments,
+		Fetch:                  fetchAzureToken,
+	})
+	RegisterCloudProvider(&CloudProvider{
+		Name: "OidcToken",
+		IsSet: func(cloudStsConfig *config.CloudStsConfig) bool {
+			return cloudStsConfig.OidcToken != nil
+		},
+		Fetch: fetchOidcToken,
+	})
+}
+
+// fetch functions return nil interface on error, typed nil pointer would be a non-nil Credential
+
+func fetchAlibabaCloudSts(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	stsOptions := &alibaba_cloud.FetchStsWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
+	}
+	alibabaCloudStsConfig := alibaba_cloud.OverridePolicyFile(cloudStsConfig.AlibabaCloud, options.PolicyFile)
+	sts, err := alibaba_cloud.FetchStsWithOidcConfig(ctx, profile, alibabaCloudStsConfig, stsOptions)
+	if err != nil {
+		return nil, err
+	}
+	return sts, nil
+}
+
+func fetchAwsSts(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	awsStsOptions := &aws.FetchAwsStsWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
+	}
+	awsStsToken, err := aws.FetchAwsStsWithOidcConfig(ctx, profile, cloudStsConfig.Aws, awsStsOptions)
+	if err != nil {
+		return nil, err
+	}
+	return awsStsToken, nil
+}
+
+func fetchAwsStsWithRolesAnywhere(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	rolesAnywhereOptions := &aws.FetchAwsStsWithRolesAnywhereOptions{
+		ForceNew: options.ForceNew,
+	}
+	awsStsToken, err := aws.FetchAwsStsWithRolesAnywhere(ctx, profile, cloudStsConfig.AwsRolesAnywhere, rolesAnywhereOptions)
+	if err != nil {
+		return nil, err
+	}
+	return awsStsToken, nil
+}
+
+func fetchGcpToken(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	gcpTokenOptions := &gcp.FetchGcpTokenWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
+	}
+	gcpToken, err := gcp.FetchGcpTokenWithOidcConfig(ctx, profile, cloudStsConfig.Gcp, gcpTokenOptions)
+	if err != nil {
+		return nil, err
+	}
+	return gcpToken, nil
+}
+
+func fetchAzureToken(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	azureTokenOptions := &azure.FetchAzureTokenWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
+	}
+	azureToken, err := azure.FetchAzureTokenWithOidcConfig(ctx, profile, cloudStsConfig.AzureAd, azureTokenOptions)
+	if err != nil {
+		return nil, err
+	}
+	return azureToken, nil
+}
+
+func fetchOidcToken(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig,
+	options *FetchCloudStsOptions) (cloud_common.Credential, error) {
+	oidcTokenConfigOptions := &oidc.FetchOidcTokenConfigOptions{
+		ForceNew:       options.ForceNew,
+		FetchTokenType: options.FetchOidcTokenType,
+	}
+	oidcToken, err := oidc.FetchOidcToken(ctx, profile, cloudStsConfig.OidcToken, oidcTokenConfigOptions)
+	if err != nil {
+		return nil, err
+	}
+	return oidcToken, nil
+}
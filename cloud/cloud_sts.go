@@ -1,11 +1,13 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/pkg/errors"
@@ -14,59 +16,84 @@ import (
 type FetchCloudStsOptions struct {
 	ForceNew           bool
 	FetchOidcTokenType oidc.FetchOidcTokenType
+	PolicyFile         string // optional, override session policy, only for Alibaba Cloud
 }
 
-func FetchCloudStsFromDefaultConfig(profile string, options *FetchCloudStsOptions) (any, *config.CloudStsConfig, error) {
+func FetchCloudStsFromDefaultConfig(ctx context.Context, profile string, options *FetchCloudStsOptions) (
+	cloud_common.Credential, *config.CloudStsConfig, error) {
 	profile, cloudStsConfig, err := config.FindProfile(profile)
 	if err != nil {
 		return nil, cloudStsConfig, fmt.Errorf("find profie `%s` error: %s", profile, err)
 	}
-	sts, err := FetchCloudSts(profile, cloudStsConfig, options)
+	credential, err := FetchCloudSts(ctx, profile, cloudStsConfig, options)
 	if err != nil {
 		return nil, cloudStsConfig, err
 	}
-	return sts, cloudStsConfig, nil
+	return credential, cloudStsConfig, nil
 }
 
-func FetchCloudSts(profile string, cloudStsConfig *config.CloudStsConfig, options *FetchCloudStsOptions) (any, error) {
-	hasAlibabaCloud := cloudStsConfig.AlibabaCloud != nil
-	hasAws := cloudStsConfig.Aws != nil
-	hasOidcToken := cloudStsConfig.OidcToken != nil
+func FetchCloudSts(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig, options *FetchCloudStsOptions) (
+	cloud_common.Credential, error) {
+	err := checkMultipleClouds(profile, cloudStsConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, cloudProvider := range cloudProviders {
+		if !cloudProvider.IsSet(cloudStsConfig) {
+			continue
+		}
+		if options.PolicyFile != "" && !cloudProvider.SupportPolicyFile {
+			return nil, fmt.Errorf("policy file is only supported by Alibaba Cloud STS, profile: %s", profile)
+		}
+		return cloudProvider.Fetch(ctx, profile, cloudStsConfig, options)
+	}
+	return nil, errors.New("no cloud provider is set")
+}
 
-	var clouds []string
-	if hasAlibabaCloud {
-		clouds = append(clouds, "AlibabaCloud")
+// FetchAlibabaCloudStsChain fetch Alibaba Cloud STS tokens of AssumeRoleWithOIDC and all AssumeRole hops
+func FetchAlibabaCloudStsChain(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig, options *FetchCloudStsOptions) (
+	[]*alibaba_cloud.StsTokenHop, error) {
+	err := checkMultipleClouds(profile, cloudStsConfig)
+	if err != nil {
+		return nil, err
 	}
-	if hasAws {
-		clouds = append(clouds, "Aws")
+	if cloudStsConfig.AlibabaCloud == nil {
+		return nil, errors.New("Alibaba Cloud STS is not set")
 	}
-	if hasOidcToken {
-		clouds = append(clouds, "OidcToken")
+	stsOptions := &alibaba_cloud.FetchStsWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
 	}
+	alibabaCloudStsConfig := alibaba_cloud.OverridePolicyFile(cloudStsConfig.AlibabaCloud, options.PolicyFile)
+	return alibaba_cloud.FetchStsChainWithOidcConfig(ctx, profile, alibabaCloudStsConfig, stsOptions)
+}
 
-	if len(clouds) > 1 {
-		return nil, fmt.Errorf("multiple counds: %s found for profile: %s",
-			strings.Join(clouds, ", "), profile)
+// FetchAwsStsChain fetch AWS STS tokens of AssumeRoleWithWebIdentity and all AssumeRole hops
+func FetchAwsStsChain(ctx context.Context, profile string, cloudStsConfig *config.CloudStsConfig, options *FetchCloudStsOptions) (
+	[]*aws.AwsStsTokenHop, error) {
+	err := checkMultipleClouds(profile, cloudStsConfig)
+	if err != nil {
+		return nil, err
 	}
-
-	if hasAlibabaCloud {
-		stsOptions := &alibaba_cloud.FetchStsWithOidcConfigOptions{
-			ForceNew: options.ForceNew,
-		}
-		return alibaba_cloud.FetchStsWithOidcConfig(profile, cloudStsConfig.AlibabaCloud, stsOptions)
+	if cloudStsConfig.Aws == nil {
+		return nil, errors.New("AWS STS is not set")
 	}
-	if hasAws {
-		awsStsOptions := &aws.FetchAwsStsWithOidcConfigOptions{
-			ForceNew: options.ForceNew,
-		}
-		return aws.FetchAwsStsWithOidcConfig(profile, cloudStsConfig.Aws, awsStsOptions)
+	awsStsOptions := &aws.FetchAwsStsWithOidcConfigOptions{
+		ForceNew: options.ForceNew,
 	}
-	if hasOidcToken {
-		oidcTokenConfigOptions := &oidc.FetchOidcTokenConfigOptions{
-			ForceNew:       options.ForceNew,
-			FetchTokenType: options.FetchOidcTokenType,
+	return aws.FetchAwsStsChainWithOidcConfig(ctx, profile, cloudStsConfig.Aws, awsStsOptions)
+}
+
+func checkMultipleClouds(profile string, cloudStsConfig *config.CloudStsConfig) error {
+	var clouds []string
+	for _, cloudProvider := range cloudProviders {
+		if cloudProvider.IsSet(cloudStsConfig) {
+			clouds = append(clouds, cloudProvider.Name)
 		}
-		return oidc.FetchOidcToken(profile, cloudStsConfig.OidcToken, oidcTokenConfigOptions)
 	}
-	return nil, errors.New("no cloud provider is set")
+
+	if len(clouds) > 1 {
+		return fmt.Errorf("multiple counds: %s found for profile: %s",
+			strings.Join(clouds, ", "), profile)
+	}
+	return nil
 }
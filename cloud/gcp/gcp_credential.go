@@ -0,0 +1,92 @@
+package gcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
+	"github.com/pkg/errors"
+)
+
+var _ cloud_common.Credential = (*GcpToken)(nil)
+
+// CredentialEnvironments credential environments read by GCP client libraries, gcloud and Terraform,
+// including environments not set by execute
+var CredentialEnvironments = []string{
+	"GOOGLE_APPLICATION_CREDENTIALS", "GOOGLE_CREDENTIALS", "GOOGLE_CLOUD_KEYFILE_JSON", "GOOGLE_OAUTH_ACCESS_TOKEN",
+	"CLOUDSDK_AUTH_ACCESS_TOKEN", "CLOUDSDK_AUTH_CREDENTIAL_FILE_OVERRIDE",
+}
+
+func (t *GcpToken) CloudName() string {
+	return "GCP"
+}
+
+func (t *GcpToken) GetExpiration() *time.Time {
+	return &t.Expiration
+}
+
+// Environ access token for gcloud and Terraform, external account credential file for client libraries
+// reference: https://cloud.google.com/iam/docs/workload-identity-federation-with-other-providers#use-the-credential-configuration
+// reference: https://registry.terraform.io/providers/hashicorp/google/latest/docs/guides/provider_reference#access_token-1
+func (t *GcpToken) Environ(ctx context.Context, options *cloud_common.EnvironOptions) ([]string, error) {
+	if options.CredentialEndpoint != nil {
+		return nil, errors.New("GCP credential endpoint is not supported")
+	}
+	if options.CloudStsConfig == nil || options.CloudStsConfig.Gcp == nil {
+		return nil, errors.New("GCP config is required")
+	}
+	gcpStsConfig := options.CloudStsConfig.Gcp
+	var env []string
+
+	// OIDC token is cached, external account credential file exchanges token by client libraries themselves
+	fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
+		ForceNew: options.ForceNew,
+	}
+	oidcToken, err := idp.FetchOidcToken(ctx, options.Profile, gcpStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
+	if err != nil {
+		return nil, err
+	}
+	credentialDir, err := options.CredentialDir("gcp")
+	if err != nil {
+		return nil, err
+	}
+	credentialFile, err := WriteExternalAccountCredentialFile(credentialDir, gcpStsConfig, oidcToken)
+	if err != nil {
+		return nil, err
+	}
+	idaaslog.Debug.PrintfLn("Found external account credential file: %s", credentialFile)
+	env = append(env, "GOOGLE_APPLICATION_CREDENTIALS="+credentialFile)
+
+	env = append(env, "CLOUDSDK_AUTH_ACCESS_TOKEN="+t.AccessToken)
+	env = append(env, "GOOGLE_OAUTH_ACCESS_TOKEN="+t.AccessToken)
+
+	if options.Region != "" {
+		idaaslog.Debug.PrintfLn("Set region: %s", options.Region)
+		env = append(env, "CLOUDSDK_COMPUTE_REGION="+options.Region)
+		env = append(env, "GOOGLE_REGION="+options.Region)
+	}
+	return env, nil
+}
+
+func (t *GcpToken) Output(options *cloud_common.FormatOptions) (*cloud_common.CredentialOutput, error) {
+	content, err := t.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return &cloud_common.CredentialOutput{Content: content}, nil
+}
+
+func (t *GcpToken) DisplayRows(options *cloud_common.FormatOptions) []*cloud_common.DisplayRow {
+	var rows []*cloud_common.DisplayRow
+	if t.ServiceAccountEmail != "" {
+		rows = append(rows, cloud_common.NewDisplayRow("Service Account", t.ServiceAccountEmail))
+	}
+	rows = append(rows,
+		cloud_common.NewDisplayRow("Access Token Type", t.TokenType),
+		cloud_common.NewDisplayRow("Access Token", t.AccessToken),
+		cloud_common.NewExpirationDisplayRow(t.Expiration),
+	)
+	return rows
+}
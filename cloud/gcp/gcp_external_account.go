@@ -0,0 +1,85 @@
+package gcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	ExternalAccountType            = "external_account"
+	ExternalAccountFileName        = "external_account.json"
+	ExternalAccountOidcTokenFile   = "oidc_token"
+	CredentialSourceFormatTypeText = "text"
+)
+
+// ExternalAccountCredential credential file for Google Cloud client libraries and gcloud
+// reference: https://google.aip.dev/auth/4117
+type ExternalAccountCredential struct {
+	Type                           string                           `json:"type"`
+	Audience                       string                           `json:"audience"`
+	SubjectTokenType               string                           `json:"subject_token_type"`
+	TokenUrl                       string                           `json:"token_url"`
+	ServiceAccountImpersonationUrl string                           `json:"service_account_impersonation_url,omitempty"`
+	ServiceAccountImpersonation    *ServiceAccountImpersonation     `json:"service_account_impersonation,omitempty"`
+	CredentialSource               *ExternalAccountCredentialSource `json:"credential_source"`
+}
+
+type ServiceAccountImpersonation struct {
+	TokenLifetimeSeconds int64 `json:"token_lifetime_seconds"`
+}
+
+type ExternalAccountCredentialSource struct {
+	File   string                                 `json:"file"`
+	Format *ExternalAccountCredentialSourceFormat `json:"format"`
+}
+
+type ExternalAccountCredentialSourceFormat struct {
+	Type string `json:"type"`
+}
+
+func BuildExternalAccountCredential(gcpStsConfig *config.GcpStsConfig, oidcTokenFile string) *ExternalAccountCredential {
+	credential := &ExternalAccountCredential{
+		Type:                           ExternalAccountType,
+		Audience:                       gcpStsConfig.Audience,
+		SubjectTokenType:               TokenTypeJwt,
+		TokenUrl:                       GetStsEndpoint(gcpStsConfig),
+		ServiceAccountImpersonationUrl: GetServiceAccountImpersonationUrl(gcpStsConfig),
+		CredentialSource: &ExternalAccountCredentialSource{
+			File: oidcTokenFile,
+			Format: &ExternalAccountCredentialSourceFormat{
+				Type: CredentialSourceFormatTypeText,
+			},
+		},
+	}
+	if gcpStsConfig.ServiceAccountEmail != "" && gcpStsConfig.TokenLifetimeSeconds > 0 {
+		credential.ServiceAccountImpersonation = &ServiceAccountImpersonation{
+			TokenLifetimeSeconds: gcpStsConfig.TokenLifetimeSeconds,
+		}
+	}
+	return credential
+}
+
+// WriteExternalAccountCredentialFile writes OIDC token file and external account credential file into dir,
+// returns the external account credential file path
+func WriteExternalAccountCredentialFile(dir string, gcpStsConfig *config.GcpStsConfig, oidcToken string) (string, error) {
+	oidcTokenFile := filepath.Join(dir, ExternalAccountOidcTokenFile)
+	err := os.WriteFile(oidcTokenFile, []byte(oidcToken), 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write OIDC token file: %s failed", oidcTokenFile)
+	}
+	credential := BuildExternalAccountCredential(gcpStsConfig, oidcTokenFile)
+	credentialBytes, err := json.MarshalIndent(credential, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "marshal external account credential failed")
+	}
+	credentialFile := filepath.Join(dir, ExternalAccountFileName)
+	err = os.WriteFile(credentialFile, credentialBytes, 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write external account credential file: %s failed", credentialFile)
+	}
+	return credentialFile, nil
+}
@@ -0,0 +1,46 @@
+package gcp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+type GcpToken struct {
+	Version             int       `json:"Version"`
+	AccessToken         string    `json:"AccessToken"`
+	TokenType           string    `json:"TokenType"`
+	ServiceAccountEmail string    `json:"ServiceAccountEmail,omitempty"`
+	Expiration          time.Time `json:"Expiration"`
+}
+
+func (t *GcpToken) Marshal() (string, error) {
+	if t == nil {
+		return "null", nil
+	}
+	tokenBytes, err := json.Marshal(t)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal gcp token failed")
+	}
+	return string(tokenBytes), nil
+}
+
+func UnmarshalGcpToken(token string) (*GcpToken, error) {
+	var gcpToken GcpToken
+	err := json.Unmarshal([]byte(token), &gcpToken)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal gcp token: %s failed", token)
+	}
+	return &gcpToken, nil
+}
+
+func (t *GcpToken) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
+	idaaslog.Debug.PrintfLn("Check is valid, expiration: %s, threshold: %d ms",
+		t.Expiration, thresholdDuration.Milliseconds())
+	valid := utils.UntilFor(serverHost, t.Expiration) > thresholdDuration
+	idaaslog.Info.PrintfLn("Check is valid: %s", valid)
+	return valid
+}
@@ -0,0 +1,293 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultStsEndpoint            = "https://sts.googleapis.com/v1/token"
+	DefaultIamCredentialsEndpoint = "https://iamcredentials.googleapis.com"
+	DefaultScope                  = "https://www.googleapis.com/auth/cloud-platform"
+	DefaultTokenLifetimeSeconds   = 3600
+)
+
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJwt           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+type FetchGcpTokenWithOidcConfigOptions struct {
+	ForceNew bool
+}
+
+type FetchGcpTokenWithOidcOptions struct {
+	Audience               string
+	StsEndpoint            string
+	Scope                  string
+	ServiceAccountEmail    string
+	IamCredentialsEndpoint string
+	TokenLifetimeSeconds   int64
+	FetchOidcToken         func() (string, error)
+	ForceNew               bool
+}
+
+// stsTokenExchangeResponse
+// reference: https://cloud.google.com/iam/docs/reference/sts/rest/v1/TopLevel/token
+type stsTokenExchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	IssuedTokenType  string `json:"issued_token_type"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// generateAccessTokenResponse
+// reference: https://cloud.google.com/iam/docs/reference/credentials/rest/v1/projects.serviceAccounts/generateAccessToken
+type generateAccessTokenResponse struct {
+	AccessToken string `json:"accessToken"`
+	ExpireTime  string `json:"expireTime"`
+}
+
+func FetchGcpTokenWithOidcConfig(ctx context.Context, profile string, gcpStsConfig *config.GcpStsConfig,
+	configOptions *FetchGcpTokenWithOidcConfigOptions) (
+	*GcpToken, error) {
+	if gcpStsConfig.Audience == "" {
+		return nil, errors.New("Audience is required")
+	}
+	if gcpStsConfig.OidcTokenProvider == nil {
+		return nil, errors.New("OidcTokenProvider is required")
+	}
+
+	options := &FetchGcpTokenWithOidcOptions{
+		Audience:               gcpStsConfig.Audience,
+		StsEndpoint:            GetStsEndpoint(gcpStsConfig),
+		Scope:                  getScope(gcpStsConfig),
+		ServiceAccountEmail:    gcpStsConfig.ServiceAccountEmail,
+		IamCredentialsEndpoint: getIamCredentialsEndpoint(gcpStsConfig),
+		TokenLifetimeSeconds:   gcpStsConfig.TokenLifetimeSeconds,
+		FetchOidcToken: func() (string, error) {
+			fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
+				ForceNew: configOptions.ForceNew,
+			}
+			return idp.FetchOidcToken(ctx, profile, gcpStsConfig.OidcTokenProvider, fetchOidcTokenOptions)
+		},
+		ForceNew: configOptions.ForceNew,
+	}
+	return FetchGcpTokenWithOidc(ctx, profile, gcpStsConfig, options)
+}
+
+func FetchGcpTokenWithOidc(ctx context.Context, profile string, gcpStsConfig *config.GcpStsConfig, options *FetchGcpTokenWithOidcOptions) (*GcpToken, error) {
+	digest := gcpStsConfig.Digest()
+	serverHost := getTokenServerHost(options)
+	readCacheFileOptions := &utils.ReadCacheOptions{
+		Context: map[string]interface{}{
+			"profile": profile,
+			"digest":  digest,
+			"config":  gcpStsConfig,
+		},
+		FetchContent: func() (int, string, error) {
+			return fetchContent(ctx, options)
+		},
+		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpiringOrExpired(serverHost, s)
+		},
+		IsContentExpired: func(s *utils.StringWithTime) bool {
+			return isContentExpired(serverHost, s)
+		},
+		ForceNew: options.ForceNew,
+	}
+
+	cacheKey := fmt.Sprintf("%s_%s", profile, digest[0:32])
+	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryCloudToken, cacheKey)
+	gcpTokenStr, err := utils.ReadCacheFileWithEncryptionCallback(
+		constants.CategoryCloudToken, cacheKey, readCacheFileOptions)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetch cloud_token token with OIDC: %v", err)
+		return nil, err
+	}
+	return UnmarshalGcpToken(gcpTokenStr)
+}
+
+// GetStsEndpoint returns Google STS token endpoint, also used as `token_url` in external account credential file
+func GetStsEndpoint(gcpStsConfig *config.GcpStsConfig) string {
+	if gcpStsConfig.StsEndpoint != "" {
+		return gcpStsConfig.StsEndpoint
+	}
+	return DefaultStsEndpoint
+}
+
+// GetServiceAccountImpersonationUrl returns empty when no service account is configured
+func GetServiceAccountImpersonationUrl(gcpStsConfig *config.GcpStsConfig) string {
+	if gcpStsConfig.ServiceAccountEmail == "" {
+		return ""
+	}
+	return buildImpersonationUrl(getIamCredentialsEndpoint(gcpStsConfig), gcpStsConfig.ServiceAccountEmail)
+}
+
+func buildImpersonationUrl(iamCredentialsEndpoint, serviceAccountEmail string) string {
+	return fmt.Sprintf("%s/v1/projects/-/serviceAccounts/%s:generateAccessToken",
+		strings.TrimSuffix(iamCredentialsEndpoint, "/"), url.PathEscape(serviceAccountEmail))
+}
+
+func getScope(gcpStsConfig *config.GcpStsConfig) string {
+	if gcpStsConfig.Scope != "" {
+		return gcpStsConfig.Scope
+	}
+	return DefaultScope
+}
+
+func getIamCredentialsEndpoint(gcpStsConfig *config.GcpStsConfig) string {
+	if gcpStsConfig.IamCredentialsEndpoint != "" {
+		return gcpStsConfig.IamCredentialsEndpoint
+	}
+	return DefaultIamCredentialsEndpoint
+}
+
+// getTokenServerHost token is issued by IAM credentials when impersonating service account, otherwise by STS
+func getTokenServerHost(options *FetchGcpTokenWithOidcOptions) string {
+	if options.ServiceAccountEmail != "" {
+		return options.IamCredentialsEndpoint
+	}
+	return options.StsEndpoint
+}
+
+func fetchContent(ctx context.Context, options *FetchGcpTokenWithOidcOptions) (int, string, error) {
+	oidcToken, err := options.FetchOidcToken()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error fetching oidc token: %v", err)
+		return 600, "", err
+	}
+	startTime := utils.NowFor(options.StsEndpoint)
+	stsResponse, err := exchangeToken(ctx, oidcToken, options)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error exchanging token: %v", err)
+		return 600, "", err
+	}
+	gcpToken := &GcpToken{
+		Version:     1,
+		AccessToken: stsResponse.AccessToken,
+		TokenType:   stsResponse.TokenType,
+		Expiration:  startTime.Add(time.Duration(stsResponse.ExpiresIn) * time.Second),
+	}
+	if options.ServiceAccountEmail != "" {
+		gcpToken, err = generateAccessToken(ctx, stsResponse.AccessToken, options)
+		if err != nil {
+			idaaslog.Error.PrintfLn("Error impersonating service account: %v", err)
+			return 600, "", err
+		}
+	}
+	gcpTokenJson, err := gcpToken.Marshal()
+	if err != nil {
+		idaaslog.Error.PrintfLn("Error marshaling gcp token: %v", err)
+		return 600, "", err
+	}
+	return 200, gcpTokenJson, nil
+}
+
+// exchangeToken exchanges OIDC token to federated access token, RFC 8693
+func exchangeToken(ctx context.Context, oidcToken string, options *FetchGcpTokenWithOidcOptions) (*stsTokenExchangeResponse, error) {
+	parameters := map[string]string{
+		"grant_type":           GrantTypeTokenExchange,
+		"audience":             options.Audience,
+		"scope":                options.Scope,
+		"requested_token_type": TokenTypeAccessToken,
+		"subject_token":        oidcToken,
+		"subject_token_type":   TokenTypeJwt,
+	}
+	idaaslog.Debug.PrintfLn("Exchange token, Audience: %s, Endpoint: %s", options.Audience, options.StsEndpoint)
+	idaaslog.Unsafe.PrintfLn("Exchange token, OIDC Token: %s", oidcToken)
+	statusCode, response, err := utils.PostHttp(ctx, options.StsEndpoint, parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange token failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Exchange token, status: %d, response: %s", statusCode, response)
+	var stsResponse stsTokenExchangeResponse
+	err = json.Unmarshal([]byte(response), &stsResponse)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse exchange token response failed, status: %d", statusCode)
+	}
+	if statusCode != 200 || stsResponse.Error != "" {
+		return nil, errors.Errorf("exchange token failed, status: %d, error: %s, description: %s",
+			statusCode, stsResponse.Error, stsResponse.ErrorDescription)
+	}
+	if stsResponse.AccessToken == "" {
+		return nil, errors.Errorf("exchange token failed, no access token in response, status: %d", statusCode)
+	}
+	return &stsResponse, nil
+}
+
+// generateAccessToken impersonates service account with federated access token
+func generateAccessToken(ctx context.Context, federatedAccessToken string, options *FetchGcpTokenWithOidcOptions) (*GcpToken, error) {
+	impersonationUrl := buildImpersonationUrl(options.IamCredentialsEndpoint, options.ServiceAccountEmail)
+	lifetimeSeconds := options.TokenLifetimeSeconds
+	if lifetimeSeconds <= 0 {
+		lifetimeSeconds = DefaultTokenLifetimeSeconds
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + federatedAccessToken,
+	}
+	body := map[string]any{
+		"scope":    strings.Fields(options.Scope),
+		"lifetime": fmt.Sprintf("%ds", lifetimeSeconds),
+	}
+	idaaslog.Debug.PrintfLn("Generate access token, ServiceAccount: %s, Url: %s",
+		options.ServiceAccountEmail, impersonationUrl)
+	statusCode, response, err := utils.PostJsonHttp(ctx, impersonationUrl, headers, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "generate access token failed")
+	}
+	idaaslog.Unsafe.PrintfLn("Generate access token, status: %d, response: %s", statusCode, response)
+	if statusCode != 200 {
+		return nil, errors.Errorf("generate access token failed, status: %d, response: %s", statusCode, response)
+	}
+	var accessTokenResponse generateAccessTokenResponse
+	err = json.Unmarshal([]byte(response), &accessTokenResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse generate access token response failed")
+	}
+	expiration, err := time.Parse(time.RFC3339, accessTokenResponse.ExpireTime)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse expire time: %s failed", accessTokenResponse.ExpireTime)
+	}
+	return &GcpToken{
+		Version:             1,
+		AccessToken:         accessTokenResponse.AccessToken,
+		TokenType:           "Bearer",
+		ServiceAccountEmail: options.ServiceAccountEmail,
+		Expiration:          expiration,
+	}, nil
+}
+
+func isContentExpiringOrExpired(serverHost string, s *utils.StringWithTime) bool {
+	gcpToken, err := UnmarshalGcpToken(s.Content)
+	if err != nil {
+		return true
+	}
+	valid := gcpToken.IsValidAtLeastThreshold(serverHost, 20*time.Minute)
+	idaaslog.Debug.PrintfLn("Check GCP token is expiring or expired: %s", !valid)
+	return !valid
+}
+
+func isContentExpired(serverHost string, s *utils.StringWithTime) bool {
+	gcpToken, err := UnmarshalGcpToken(s.Content)
+	if err != nil {
+		return true
+	}
+	valid := gcpToken.IsValidAtLeastThreshold(serverHost, 3*time.Minute)
+	idaaslog.Debug.PrintfLn("Check GCP token is expired: %s", !valid)
+	return !valid
+}
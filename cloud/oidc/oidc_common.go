@@ -8,6 +8,7 @@ import (
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
 )
 
@@ -112,7 +113,8 @@ func ParseIdTokenPayload(idToken string) (*IdTokenPayload, error) {
 	return &idTokenPayload, nil
 }
 
-func (t *OidcToken) IsValidAtLeastThreshold(fetchTokenType FetchOidcTokenType, thresholdDuration time.Duration) bool {
+func (t *OidcToken) IsValidAtLeastThreshold(serverHost string, fetchTokenType FetchOidcTokenType,
+	thresholdDuration time.Duration) bool {
 	// check ID token
 	if t.IdToken != "" && fetchTokenType.IsFetchIdToken() {
 		idTokenPayload, err := ParseIdTokenPayload(t.IdToken)
@@ -124,7 +126,7 @@ func (t *OidcToken) IsValidAtLeastThreshold(fetchTokenType FetchOidcTokenType, t
 		if idTokenPayload.Exp == 0 {
 			return false
 		}
-		valid := time.Until(time.Unix(idTokenPayload.Exp, 0)) > thresholdDuration
+		valid := utils.UntilFor(serverHost, time.Unix(idTokenPayload.Exp, 0)) > thresholdDuration
 		idaaslog.Info.PrintfLn("Check ID token is valid: %s", valid)
 		if !valid {
 			return false
@@ -134,7 +136,7 @@ func (t *OidcToken) IsValidAtLeastThreshold(fetchTokenType FetchOidcTokenType, t
 	if t.ExpiresAt > 0 && fetchTokenType.IsFetchAccessToken() {
 		idaaslog.Debug.PrintfLn("Check access token is valid, expire at: %s, threshold: %d ms",
 			t.ExpiresAt, thresholdDuration.Milliseconds())
-		valid := time.Until(time.Unix(t.ExpiresAt, 0)) > thresholdDuration
+		valid := utils.UntilFor(serverHost, time.Unix(t.ExpiresAt, 0)) > thresholdDuration
 		idaaslog.Info.PrintfLn("Check access token is valid: %s", valid)
 		return valid
 	}
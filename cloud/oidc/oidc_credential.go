@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/pkg/errors"
+)
+
+var _ cloud_common.Credential = (*OidcToken)(nil)
+
+func (t *OidcToken) CloudName() string {
+	return "OIDC"
+}
+
+// GetExpiration the earlier expiration of ID token and access token
+func (t *OidcToken) GetExpiration() *time.Time {
+	var expiresAt *time.Time
+	if t.IdToken != "" {
+		idTokenPayload, err := ParseIdTokenPayload(t.IdToken)
+		if err == nil && idTokenPayload.Exp > 0 {
+			idTokenExpiresAt := time.Unix(idTokenPayload.Exp, 0)
+			expiresAt = &idTokenExpiresAt
+		}
+	}
+	if t.ExpiresAt > 0 {
+		accessTokenExpiresAt := time.Unix(t.ExpiresAt, 0)
+		if expiresAt == nil || accessTokenExpiresAt.Before(*expiresAt) {
+			expiresAt = &accessTokenExpiresAt
+		}
+	}
+	return expiresAt
+}
+
+func (t *OidcToken) Environ(ctx context.Context, options *cloud_common.EnvironOptions) ([]string, error) {
+	return nil, errors.New("OIDC token has no cloud environments")
+}
+
+func (t *OidcToken) Output(options *cloud_common.FormatOptions) (*cloud_common.CredentialOutput, error) {
+	switch getOidcTokenType(options) {
+	case FetchIdToken:
+		return &cloud_common.CredentialOutput{Content: t.IdToken, Raw: true}, nil
+	case FetchAccessToken:
+		return &cloud_common.CredentialOutput{Content: t.AccessToken, Raw: true}, nil
+	default:
+		content, err := t.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		return &cloud_common.CredentialOutput{Content: content}, nil
+	}
+}
+
+func (t *OidcToken) DisplayRows(options *cloud_common.FormatOptions) []*cloud_common.DisplayRow {
+	oidcTokenType := getOidcTokenType(options)
+	showIdToken := t.IdToken != "" && oidcTokenType.IsFetchIdToken()
+	showAccessToken := t.AccessToken != "" && oidcTokenType.IsFetchAccessToken()
+
+	var rows []*cloud_common.DisplayRow
+	if showIdToken {
+		rows = append(rows, cloud_common.NewDisplayRow("ID Token", t.IdToken))
+		idTokenPayload, err := ParseIdTokenPayload(t.IdToken)
+		if err == nil {
+			rows = append(rows, cloud_common.NewExpirationDisplayRow(time.Unix(idTokenPayload.Exp, 0)))
+		}
+	}
+	if showIdToken && showAccessToken {
+		rows = append(rows, &cloud_common.DisplayRow{Separator: true})
+	}
+	if showAccessToken {
+		rows = append(rows,
+			cloud_common.NewDisplayRow("Access Token Type", t.TokenType),
+			cloud_common.NewDisplayRow("Access Token", t.AccessToken),
+		)
+		if t.ExpiresAt > 0 {
+			rows = append(rows, cloud_common.NewExpirationDisplayRow(time.Unix(t.ExpiresAt, 0)))
+		}
+	}
+	if t.RefreshToken != "" {
+		rows = append(rows, cloud_common.NewDisplayRow("Refresh Token", t.RefreshToken))
+	}
+	return rows
+}
+
+func getOidcTokenType(options *cloud_common.FormatOptions) FetchOidcTokenType {
+	if options == nil {
+		return FetchDefault
+	}
+	return GetOidcTokenType(options.OidcField)
+}
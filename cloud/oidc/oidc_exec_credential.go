@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ExecCredentialKind       = "ExecCredential"
+	ExecCredentialApiVersion = "client.authentication.k8s.io/v1"
+)
+
+// ExecCredential kubectl exec credential plugin output
+// reference: https://kubernetes.io/docs/reference/access-authn-authz/authentication/#input-and-output-formats
+type ExecCredential struct {
+	Kind       string                `json:"kind"`
+	ApiVersion string                `json:"apiVersion"`
+	Spec       *ExecCredentialSpec   `json:"spec"`
+	Status     *ExecCredentialStatus `json:"status"`
+}
+
+type ExecCredentialSpec struct {
+	Interactive bool `json:"interactive"`
+}
+
+type ExecCredentialStatus struct {
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+	Token               string `json:"token"`
+}
+
+// ToExecCredential use ID token by default, access token when FetchAccessToken or ID token is absent
+func (t *OidcToken) ToExecCredential(fetchTokenType FetchOidcTokenType) (*ExecCredential, error) {
+	var token string
+	var expiration int64
+	if t.IdToken != "" && fetchTokenType.IsFetchIdToken() {
+		idTokenPayload, err := ParseIdTokenPayload(t.IdToken)
+		if err != nil {
+			return nil, err
+		}
+		token = t.IdToken
+		expiration = idTokenPayload.Exp
+	} else if t.AccessToken != "" && fetchTokenType.IsFetchAccessToken() {
+		token = t.AccessToken
+		expiration = t.ExpiresAt
+	} else {
+		return nil, errors.New("no ID token or access token found")
+	}
+	status := &ExecCredentialStatus{
+		Token: token,
+	}
+	if expiration > 0 {
+		status.ExpirationTimestamp = time.Unix(expiration, 0).UTC().Format(time.RFC3339)
+	}
+	return &ExecCredential{
+		Kind:       ExecCredentialKind,
+		ApiVersion: ExecCredentialApiVersion,
+		Spec:       &ExecCredentialSpec{},
+		Status:     status,
+	}, nil
+}
+
+func (c *ExecCredential) Marshal() (string, error) {
+	execCredentialBytes, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal exec credential failed")
+	}
+	return string(execCredentialBytes), nil
+}
@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -16,9 +17,10 @@ type FetchOidcTokenConfigOptions struct {
 	FetchTokenType FetchOidcTokenType
 }
 
-func FetchOidcToken(profile string, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenConfigOptions) (
+func FetchOidcToken(ctx context.Context, profile string, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenConfigOptions) (
 	*OidcToken, error) {
 	digest := oidcTokenProviderConfig.Digest()
+	serverHost := idp.GetTokenServerHost(oidcTokenProviderConfig)
 	readCacheFileOptions := &utils.ReadCacheOptions{
 		Context: map[string]interface{}{
 			"profile": profile,
@@ -26,13 +28,13 @@ func FetchOidcToken(profile string, oidcTokenProviderConfig *config.OidcTokenPro
 			"config":  oidcTokenProviderConfig,
 		},
 		FetchContent: func() (int, string, error) {
-			return fetchContent(oidcTokenProviderConfig, options)
+			return fetchContent(ctx, oidcTokenProviderConfig, options)
 		},
 		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpiringOrExpired(options.FetchTokenType, s)
+			return isContentExpiringOrExpired(serverHost, options.FetchTokenType, s)
 		},
 		IsContentExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpired(options.FetchTokenType, s)
+			return isContentExpired(serverHost, options.FetchTokenType, s)
 		},
 		ForceNew: options.ForceNew,
 	}
@@ -48,12 +50,12 @@ func FetchOidcToken(profile string, oidcTokenProviderConfig *config.OidcTokenPro
 	return UnmarshalOidcToken(oidcTokenStr)
 }
 
-func fetchContent(oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenConfigOptions) (int, string, error) {
-	startTime := time.Now().Unix()
+func fetchContent(ctx context.Context, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenConfigOptions) (int, string, error) {
+	startTime := utils.NowFor(idp.GetTokenServerHost(oidcTokenProviderConfig)).Unix()
 	fetchOidcTokenOptions := &idp.FetchOidcTokenOptions{
 		ForceNew: options.ForceNew,
 	}
-	tokenResponse, tokenResponseErr := idp.FetchTokenResponse(oidcTokenProviderConfig, fetchOidcTokenOptions)
+	tokenResponse, tokenResponseErr := idp.FetchTokenResponse(ctx, oidcTokenProviderConfig, fetchOidcTokenOptions)
 	if tokenResponseErr == nil && tokenResponse != nil {
 		oidcToken, oidcTokenErr := FromTokenResponse(startTime, tokenResponse)
 		if oidcTokenErr != nil {
@@ -68,22 +70,22 @@ func fetchContent(oidcTokenProviderConfig *config.OidcTokenProviderConfig, optio
 	return 600, "", tokenResponseErr
 }
 
-func isContentExpiringOrExpired(fetchTokenType FetchOidcTokenType, s *utils.StringWithTime) bool {
+func isContentExpiringOrExpired(serverHost string, fetchTokenType FetchOidcTokenType, s *utils.StringWithTime) bool {
 	oidcToken, err := UnmarshalOidcToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := oidcToken.IsValidAtLeastThreshold(fetchTokenType, 3*time.Minute)
+	valid := oidcToken.IsValidAtLeastThreshold(serverHost, fetchTokenType, 3*time.Minute)
 	idaaslog.Debug.PrintfLn("Check OIDC Token is expiring or expired: %s", !valid)
 	return !valid
 }
 
-func isContentExpired(fetchTokenType FetchOidcTokenType, s *utils.StringWithTime) bool {
+func isContentExpired(serverHost string, fetchTokenType FetchOidcTokenType, s *utils.StringWithTime) bool {
 	oidcToken, err := UnmarshalOidcToken(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := oidcToken.IsValidAtLeastThreshold(fetchTokenType, 1*time.Minute)
+	valid := oidcToken.IsValidAtLeastThreshold(serverHost, fetchTokenType, 1*time.Minute)
 	idaaslog.Debug.PrintfLn("Check OIDC Token is expired: %s", !valid)
 	return !valid
 }
@@ -7,75 +7,72 @@ import (
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 )
 
-func ShowToken(sts any, oidcTokenType oidc.FetchOidcTokenType, stdout, color bool) error {
-	alibabaCloudSts, ok := sts.(*alibaba_cloud.StsToken)
-	if ok {
-		return showStsToken(alibabaCloudSts, stdout, color)
-	}
-	awsStsToken, ok := sts.(*aws.AwsStsToken)
-	if ok {
-		return showAwsStsToken(awsStsToken, stdout, color)
-	}
-	oidcToken, ok := sts.(*oidc.OidcToken)
-	if ok {
-		return showOidcToken(oidcToken, oidcTokenType, stdout, color)
-	}
+func ShowToken(credential cloud_common.Credential, options *cloud_common.FormatOptions, stdout, color bool) error {
+	showDisplayRows(credential.DisplayRows(options), stdout, color)
+	return nil
+}
 
-	return fmt.Errorf("unknown cloud STS token type")
+// ShowProfileToken show token with profile row, used when tokens of multiple profiles are shown
+func ShowProfileToken(profile string, credential cloud_common.Credential, options *cloud_common.FormatOptions,
+	stdout, color bool) error {
+	printRow("Profile", profile, stdout, color)
+	return ShowToken(credential, options, stdout, color)
 }
 
-func showStsToken(alibabaCloudSts *alibaba_cloud.StsToken, stdout, color bool) error {
-	printRow("Access Key ID", alibabaCloudSts.AccessKeyId, stdout, color)
-	printRow("Access Key Secret", alibabaCloudSts.AccessKeySecret, stdout, color)
-	printRow("Security Token", alibabaCloudSts.StsToken, stdout, color)
-	expiration, err := time.Parse(time.RFC3339Nano, alibabaCloudSts.Expiration)
-	if err == nil {
-		printRowExpiration(&expiration, stdout, color)
-	} else {
-		printRow("Expiration", alibabaCloudSts.Expiration, stdout, color)
+func ShowStsTokenChain(stsTokenHops []*alibaba_cloud.StsTokenHop, stdout, color bool) error {
+	for i, stsTokenHop := range stsTokenHops {
+		if i > 0 {
+			printStdio("\n", stdout)
+		}
+		printRow("Role ARN", fmt.Sprintf("%s   [Hop %d/%d]", stsTokenHop.RoleArn, i+1, len(stsTokenHops)), stdout, color)
+		err := ShowToken(stsTokenHop.StsToken, &cloud_common.FormatOptions{}, stdout, color)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func showAwsStsToken(awsStsToken *aws.AwsStsToken, stdout, color bool) error {
-	printRow("Access Key ID", awsStsToken.AccessKeyId, stdout, color)
-	printRow("Secret Access Key", awsStsToken.SecretAccessKey, stdout, color)
-	printRow("Session Token", awsStsToken.SessionToken, stdout, color)
-	printRowExpiration(&awsStsToken.Expiration, stdout, color)
+func ShowAwsStsTokenChain(awsStsTokenHops []*aws.AwsStsTokenHop, stdout, color bool) error {
+	for i, awsStsTokenHop := range awsStsTokenHops {
+		if i > 0 {
+			printStdio("\n", stdout)
+		}
+		printRow("Role ARN", fmt.Sprintf("%s   [Hop %d/%d]", awsStsTokenHop.RoleArn, i+1, len(awsStsTokenHops)), stdout, color)
+		err := ShowToken(awsStsTokenHop.AwsStsToken, &cloud_common.FormatOptions{}, stdout, color)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func showOidcToken(oidcToken *oidc.OidcToken, oidcTokenType oidc.FetchOidcTokenType, stdout, color bool) error {
-	printIdToken := oidcToken.IdToken != "" && oidcTokenType.IsFetchIdToken()
-	printAccessToken := oidcToken.AccessToken != "" && oidcTokenType.IsFetchAccessToken()
-
-	if printIdToken {
-		printRow("ID Token", oidcToken.IdToken, stdout, color)
-		idTokenPayload, err := oidc.ParseIdTokenPayload(oidcToken.IdToken)
-		if err == nil {
-			expiresAt := time.Unix(idTokenPayload.Exp, 0)
-			printRowExpiration(&expiresAt, stdout, color)
+func showDisplayRows(rows []*cloud_common.DisplayRow, stdout, color bool) {
+	for _, row := range rows {
+		if row.Separator {
+			printStdio("\n", stdout)
+		} else if row.Expiration != nil {
+			printRowExpiration(row.Expiration, stdout, color)
+		} else {
+			printRow(row.Header, row.Value, stdout, color)
 		}
 	}
-	if printIdToken && printAccessToken {
-		printStdio("\n", stdout)
-	}
-	if printAccessToken {
-		printRow("Access Token Type", oidcToken.TokenType, stdout, color)
-		printRow("Access Token", oidcToken.AccessToken, stdout, color)
-		if oidcToken.ExpiresAt > 0 {
-			expiresAt := time.Unix(oidcToken.ExpiresAt, 0)
-			printRowExpiration(&expiresAt, stdout, color)
-		}
+}
+
+func ShowCallerIdentity(callerIdentity *cloud_common.CallerIdentity, stdout, color bool) {
+	printRow("Account", callerIdentity.Account, stdout, color)
+	printRow("ARN", callerIdentity.Arn, stdout, color)
+	printRow("Principal", callerIdentity.PrincipalId, stdout, color)
+	if callerIdentity.IdentityType != "" {
+		printRow("Identity Type", callerIdentity.IdentityType, stdout, color)
 	}
-	if oidcToken.RefreshToken != "" {
-		printRow("Refresh Token", oidcToken.RefreshToken, stdout, color)
+	if callerIdentity.SessionName != "" {
+		printRow("Session Name", callerIdentity.SessionName, stdout, color)
 	}
-	return nil
 }
 
 func printRowExpiration(expiration *time.Time, stdout, color bool) {
@@ -0,0 +1,250 @@
+package configure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	boolFlagYes = &cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "Remove without confirmation",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "configure",
+		Usage: "Create and edit profiles interactively",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "init",
+				Usage: "Create config file, and add the first profile",
+				Action: func(context *cli.Context) error {
+					return initConfig()
+				},
+			},
+			{
+				Name:      "add-profile",
+				Usage:     "Add profile",
+				ArgsUsage: "[profile]",
+				Action: func(context *cli.Context) error {
+					return addProfile(newPrompter(isColor()), context.Args().First())
+				},
+			},
+			{
+				Name:      "edit",
+				Usage:     "Edit profile, current values are defaults, fields not prompted are preserved",
+				ArgsUsage: "[profile, default current profile]",
+				Action: func(context *cli.Context) error {
+					return editProfile(context.Args().First())
+				},
+			},
+			{
+				Name:      "remove",
+				Usage:     "Remove profile, and remove it from groups",
+				ArgsUsage: "<profile>",
+				Flags:     []cli.Flag{boolFlagYes},
+				Action: func(context *cli.Context) error {
+					return removeProfile(context.Args().First(), context.Bool("yes"))
+				},
+			},
+			{
+				Name:      "rename",
+				Usage:     "Rename profile, groups and current profile are updated",
+				ArgsUsage: "<profile> <new-profile>",
+				Action: func(context *cli.Context) error {
+					return renameProfile(context.Args().Get(0), context.Args().Get(1))
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "Set current profile",
+				ArgsUsage: "<profile>",
+				Action: func(context *cli.Context) error {
+					return useProfile(context.Args().First())
+				},
+			},
+		},
+	}
+}
+
+func initConfig() error {
+	configFilename, err := config.GetDefaultCloudCredentialConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configFilename); err == nil {
+		return fmt.Errorf("config file: %s already exists, use `configure add-profile` or `configure edit`",
+			configFilename)
+	}
+	if err := config.CreateCloudCredentialConfig(configFilename); err != nil {
+		return err
+	}
+	utils.Stderr.Fprintf("Config file created: %s\n", configFilename)
+	// stdin is buffered by prompter, the same prompter is used to add profile
+	prompter := newPrompter(isColor())
+	addNow, err := prompter.confirm("Add a profile now", true)
+	if err != nil || !addNow {
+		return err
+	}
+	return addProfile(prompter, "")
+}
+
+func addProfile(prompter *prompter, profile string) error {
+	document, err := loadConfigDocument()
+	if err != nil {
+		return err
+	}
+	if profile == "" {
+		profile, err = prompter.ask("Profile name", "", false, validateProfileName)
+		if err != nil {
+			return err
+		}
+	} else if err := validateProfileName(profile); err != nil {
+		return err
+	}
+	if _, ok := document.GetProfile(profile); ok {
+		return fmt.Errorf("profile: %s already exists, use `configure edit %s`", profile, profile)
+	}
+	wizard := &profileWizard{
+		prompter: prompter,
+		profile:  map[string]any{},
+	}
+	if err := wizard.run(context.Background()); err != nil {
+		return err
+	}
+	if err := document.SetProfile(profile, wizard.profile); err != nil {
+		return err
+	}
+	if document.CurrentProfile() == "" {
+		useNow, err := prompter.confirm("Use as current profile", true)
+		if err != nil {
+			return err
+		}
+		if useNow {
+			_ = document.UseProfile(profile)
+		}
+	}
+	return saveConfigDocument(document, fmt.Sprintf("Profile: %s added", profile))
+}
+
+func editProfile(profile string) error {
+	document, err := loadConfigDocument()
+	if err != nil {
+		return err
+	}
+	if profile == "" {
+		profile = document.CurrentProfile()
+		if profile == "" {
+			return fmt.Errorf("profile is required, no current profile")
+		}
+	}
+	profileDocument, ok := document.GetProfile(profile)
+	if !ok {
+		return fmt.Errorf("profile: %s not found", profile)
+	}
+	utils.Stderr.Fprintf("Edit profile: %s, press enter to keep current value\n", profile)
+	wizard := &profileWizard{
+		prompter: newPrompter(isColor()),
+		profile:  profileDocument,
+	}
+	if err := wizard.run(context.Background()); err != nil {
+		return err
+	}
+	if err := document.SetProfile(profile, wizard.profile); err != nil {
+		return err
+	}
+	return saveConfigDocument(document, fmt.Sprintf("Profile: %s saved", profile))
+}
+
+func removeProfile(profile string, yes bool) error {
+	if profile == "" {
+		return fmt.Errorf("profile is required")
+	}
+	document, err := loadConfigDocument()
+	if err != nil {
+		return err
+	}
+	if !yes {
+		if _, ok := document.GetProfile(profile); ok {
+			confirmed, err := newPrompter(isColor()).confirm(fmt.Sprintf("Remove profile: %s", profile), false)
+			if err != nil || !confirmed {
+				return err
+			}
+		}
+	}
+	if err := document.RemoveProfile(profile); err != nil {
+		return err
+	}
+	return saveConfigDocument(document, fmt.Sprintf("Profile: %s removed", profile))
+}
+
+func renameProfile(profile, newProfile string) error {
+	if profile == "" || newProfile == "" {
+		return fmt.Errorf("profile and new profile are required")
+	}
+	if err := validateProfileName(newProfile); err != nil {
+		return err
+	}
+	document, err := loadConfigDocument()
+	if err != nil {
+		return err
+	}
+	if err := document.RenameProfile(profile, newProfile); err != nil {
+		return err
+	}
+	return saveConfigDocument(document, fmt.Sprintf("Profile: %s renamed to: %s", profile, newProfile))
+}
+
+func useProfile(profile string) error {
+	if profile == "" {
+		return fmt.Errorf("profile is required")
+	}
+	document, err := loadConfigDocument()
+	if err != nil {
+		return err
+	}
+	if err := document.UseProfile(profile); err != nil {
+		return err
+	}
+	return saveConfigDocument(document, fmt.Sprintf("Current profile: %s", profile))
+}
+
+func loadConfigDocument() (*config.ConfigDocument, error) {
+	configFilename, err := config.GetDefaultCloudCredentialConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadConfigDocument(configFilename)
+}
+
+func saveConfigDocument(document *config.ConfigDocument, message string) error {
+	if err := document.Save(); err != nil {
+		return err
+	}
+	utils.Stderr.Fprintf("%s, config file: %s\n", message, document.Filename)
+	return nil
+}
+
+// validateProfileName profile names are used in cache keys and command lines
+func validateProfileName(profile string) error {
+	if strings.TrimSpace(profile) == "" || strings.ContainsAny(profile, " \t\r\n/\\") {
+		return fmt.Errorf("invalid profile name: %q, spaces and path separators are not allowed", profile)
+	}
+	if strings.HasPrefix(profile, "{") {
+		return fmt.Errorf("invalid profile name: %q, profile starts with { is parsed as inline JSON", profile)
+	}
+	return nil
+}
+
+func isColor() bool {
+	return !utils.IsNonStdErrTerminal
+}
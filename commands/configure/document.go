@@ -0,0 +1,98 @@
+package configure
+
+// getValue returns nil when path is absent
+func getValue(document map[string]any, path ...string) any {
+	var value any = document
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func getString(document map[string]any, path ...string) string {
+	value, _ := getValue(document, path...).(string)
+	return value
+}
+
+func getStringWithDefault(document map[string]any, defaultValue string, path ...string) string {
+	if value := getString(document, path...); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getBool(document map[string]any, path ...string) bool {
+	value, _ := getValue(document, path...).(bool)
+	return value
+}
+
+// setValue creates parent objects when absent
+func setValue(document map[string]any, value any, path ...string) {
+	object := document
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			object[key] = child
+		}
+		object = child
+	}
+	object[path[len(path)-1]] = value
+}
+
+// setString removes value when empty
+func setString(document map[string]any, value string, path ...string) {
+	if value == "" {
+		deleteValue(document, path...)
+		return
+	}
+	setValue(document, value, path...)
+}
+
+// setBool removes value when false
+func setBool(document map[string]any, value bool, path ...string) {
+	if !value {
+		deleteValue(document, path...)
+		return
+	}
+	setValue(document, value, path...)
+}
+
+func deleteValue(document map[string]any, path ...string) {
+	object, ok := getValue(document, path[:len(path)-1]...).(map[string]any)
+	if ok {
+		delete(object, path[len(path)-1])
+	}
+}
+
+func joinPath(path []string, keys ...string) []string {
+	joinedPath := make([]string, 0, len(path)+len(keys))
+	joinedPath = append(joinedPath, path...)
+	return append(joinedPath, keys...)
+}
+
+// containsKey key is in document or nested objects and arrays
+func containsKey(document any, key string) bool {
+	switch value := document.(type) {
+	case map[string]any:
+		if _, ok := value[key]; ok {
+			return true
+		}
+		for _, v := range value {
+			if containsKey(v, key) {
+				return true
+			}
+		}
+	case []any:
+		for _, v := range value {
+			if containsKey(v, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
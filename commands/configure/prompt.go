@@ -0,0 +1,174 @@
+package configure
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"golang.org/x/term"
+)
+
+// clearValue input to clear optional value
+const clearValue = "-"
+
+// prompter reads answers from stdin, prompts are printed to stderr
+type prompter struct {
+	reader *bufio.Reader
+	color  bool
+}
+
+func newPrompter(color bool) *prompter {
+	return &prompter{
+		reader: bufio.NewReader(os.Stdin),
+		color:  color,
+	}
+}
+
+// ask returns default value when input is empty, optional value is cleared by "-",
+// asks again until validate passes
+func (p *prompter) ask(label, defaultValue string, optional bool, validate func(string) error) (string, error) {
+	for {
+		p.printLabel(label, defaultValue, optional)
+		input, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		value := input
+		if value == "" {
+			value = defaultValue
+		} else if optional && value == clearValue {
+			value = ""
+		}
+		if value == "" {
+			if optional {
+				return "", nil
+			}
+			utils.Stderr.Println(utils.Red(label+" is required", p.color))
+			continue
+		}
+		if validate != nil {
+			if err := validate(value); err != nil {
+				utils.Stderr.Println(utils.Red(err.Error(), p.color))
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+// askSecret input is not echoed and current value is not printed, returns current value when input is empty,
+// optional value is cleared by "-"
+func (p *prompter) askSecret(label, currentValue string, optional bool) (string, error) {
+	currentHint := ""
+	if currentValue != "" {
+		currentHint = "keep current"
+	}
+	for {
+		p.printLabel(label, currentHint, optional)
+		input, err := p.readSecret()
+		if err != nil {
+			return "", err
+		}
+		value := input
+		if value == "" {
+			value = currentValue
+		} else if optional && value == clearValue {
+			value = ""
+		}
+		if value == "" && !optional {
+			utils.Stderr.Println(utils.Red(label+" is required", p.color))
+			continue
+		}
+		return value, nil
+	}
+}
+
+// printLabel prints label with hint of value used when input is empty
+func (p *prompter) printLabel(label, valueHint string, optional bool) {
+	hint := ""
+	if valueHint != "" {
+		hint = fmt.Sprintf(" [%s]", valueHint)
+	}
+	if optional {
+		hint += " (optional"
+		if valueHint != "" {
+			hint += ", " + clearValue + " to clear"
+		}
+		hint += ")"
+	}
+	utils.Stderr.Fprintf("%s%s: ", utils.Bold(label, p.color), hint)
+}
+
+// choose option by number or name
+func (p *prompter) choose(label string, options []string, defaultOption string) (string, error) {
+	for i, option := range options {
+		utils.Stderr.Fprintf("  %d) %s\n", i+1, option)
+	}
+	value, err := p.ask(label, defaultOption, false, func(value string) error {
+		if findOption(options, value) == "" {
+			return fmt.Errorf("invalid choice: %s", value)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return findOption(options, value), nil
+}
+
+func (p *prompter) confirm(label string, defaultValue bool) (bool, error) {
+	defaultAnswer := "n"
+	if defaultValue {
+		defaultAnswer = "y"
+	}
+	for {
+		answer, err := p.ask(label+" (y/n)", defaultAnswer, false, nil)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("read input failed: %v", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readSecret reads without echo from terminal, or reads line when stdin is not terminal
+func (p *prompter) readSecret() (string, error) {
+	stdinFd := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdinFd) {
+		return p.readLine()
+	}
+	secret, err := term.ReadPassword(stdinFd)
+	utils.Stderr.Println("")
+	if err != nil {
+		return "", fmt.Errorf("read input failed: %v", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+func findOption(options []string, value string) string {
+	if index, err := strconv.Atoi(value); err == nil && index >= 1 && index <= len(options) {
+		return options[index-1]
+	}
+	for _, option := range options {
+		if option == value {
+			return option
+		}
+	}
+	return ""
+}
@@ -0,0 +1,470 @@
+package configure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	cloudAlibabaCloud = "alibaba_cloud"
+	cloudAws          = "aws"
+	cloudGcp          = "gcp"
+	cloudAzure        = "azure"
+	cloudOidc         = "oidc"
+
+	providerDeviceCode        = "device_code"
+	providerClientCredentials = "client_credentials"
+	providerAccessKey         = "access_key"
+	providerEcsRamRole        = "ecs_ram_role"
+
+	signerClientSecret    = "client_secret"
+	signerKeyFile         = "key_file"
+	signerExternalCommand = "external_command"
+	signerPkcs11          = "pkcs11"
+	signerYubikeyPiv      = "yubikey_piv"
+)
+
+var (
+	clouds = []string{cloudAlibabaCloud, cloudAws, cloudGcp, cloudAzure, cloudOidc}
+	// cloudKeys profile keys of clouds, aws_roles_anywhere is not supported by wizard
+	cloudKeys = map[string]string{
+		cloudAlibabaCloud: "alibaba_cloud_sts",
+		cloudAws:          "aws_sts",
+		cloudGcp:          "gcp_sts",
+		cloudAzure:        "azure_ad",
+		cloudOidc:         "oidc_token",
+	}
+	signers          = []string{signerClientSecret, signerKeyFile, signerExternalCommand, signerPkcs11, signerYubikeyPiv}
+	signerAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+	regexpRegion                 = regexp.MustCompile(`^[a-z0-9-]+$`)
+	regexpAlibabaCloudRoleArn    = regexp.MustCompile(`^acs:ram::\d+:role/.+$`)
+	regexpAlibabaCloudOidcArn    = regexp.MustCompile(`^acs:ram::\d+:oidc-provider/.+$`)
+	regexpAwsRoleArn             = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/.+$`)
+	regexpGcpWorkloadIdentityAud = regexp.MustCompile(`^//iam\.googleapis\.com/projects/\d+/locations/global/workloadIdentityPools/[^/]+/providers/[^/]+$`)
+)
+
+// profileWizard prompts profile fields with current values as defaults, only prompted fields are changed,
+// other fields of profile are preserved
+type profileWizard struct {
+	prompter *prompter
+	profile  map[string]any
+}
+
+func (w *profileWizard) run(ctx context.Context) error {
+	if getValue(w.profile, "aws_roles_anywhere") != nil {
+		return fmt.Errorf("aws_roles_anywhere profile is not supported by configure, edit config file instead")
+	}
+	if getValue(w.profile, "extends") != nil || containsKey(w.profile, "ref") {
+		return fmt.Errorf("profile with extends or ref is not supported by configure, edit config file instead")
+	}
+	cloud, err := w.prompter.choose("Cloud type", clouds, detectCloud(w.profile))
+	if err != nil {
+		return err
+	}
+	for otherCloud, cloudKey := range cloudKeys {
+		if otherCloud != cloud {
+			deleteValue(w.profile, cloudKey)
+		}
+	}
+	cloudKey := cloudKeys[cloud]
+	switch cloud {
+	case cloudAlibabaCloud:
+		err = w.askAlibabaCloud(ctx, cloudKey)
+	case cloudAws:
+		err = w.askAws(ctx, cloudKey)
+	case cloudGcp:
+		err = w.askGcp(ctx, cloudKey)
+	case cloudAzure:
+		err = w.askAzure(ctx, cloudKey)
+	case cloudOidc:
+		err = w.askOidcTokenProvider(ctx, []string{cloudKey}, "")
+	}
+	if err != nil {
+		return err
+	}
+	return w.askString("Comment", false, nil, "comment")
+}
+
+func (w *profileWizard) askAlibabaCloud(ctx context.Context, cloudKey string) error {
+	if err := w.askStringWithDefault("Region", "cn-hangzhou", true, validateRegion, cloudKey, "region"); err != nil {
+		return err
+	}
+	defaultStsEndpoint := fmt.Sprintf("sts.%s.aliyuncs.com", getString(w.profile, cloudKey, "region"))
+	if err := w.askStringWithDefault("STS endpoint", defaultStsEndpoint, true, nil, cloudKey, "sts_endpoint"); err != nil {
+		return err
+	}
+	if err := w.askString("Role ARN", true, validateRegexp(regexpAlibabaCloudRoleArn, "acs:ram::<account>:role/<role>"),
+		cloudKey, "role_arn"); err != nil {
+		return err
+	}
+	providers := []string{providerDeviceCode, providerClientCredentials, providerAccessKey, providerEcsRamRole}
+	defaultProvider := detectOidcTokenProvider(w.profile, cloudKey, "oidc_token_provider")
+	if getValue(w.profile, cloudKey, "credential_source", providerAccessKey) != nil {
+		defaultProvider = providerAccessKey
+	} else if getValue(w.profile, cloudKey, "credential_source", providerEcsRamRole) != nil {
+		defaultProvider = providerEcsRamRole
+	}
+	provider, err := w.prompter.choose("Provider type", providers, defaultProvider)
+	if err != nil {
+		return err
+	}
+	if provider == providerAccessKey || provider == providerEcsRamRole {
+		deleteValue(w.profile, cloudKey, "oidc_provider_arn")
+		deleteValue(w.profile, cloudKey, "oidc_token_provider")
+		return w.askCredentialSource(cloudKey, provider)
+	}
+	deleteValue(w.profile, cloudKey, "credential_source")
+	if err := w.askString("OIDC provider ARN", true,
+		validateRegexp(regexpAlibabaCloudOidcArn, "acs:ram::<account>:oidc-provider/<provider>"),
+		cloudKey, "oidc_provider_arn"); err != nil {
+		return err
+	}
+	return w.askOidcTokenProvider(ctx, []string{cloudKey, "oidc_token_provider"}, provider)
+}
+
+func (w *profileWizard) askCredentialSource(cloudKey, provider string) error {
+	if provider == providerEcsRamRole {
+		deleteValue(w.profile, cloudKey, "credential_source", providerAccessKey)
+		// empty ecs_ram_role fetches role name from metadata
+		if getValue(w.profile, cloudKey, "credential_source", providerEcsRamRole) == nil {
+			setValue(w.profile, map[string]any{}, cloudKey, "credential_source", providerEcsRamRole)
+		}
+		return w.askString("ECS RAM role name", false, nil, cloudKey, "credential_source", providerEcsRamRole, "role_name")
+	}
+	deleteValue(w.profile, cloudKey, "credential_source", providerEcsRamRole)
+	accessKeyPath := []string{cloudKey, "credential_source", providerAccessKey}
+	if err := w.askString("AccessKey ID", true, nil, joinPath(accessKeyPath, "access_key_id")...); err != nil {
+		return err
+	}
+	secretSources := []string{"access_key_secret_env", "access_key_secret_file", "access_key_secret_encrypted", "access_key_secret"}
+	defaultSecretSource := secretSources[0]
+	for _, secretSource := range secretSources {
+		if getValue(w.profile, joinPath(accessKeyPath, secretSource)...) != nil {
+			defaultSecretSource = secretSource
+			break
+		}
+	}
+	secretSource, err := w.prompter.choose("AccessKey secret source", secretSources, defaultSecretSource)
+	if err != nil {
+		return err
+	}
+	for _, otherSecretSource := range secretSources {
+		if otherSecretSource != secretSource {
+			deleteValue(w.profile, joinPath(accessKeyPath, otherSecretSource)...)
+		}
+	}
+	labels := map[string]string{
+		"access_key_secret_env":  "AccessKey secret environment variable",
+		"access_key_secret_file": "AccessKey secret file",
+		"access_key_secret":      "AccessKey secret",
+	}
+	if secretSource == "access_key_secret_encrypted" {
+		return w.askEncryptedAccessKeySecret(accessKeyPath)
+	}
+	if secretSource == "access_key_secret" {
+		return w.askSecret(labels[secretSource], true, joinPath(accessKeyPath, secretSource)...)
+	}
+	return w.askString(labels[secretSource], true, nil, joinPath(accessKeyPath, secretSource)...)
+}
+
+// askEncryptedAccessKeySecret ask plain AccessKey secret and save encrypted, keep current when input is empty
+func (w *profileWizard) askEncryptedAccessKeySecret(accessKeyPath []string) error {
+	secretPath := joinPath(accessKeyPath, "access_key_secret_encrypted")
+	accessKeyId := getStringWithDefault(w.profile, "", joinPath(accessKeyPath, "access_key_id")...)
+	currentAccessKeySecretEncrypted := getStringWithDefault(w.profile, "", secretPath...)
+	label := "AccessKey secret (saved encrypted, can only be decrypted on this machine)"
+	if currentAccessKeySecretEncrypted != "" {
+		label += ", empty keeps current"
+	}
+	accessKeySecret, err := w.prompter.askSecret(label, "", currentAccessKeySecretEncrypted != "")
+	if err != nil {
+		return err
+	}
+	if accessKeySecret == "" {
+		// encrypted secret is bound to AccessKey ID
+		if _, err := alibaba_cloud.DecryptAccessKeySecret(accessKeyId, currentAccessKeySecretEncrypted); err != nil {
+			return fmt.Errorf("current AccessKey secret cannot be decrypted, input AccessKey secret again: %v",
+				errors.Cause(err))
+		}
+		return nil
+	}
+	accessKeySecretEncrypted, err := alibaba_cloud.EncryptAccessKeySecret(accessKeyId, accessKeySecret)
+	if err != nil {
+		return fmt.Errorf("encrypt AccessKey secret failed: %v", errors.Cause(err))
+	}
+	setString(w.profile, accessKeySecretEncrypted, secretPath...)
+	return nil
+}
+
+func (w *profileWizard) askAws(ctx context.Context, cloudKey string) error {
+	if err := w.askStringWithDefault("Region", "us-east-1", true, validateRegion, cloudKey, "region"); err != nil {
+		return err
+	}
+	if err := w.askString("Role ARN", true, validateRegexp(regexpAwsRoleArn, "arn:aws:iam::<account>:role/<role>"),
+		cloudKey, "role_arn"); err != nil {
+		return err
+	}
+	return w.askOidcTokenProvider(ctx, []string{cloudKey, "oidc_token_provider"}, "")
+}
+
+func (w *profileWizard) askGcp(ctx context.Context, cloudKey string) error {
+	if err := w.askString("Workload identity provider audience", true,
+		validateRegexp(regexpGcpWorkloadIdentityAud,
+			"//iam.googleapis.com/projects/<number>/locations/global/workloadIdentityPools/<pool>/providers/<provider>"),
+		cloudKey, "audience"); err != nil {
+		return err
+	}
+	if err := w.askString("Service account email", false, nil, cloudKey, "service_account_email"); err != nil {
+		return err
+	}
+	return w.askOidcTokenProvider(ctx, []string{cloudKey, "oidc_token_provider"}, "")
+}
+
+func (w *profileWizard) askAzure(ctx context.Context, cloudKey string) error {
+	if err := w.askString("Tenant ID", true, nil, cloudKey, "tenant_id"); err != nil {
+		return err
+	}
+	if err := w.askString("Client ID", true, nil, cloudKey, "client_id"); err != nil {
+		return err
+	}
+	return w.askOidcTokenProvider(ctx, []string{cloudKey, "oidc_token_provider"}, "")
+}
+
+// askOidcTokenProvider prompts provider type when provider is empty
+func (w *profileWizard) askOidcTokenProvider(ctx context.Context, providerPath []string, provider string) error {
+	if provider == "" {
+		var err error
+		provider, err = w.prompter.choose("Provider type", []string{providerDeviceCode, providerClientCredentials},
+			detectOidcTokenProvider(w.profile, providerPath...))
+		if err != nil {
+			return err
+		}
+	}
+	if provider == providerDeviceCode {
+		deleteValue(w.profile, joinPath(providerPath, providerClientCredentials)...)
+		return w.askDeviceCode(ctx, joinPath(providerPath, providerDeviceCode))
+	}
+	deleteValue(w.profile, joinPath(providerPath, providerDeviceCode)...)
+	return w.askClientCredentials(ctx, joinPath(providerPath, providerClientCredentials))
+}
+
+func (w *profileWizard) askDeviceCode(ctx context.Context, deviceCodePath []string) error {
+	if err := w.askString("Issuer", true, validateUrl, joinPath(deviceCodePath, "issuer")...); err != nil {
+		return err
+	}
+	issuer := getString(w.profile, joinPath(deviceCodePath, "issuer")...)
+	if openIdConfiguration := w.discover(ctx, issuer); openIdConfiguration != nil {
+		if openIdConfiguration.DeviceAuthorizationEndpoint == "" {
+			utils.Stderr.Println(utils.Yellow("Issuer does not declare device_authorization_endpoint", w.prompter.color))
+		} else {
+			utils.Stderr.Fprintf("Device authorization endpoint: %s\n", openIdConfiguration.DeviceAuthorizationEndpoint)
+		}
+	}
+	if err := w.askString("Client ID", true, nil, joinPath(deviceCodePath, "client_id")...); err != nil {
+		return err
+	}
+	if err := w.askSecret("Client secret, empty for public client", false,
+		joinPath(deviceCodePath, "client_secret")...); err != nil {
+		return err
+	}
+	if err := w.askString("Scope", false, nil, joinPath(deviceCodePath, "scope")...); err != nil {
+		return err
+	}
+	autoOpenUrl, err := w.prompter.confirm("Auto open URL in browser",
+		getBool(w.profile, joinPath(deviceCodePath, "auto_open_url")...))
+	if err != nil {
+		return err
+	}
+	setBool(w.profile, autoOpenUrl, joinPath(deviceCodePath, "auto_open_url")...)
+	return nil
+}
+
+func (w *profileWizard) askClientCredentials(ctx context.Context, clientCredentialsPath []string) error {
+	issuer, err := w.prompter.ask("Issuer, for OIDC discovery", "", true, validateUrl)
+	if err != nil {
+		return err
+	}
+	tokenEndpoint := getString(w.profile, joinPath(clientCredentialsPath, "token_endpoint")...)
+	if issuer != "" {
+		if openIdConfiguration := w.discover(ctx, issuer); openIdConfiguration != nil &&
+			openIdConfiguration.TokenEndpoint != "" {
+			tokenEndpoint = openIdConfiguration.TokenEndpoint
+		}
+	}
+	if err := w.askStringWithDefault("Token endpoint", tokenEndpoint, true, validateUrl,
+		joinPath(clientCredentialsPath, "token_endpoint")...); err != nil {
+		return err
+	}
+	if err := w.askString("Client ID", true, nil, joinPath(clientCredentialsPath, "client_id")...); err != nil {
+		return err
+	}
+	if err := w.askString("Scope", false, nil, joinPath(clientCredentialsPath, "scope")...); err != nil {
+		return err
+	}
+
+	signerPath := joinPath(clientCredentialsPath, "client_assertion_singer")
+	defaultSigner := signerClientSecret
+	for _, signer := range signers[1:] {
+		if getValue(w.profile, joinPath(signerPath, signer)...) != nil {
+			defaultSigner = signer
+		}
+	}
+	signer, err := w.prompter.choose("Signer type", signers, defaultSigner)
+	if err != nil {
+		return err
+	}
+	if signer == signerClientSecret {
+		deleteValue(w.profile, signerPath...)
+		return w.askSecret("Client secret", true, joinPath(clientCredentialsPath, "client_secret")...)
+	}
+	deleteValue(w.profile, joinPath(clientCredentialsPath, "client_secret")...)
+	for _, otherSigner := range signers[1:] {
+		if otherSigner != signer {
+			deleteValue(w.profile, joinPath(signerPath, otherSigner)...)
+		}
+	}
+	return w.askSigner(signerPath, signer)
+}
+
+func (w *profileWizard) askSigner(signerPath []string, signer string) error {
+	algorithm, err := w.prompter.choose("Algorithm", signerAlgorithms,
+		getStringWithDefault(w.profile, "RS256", joinPath(signerPath, "algorithm")...))
+	if err != nil {
+		return err
+	}
+	setString(w.profile, algorithm, joinPath(signerPath, "algorithm")...)
+	if err := w.askString("Key ID", false, nil, joinPath(signerPath, "key_id")...); err != nil {
+		return err
+	}
+	type signerField struct {
+		label    string
+		key      string
+		required bool
+		secret   bool
+	}
+	var signerFields []signerField
+	switch signer {
+	case signerKeyFile:
+		signerFields = []signerField{{"Key file", "file", true, false}, {"Key password", "password", false, true}}
+	case signerExternalCommand:
+		signerFields = []signerField{{"Command", "command", true, false}, {"Parameter", "parameter", true, false}}
+	case signerPkcs11:
+		signerFields = []signerField{{"Library path", "library_path", true, false},
+			{"Token label", "token_label", true, false}, {"Key label", "key_label", true, false},
+			{"PIN, or set env " + constants.EnvPkcs11Pin, "pin", false, true}}
+	case signerYubikeyPiv:
+		signerFields = []signerField{{"Slot, auth, sign or rN", "slot", true, false}}
+	}
+	for _, field := range signerFields {
+		fieldPath := joinPath(signerPath, signer, field.key)
+		var err error
+		if field.secret {
+			err = w.askSecret(field.label, field.required, fieldPath...)
+		} else {
+			err = w.askString(field.label, field.required, nil, fieldPath...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if signer == signerYubikeyPiv {
+		pinPolicy, err := w.prompter.choose("PIN policy", []string{"never", "once", "always"},
+			getStringWithDefault(w.profile, "once", joinPath(signerPath, signer, "pin_policy")...))
+		if err != nil {
+			return err
+		}
+		setString(w.profile, pinPolicy, joinPath(signerPath, signer, "pin_policy")...)
+		return w.askSecret("PIN, or set env "+constants.EnvYubiKeyPin, false, joinPath(signerPath, signer, "pin")...)
+	}
+	return nil
+}
+
+// discover fetch OpenID configuration to pre-fill values, failures are printed and ignored
+func (w *profileWizard) discover(ctx context.Context, issuer string) *oidc.OpenIdConfiguration {
+	openIdConfiguration, err := oidc.FetchOpenIdConfiguration(ctx, strings.TrimSuffix(issuer, "/"),
+		&oidc.FetchOpenIdConfigurationOptions{})
+	if err != nil {
+		utils.Stderr.Println(utils.Yellow(fmt.Sprintf("OIDC discovery failed: %v", err), w.prompter.color))
+		return nil
+	}
+	return openIdConfiguration
+}
+
+func (w *profileWizard) askString(label string, required bool, validate func(string) error, path ...string) error {
+	return w.askStringWithDefault(label, "", required, validate, path...)
+}
+
+// askSecret current value is kept when input is empty, input and current value are not printed
+func (w *profileWizard) askSecret(label string, required bool, path ...string) error {
+	value, err := w.prompter.askSecret(label, getStringWithDefault(w.profile, "", path...), !required)
+	if err != nil {
+		return err
+	}
+	setString(w.profile, value, path...)
+	return nil
+}
+
+// askStringWithDefault current value is default, or defaultValue when absent
+func (w *profileWizard) askStringWithDefault(label, defaultValue string, required bool, validate func(string) error,
+	path ...string) error {
+	value, err := w.prompter.ask(label, getStringWithDefault(w.profile, defaultValue, path...), !required, validate)
+	if err != nil {
+		return err
+	}
+	setString(w.profile, value, path...)
+	return nil
+}
+
+func detectCloud(profile map[string]any) string {
+	for _, cloud := range clouds {
+		if getValue(profile, cloudKeys[cloud]) != nil {
+			return cloud
+		}
+	}
+	return cloudAlibabaCloud
+}
+
+func detectOidcTokenProvider(profile map[string]any, providerPath ...string) string {
+	if getValue(profile, joinPath(providerPath, providerClientCredentials)...) != nil {
+		return providerClientCredentials
+	}
+	return providerDeviceCode
+}
+
+func validateRegion(value string) error {
+	return validateRegexp(regexpRegion, "e.g. cn-hangzhou")(value)
+}
+
+func validateRegexp(pattern *regexp.Regexp, format string) func(string) error {
+	return func(value string) error {
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("invalid value: %s, format: %s", value, format)
+		}
+		return nil
+	}
+}
+
+// validateUrl requires https, http is allowed for localhost
+func validateUrl(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid URL: %s", value)
+	}
+	if u.Scheme == "https" {
+		return nil
+	}
+	if u.Scheme == "http" && (u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1") {
+		return nil
+	}
+	return fmt.Errorf("invalid URL: %s, https is required", value)
+}
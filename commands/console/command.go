@@ -0,0 +1,151 @@
+package console
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/qr"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagDestination = &cli.StringFlag{
+		Name:    "destination",
+		Aliases: []string{"d"},
+		Usage:   "Console destination, full URL or path",
+	}
+	stringFlagSigninEndpoint = &cli.StringFlag{
+		Name:  "signin-endpoint",
+		Usage: "Override federation signin endpoint, e.g. https://signin.alibabacloud.com/federation",
+	}
+	intFlagSessionDuration = &cli.IntFlag{
+		Name:  "session-duration",
+		Usage: "AWS console session duration seconds, not allowed for role chaining",
+	}
+	boolFlagNoOpen = &cli.BoolFlag{
+		Name:  "no-open",
+		Usage: "Do not open URL in browser",
+	}
+	boolFlagQrCode = &cli.BoolFlag{
+		Name:  "qr",
+		Usage: "Show URL QR code",
+	}
+	boolFlagSmallQrCode = &cli.BoolFlag{
+		Name:  "small-qr",
+		Usage: "Show small URL QR code",
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch cloud STS token, ignore cache (including OpenId configuration etc.)",
+	}
+)
+
+type consoleOptions struct {
+	destination     string
+	signinEndpoint  string
+	sessionDuration int32
+	noOpen          bool
+	qrCode          bool
+	smallQrCode     bool
+	forceNew        bool
+}
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagDestination,
+		stringFlagSigninEndpoint,
+		intFlagSessionDuration,
+		boolFlagNoOpen,
+		boolFlagQrCode,
+		boolFlagSmallQrCode,
+		boolFlagForceNew,
+	}
+	return &cli.Command{
+		Name:  "console",
+		Usage: "Sign in cloud console with STS token",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			options := &consoleOptions{
+				destination:     context.String("destination"),
+				signinEndpoint:  context.String("signin-endpoint"),
+				sessionDuration: int32(context.Int("session-duration")),
+				noOpen:          context.Bool("no-open"),
+				qrCode:          context.Bool("qr"),
+				smallQrCode:     context.Bool("small-qr"),
+				forceNew:        context.Bool("force-new"),
+			}
+			return signinConsole(profile, options)
+		},
+	}
+}
+
+func signinConsole(profile string, options *consoleOptions) error {
+	ctx := context.Background()
+	fetchOptions := &cloud.FetchCloudStsOptions{
+		ForceNew: options.forceNew,
+	}
+	credential, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(ctx, profile, fetchOptions)
+	if err != nil {
+		return err
+	}
+
+	var signinUrl string
+	if alibabaCloudSts, ok := credential.(*alibaba_cloud.StsToken); ok {
+		signinUrl, err = alibabaCloudSts.BuildConsoleSigninUrl(ctx, &alibaba_cloud.BuildConsoleSigninUrlOptions{
+			SigninEndpoint: options.signinEndpoint,
+			Destination:    options.destination,
+		})
+	} else if awsStsToken, ok := credential.(*aws.AwsStsToken); ok {
+		signinUrl, err = awsStsToken.BuildConsoleSigninUrl(ctx, &aws.BuildConsoleSigninUrlOptions{
+			Region:          getAwsRegion(cloudStsConfig),
+			SigninEndpoint:  options.signinEndpoint,
+			Destination:     options.destination,
+			SessionDuration: options.sessionDuration,
+		})
+	} else {
+		return fmt.Errorf("console only supports Alibaba Cloud and AWS STS token")
+	}
+	if err != nil {
+		return err
+	}
+
+	utils.Stdout.Println(signinUrl)
+	if options.qrCode || options.smallQrCode {
+		err = qr.PrintQrCode(signinUrl, options.smallQrCode)
+		if err != nil {
+			return err
+		}
+	}
+	if !options.noOpen {
+		err = utils.OpenUrl(signinUrl)
+		if err != nil {
+			idaaslog.Warn.PrintfLn("Open URL failed: %v", err)
+			utils.Stderr.Fprintf("Open URL failed: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func getAwsRegion(cloudStsConfig *config.CloudStsConfig) string {
+	if cloudStsConfig.Aws != nil {
+		return cloudStsConfig.Aws.Region
+	}
+	if cloudStsConfig.AwsRolesAnywhere != nil {
+		return cloudStsConfig.AwsRolesAnywhere.Region
+	}
+	return ""
+}
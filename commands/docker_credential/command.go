@@ -0,0 +1,158 @@
+package docker_credential
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// HelperName binary (or symbolic link) name for docker credential helper mode
+	// reference: https://github.com/docker/docker-credential-helpers
+	HelperName = "docker-credential-alibaba-cloud-idaas"
+
+	ActionGet   = "get"
+	ActionStore = "store"
+	ActionErase = "erase"
+
+	// ErrCredentialsNotFound docker treats this message as no credentials
+	ErrCredentialsNotFound = "credentials not found in native keychain"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile, default find profile by registry (alibaba_cloud_sts.acr.registries)",
+		EnvVars: []string{constants.EnvAcrProfile},
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch ACR token, ignore cache",
+	}
+)
+
+// DockerCredential docker credential helper JSON
+type DockerCredential struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		boolFlagForceNew,
+	}
+	return &cli.Command{
+		Name:      "docker-credential",
+		Usage:     "Docker credential helper for Alibaba Cloud Container Registry(ACR)",
+		ArgsUsage: "get|store|erase",
+		Flags:     flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			forceNew := context.Bool("force-new")
+			action := context.Args().First()
+			return dockerCredential(action, profile, forceNew)
+		},
+	}
+}
+
+func dockerCredential(action, profile string, forceNew bool) error {
+	// check action first, stdin is not read for unknown action, e.g. blocks when run from terminal
+	if action != ActionGet && action != ActionStore && action != ActionErase {
+		return fmt.Errorf("unknown action: %s, supports get, store or erase", action)
+	}
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return errors.Wrap(err, "read stdin failed")
+	}
+	switch action {
+	case ActionGet:
+		return getCredential(normalizeRegistry(string(input)), profile, forceNew)
+	case ActionErase:
+		return eraseCredential(normalizeRegistry(string(input)), profile)
+	}
+	// ACR tokens are fetched on demand, nothing to store
+	idaaslog.Info.PrintfLn("Docker credential store ignored")
+	return nil
+}
+
+func getCredential(registry, profile string, forceNew bool) error {
+	profile, cloudStsConfig, err := findProfile(registry, profile)
+	if err != nil {
+		idaaslog.Warn.PrintfLn("Find profile for registry: %s failed: %v", registry, err)
+		utils.Stdout.Println(ErrCredentialsNotFound)
+		return err
+	}
+	acrToken, err := alibaba_cloud.FetchAcrToken(context.Background(), profile, cloudStsConfig.AlibabaCloud, registry,
+		&alibaba_cloud.FetchAcrTokenOptions{
+			ForceNew: forceNew,
+		})
+	if err != nil {
+		return err
+	}
+	dockerCredential := &DockerCredential{
+		ServerURL: registry,
+		Username:  acrToken.Username,
+		Secret:    acrToken.Password,
+	}
+	dockerCredentialBytes, err := json.Marshal(dockerCredential)
+	if err != nil {
+		return errors.Wrap(err, "marshal docker credential failed")
+	}
+	utils.Stdout.Println(string(dockerCredentialBytes))
+	return nil
+}
+
+func eraseCredential(registry, profile string) error {
+	profile, cloudStsConfig, err := findProfile(registry, profile)
+	if err != nil {
+		// nothing to erase
+		idaaslog.Warn.PrintfLn("Find profile for registry: %s failed: %v", registry, err)
+		return nil
+	}
+	return alibaba_cloud.RemoveAcrToken(profile, cloudStsConfig.AlibabaCloud, registry)
+}
+
+func findProfile(registry, profile string) (string, *config.CloudStsConfig, error) {
+	if registry == "" {
+		return "", nil, errors.New("registry is required from stdin")
+	}
+	var cloudStsConfig *config.CloudStsConfig
+	var err error
+	if profile != "" {
+		profile, cloudStsConfig, err = config.FindProfile(profile)
+	} else {
+		profile, cloudStsConfig, err = config.FindProfileByAcrRegistry(registry)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if cloudStsConfig.AlibabaCloud == nil || cloudStsConfig.AlibabaCloud.Acr == nil {
+		return "", nil, fmt.Errorf("profile: %s has no alibaba_cloud_sts.acr", profile)
+	}
+	return profile, cloudStsConfig, nil
+}
+
+// normalizeRegistry registry hostname from server URL, e.g. https://registry.cn-hangzhou.aliyuncs.com/v2/
+func normalizeRegistry(serverUrl string) string {
+	registry := strings.TrimSpace(serverUrl)
+	if _, host, found := strings.Cut(registry, "://"); found {
+		registry = host
+	}
+	registry, _, _ = strings.Cut(registry, "/")
+	return strings.ToLower(registry)
+}
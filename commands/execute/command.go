@@ -1,31 +1,45 @@
 package execute
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+	"text/template"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idp"
 	"github.com/urfave/cli/v2"
 )
 
 var (
-	stringFlagProfile = &cli.StringFlag{
+	stringSliceFlagProfile = &cli.StringSliceFlag{
 		Name:    "profile",
 		Aliases: []string{"p"},
-		Usage:   "IDaaS Profile",
+		Usage:   "IDaaS Profile, multiple profiles are fetched concurrently, e.g. -p aliyun1 -p aws1",
 	}
-	stringFlagEnvRegion = &cli.StringFlag{
+	stringFlagGroup = &cli.StringFlag{
+		Name:    "group",
+		Aliases: []string{"g"},
+		Usage:   "Execute command once per profile of group (groups or tags in config), output is prefixed with profile",
+	}
+	intFlagParallel = &cli.IntFlag{
+		Name:  "parallel",
+		Value: 4,
+		Usage: "Max profiles executed in parallel with --group",
+	}
+	stringSliceFlagEnvRegion = &cli.StringSliceFlag{
 		Name:    "env-region",
 		Aliases: []string{"R"},
-		Usage:   "Set environment region",
+		Usage:   "Set environment region, `region` for all profiles or `profile=region` for one profile",
+	}
+	stringFlagPolicyFile = &cli.StringFlag{
+		Name:  "policy-file",
+		Usage: "Override Alibaba Cloud STS session policy with policy JSON file",
 	}
 	boolFlagForceNew = &cli.BoolFlag{
 		Name:    "force-new",
@@ -36,14 +50,65 @@ var (
 		Name:  "show-token",
 		Usage: "Show cloud STS token",
 	}
+	boolFlagRefreshing = &cli.BoolFlag{
+		Name:  "refreshing",
+		Usage: "Serve refreshing credentials via local endpoint (Alibaba Cloud and AWS), for long-running command",
+	}
+	boolFlagCleanEnv = &cli.BoolFlag{
+		Name:  "clean-env",
+		Usage: "Unset inherited credential environments of all clouds before set profile's environments",
+	}
+	boolFlagUnsetConflicting = &cli.BoolFlag{
+		Name:  "unset-conflicting",
+		Usage: "Unset inherited credential environments of profile's clouds before set profile's environments",
+	}
+	boolFlagCredentialFiles = &cli.BoolFlag{
+		Name:  "credential-files",
+		Usage: "Render credential files for tools which read files only (Alibaba Cloud and AWS), removed after exits",
+	}
+	boolFlagOidcTokenFile = &cli.BoolFlag{
+		Name:  "oidc-token-file",
+		Usage: "Keep OIDC token file up to date for SDKs assume role with OIDC themselves (Alibaba Cloud only)",
+	}
+	boolFlagExecReplace = &cli.BoolFlag{
+		Name:  "exec-replace",
+		Usage: "Replace current process with command (Unix only), not supported with --refreshing or credential files",
+	}
 )
 
+type executeOptions struct {
+	Profiles   []string
+	Group      string
+	Parallel   int
+	EnvRegions []string
+	PolicyFile string
+	ForceNew   bool
+	ShowToken  bool
+	Refreshing bool
+	// CleanEnv unset inherited credential environments of all clouds
+	CleanEnv bool
+	// UnsetConflicting unset inherited credential environments of profiles' clouds
+	UnsetConflicting bool
+	CredentialFiles  bool
+	OidcTokenFile    bool
+	ExecReplace      bool
+}
+
 func BuildCommand() *cli.Command {
 	flags := []cli.Flag{
-		stringFlagProfile,
-		stringFlagEnvRegion,
+		stringSliceFlagProfile,
+		stringFlagGroup,
+		intFlagParallel,
+		stringSliceFlagEnvRegion,
+		stringFlagPolicyFile,
 		boolFlagForceNew,
 		boolFlagShowToken,
+		boolFlagRefreshing,
+		boolFlagCleanEnv,
+		boolFlagUnsetConflicting,
+		boolFlagCredentialFiles,
+		boolFlagOidcTokenFile,
+		boolFlagExecReplace,
 	}
 	return &cli.Command{
 		Name:    "execute",
@@ -51,124 +116,168 @@ func BuildCommand() *cli.Command {
 		Usage:   "Execute command",
 		Flags:   flags,
 		Action: func(context *cli.Context) error {
-			profile := context.String("profile")
-			envRegion := context.String("env-region")
-			forceNew := context.Bool("force-new")
-			showToken := context.Bool("show-token")
+			options := &executeOptions{
+				Profiles:   context.StringSlice("profile"),
+				Group:      context.String("group"),
+				Parallel:   context.Int("parallel"),
+				EnvRegions: context.StringSlice("env-region"),
+				PolicyFile: context.String("policy-file"),
+				ForceNew:   context.Bool("force-new"),
+				ShowToken:  context.Bool("show-token"),
+				Refreshing: context.Bool("refreshing"),
+
+				CleanEnv:         context.Bool("clean-env"),
+				UnsetConflicting: context.Bool("unset-conflicting"),
+				CredentialFiles:  context.Bool("credential-files"),
+				OidcTokenFile:    context.Bool("oidc-token-file"),
+				ExecReplace:      context.Bool("exec-replace"),
+			}
 			args := context.Args()
-			return execute(profile, showToken, forceNew, envRegion, args.Slice())
+			return execute(options, args.Slice())
 		},
 	}
 }
 
-func execute(profile string, showToken, forceNew bool, envRegion string, args []string) error {
-	options := &cloud.FetchCloudStsOptions{
-		ForceNew: forceNew,
+func execute(options *executeOptions, args []string) error {
+	ctx := idp.WithForceNewSession(context.Background())
+	if options.OidcTokenFile {
+		return executeOidcTokenFile(ctx, options, args)
+	}
+	if options.ExecReplace && options.Refreshing {
+		return fmt.Errorf("--exec-replace is not supported with --refreshing, credential endpoint stops when replaced")
+	}
+	if options.CredentialFiles && options.Refreshing {
+		return fmt.Errorf("--credential-files is not supported with --refreshing, credential files are not refreshed")
+	}
+	if options.Group != "" {
+		return executeGroup(ctx, options, args)
 	}
-	sts, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(profile, options)
+	profiles := options.Profiles
+	if len(profiles) == 0 {
+		// default profile
+		profiles = []string{""}
+	}
+	defaultEnvRegion, profileEnvRegions, err := parseEnvRegions(options.EnvRegions, profiles)
 	if err != nil {
 		return err
 	}
-
-	if showToken {
-		_ = common.ShowToken(sts, oidc.FetchDefault, false, true)
+	fetchOptions := &cloud.FetchCloudStsOptions{
+		ForceNew:   options.ForceNew,
+		PolicyFile: options.PolicyFile,
+	}
+	profileCredentials, err := fetchProfileCredentials(ctx, profiles, fetchOptions)
+	if err != nil {
+		return err
 	}
 
-	alibabaCloudSts, ok := sts.(*alibaba_cloud.StsToken)
-	if ok {
-		environment, err := putEnvForStsToken(alibabaCloudSts, envRegion, cloudStsConfig)
-		if err != nil {
-			return err
+	if options.ShowToken {
+		for _, profileCredential := range profileCredentials {
+			if len(profileCredentials) > 1 {
+				_ = common.ShowProfileToken(profileCredential.Profile, profileCredential.Credential,
+					&cloud_common.FormatOptions{}, false, true)
+			} else {
+				_ = common.ShowToken(profileCredential.Credential, &cloud_common.FormatOptions{}, false, true)
+			}
 		}
-		return executeCommand(args, environment)
 	}
-	awsStsToken, ok := sts.(*aws.AwsStsToken)
-	if ok {
-		environment, err := putEnvForAwsStsToken(awsStsToken, envRegion, cloudStsConfig)
+
+	var profileEnvironments []*profileEnvironment
+	defer func() {
+		for _, profileEnvironment := range profileEnvironments {
+			profileEnvironment.close()
+		}
+	}()
+	for _, profileCredential := range profileCredentials {
+		envRegion, ok := profileEnvRegions[profileCredential.Profile]
+		if !ok {
+			envRegion = defaultEnvRegion
+		}
+		profileEnvironment, err := buildProfileEnvironment(ctx, options, profileCredential, envRegion)
 		if err != nil {
-			return err
+			return wrapProfileError(profileCredential.Profile, len(profileCredentials), err)
+		}
+		profileEnvironments = append(profileEnvironments, profileEnvironment)
+		if options.ExecReplace && len(profileEnvironment.CredentialDirs) > 0 {
+			return fmt.Errorf("--exec-replace is not supported with credential files, which are removed after command exits")
 		}
-		return executeCommand(args, environment)
 	}
-
-	return fmt.Errorf("unknown cloud STS token type")
+	mergedEnvironments, err := mergeProfileEnvironments(profileEnvironments)
+	if err != nil {
+		return err
+	}
+	environment := buildCommandEnvironment(options, profileCredentials, mergedEnvironments)
+	return executeCommand(args, environment, options.ExecReplace)
 }
 
-func executeCommand(args, environment []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("no command specified")
-	}
-	idaaslog.Debug.PrintfLn("Exec args: %+v", args)
-	idaaslog.Unsafe.PrintfLn("Env: %s", strings.Join(environment, "\n"))
-	cmd := exec.Command(args[0], args[1:]...)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = environment
-	return cmd.Run()
+// buildProfileEnvironment builds environments of profile, credential files and endpoint are cleaned up by close
+func buildProfileEnvironment(ctx context.Context, options *executeOptions, profileCredential *profileCredential,
+	envRegion string) (*profileEnvironment, error) {
+	profile := profileCredential.Profile
+	environment := &profileEnvironment{
+		Profile:               profile,
+		credentialDirsByCloud: map[string]string{},
+	}
+	environOptions := &cloud_common.EnvironOptions{
+		Profile:         profile,
+		Region:          envRegion,
+		ForceNew:        options.ForceNew,
+		CloudStsConfig:  profileCredential.CloudStsConfig,
+		CredentialDir:   environment.credentialDir,
+		CredentialFiles: options.CredentialFiles,
+	}
+	if options.Refreshing {
+		endpointOptions := &cloud.FetchCloudStsOptions{
+			PolicyFile: options.PolicyFile,
+		}
+		endpoint, err := startCredentialEndpoint(profile, endpointOptions)
+		if err != nil {
+			return nil, err
+		}
+		environment.endpoint = endpoint
+		environOptions.CredentialEndpoint = endpoint.cloudCredentialEndpoint()
+	}
+	credentialEnvironments, err := profileCredential.Credential.Environ(ctx, environOptions)
+	if err != nil {
+		environment.close()
+		return nil, err
+	}
+	environments := addEnvironmentsFromConfig(nil, profileCredential.CloudStsConfig)
+	templateEnvironments, err := renderEnvTemplates(profileCredential.CloudStsConfig.EnvTemplates,
+		profileCredential.Credential)
+	if err != nil {
+		environment.close()
+		return nil, err
+	}
+	environments = append(environments, templateEnvironments...)
+	environments = append(environments, credentialEnvironments...)
+	environment.Environments = environments
+	return environment, nil
 }
 
-// Alibaba Cloud Terraform plugin credential order:
-// 静态配置 > 环境变量 > Profile 静态配置 > ECS 服务角色 > Profile ECS 服务角色 > OIDC 角色扮演 > 角色扮演
-// reference: https://help.aliyun.com/zh/terraform/terraform-authentication
-// reference: https://help.aliyun.com/zh/sdk/developer-reference/v2-manage-access-credentials
-func putEnvForStsToken(sts *alibaba_cloud.StsToken, envRegion string, cloudStsConfig *config.CloudStsConfig) ([]string, error) {
-	osEnv := os.Environ()
-	osEnv = addEnvironmentsFromConfig(osEnv, cloudStsConfig)
-
-	idaaslog.Debug.PrintfLn("Found access key ID: %s", sts.AccessKeyId)
-	osEnv = append(osEnv, "ALIBABA_CLOUD_ACCESS_KEY_ID="+sts.AccessKeyId)
-	osEnv = append(osEnv, "ALIBABACLOUD_ACCESS_KEY_ID="+sts.AccessKeyId)
-	osEnv = append(osEnv, "ALICLOUD_ACCESS_KEY_ID="+sts.AccessKeyId)
-	osEnv = append(osEnv, "ALICLOUD_ACCESS_KEY="+sts.AccessKeyId)
-	osEnv = append(osEnv, "ACCESS_KEY_ID="+sts.AccessKeyId)
-	osEnv = append(osEnv, "OSS_ACCESS_KEY_ID="+sts.AccessKeyId)
-
-	osEnv = append(osEnv, "ALICLOUD_SECRET_KEY="+sts.AccessKeySecret)
-	osEnv = append(osEnv, "ALIBABA_CLOUD_ACCESS_KEY_SECRET="+sts.AccessKeySecret)
-	osEnv = append(osEnv, "ALIBABACLOUD_ACCESS_KEY_SECRET="+sts.AccessKeySecret)
-	osEnv = append(osEnv, "ALICLOUD_ACCESS_KEY_SECRET="+sts.AccessKeySecret)
-	osEnv = append(osEnv, "ACCESS_KEY_SECRET="+sts.AccessKeySecret)
-	osEnv = append(osEnv, "OSS_ACCESS_KEY_SECRET="+sts.AccessKeySecret)
-
-	osEnv = append(osEnv, "ALIBABA_CLOUD_SECURITY_TOKEN="+sts.StsToken)
-	osEnv = append(osEnv, "ALIBABACLOUD_SECURITY_TOKEN="+sts.StsToken)
-	osEnv = append(osEnv, "ALICLOUD_SECURITY_TOKEN="+sts.StsToken)
-	osEnv = append(osEnv, "SECURITY_TOKEN="+sts.StsToken)
-	osEnv = append(osEnv, "OSS_SESSION_TOKEN="+sts.StsToken)
-
-	if envRegion != "" {
-		idaaslog.Debug.PrintfLn("Set region: %s", envRegion)
-		osEnv = append(osEnv, "ALICLOUD_REGION="+envRegion)
-		osEnv = append(osEnv, "ALIYUN_DEFAULT_REGION="+envRegion)
-		osEnv = append(osEnv, "DEFAULT_REGION="+envRegion)
-		osEnv = append(osEnv, "ALIBABA_CLOUD_DEFAULT_REGION="+envRegion)
-		osEnv = append(osEnv, "REGION="+envRegion)
-		osEnv = append(osEnv, "OSS_REGION="+envRegion)
+// buildCommandEnvironment os environments, optionally unset inherited credential environments, with profiles' environments,
+// duplicated variables are removed and the last value wins, syscall.Exec does not dedup like exec.Cmd
+func buildCommandEnvironment(options *executeOptions, profileCredentials []*profileCredential,
+	environments []string) []string {
+	environment := os.Environ()
+	if options.CleanEnv || options.UnsetConflicting {
+		environment = unsetEnvironments(environment,
+			getCredentialEnvironments(profileCredentials, options.CleanEnv))
 	}
-
-	return osEnv, nil
+	return dedupEnvironments(append(environment, environments...))
 }
 
-// reference: https://docs.aws.amazon.com/cli/v1/userguide/cli-configure-envvars.html
-func putEnvForAwsStsToken(sts *aws.AwsStsToken, envRegion string, cloudStsConfig *config.CloudStsConfig) ([]string, error) {
-	osEnv := os.Environ()
-	osEnv = addEnvironmentsFromConfig(osEnv, cloudStsConfig)
-
-	idaaslog.Debug.PrintfLn("Found access key ID: %s", sts.AccessKeyId)
-	osEnv = append(osEnv, "AWS_ACCESS_KEY_ID="+sts.AccessKeyId)
-
-	osEnv = append(osEnv, "AWS_SECRET_ACCESS_KEY="+sts.SecretAccessKey)
-
-	osEnv = append(osEnv, "AWS_SESSION_TOKEN="+sts.SessionToken)
-
-	if envRegion != "" {
-		idaaslog.Debug.PrintfLn("Set region: %s", envRegion)
-		osEnv = append(osEnv, "AWS_DEFAULT_REGION="+envRegion)
-		osEnv = append(osEnv, "AWS_REGION="+envRegion)
+// createCredentialDir creates private temp dir for credential files, removed after command exits
+func createCredentialDir(cloud string) (string, error) {
+	credentialDir, err := os.MkdirTemp("", "alibaba-cloud-idaas-"+cloud+"-")
+	if err != nil {
+		return "", fmt.Errorf("create %s credential dir failed: %v", cloud, err)
 	}
+	return credentialDir, nil
+}
 
-	return osEnv, nil
+func removeCredentialDir(credentialDir string) {
+	idaaslog.Debug.PrintfLn("Remove credential dir: %s", credentialDir)
+	_ = os.RemoveAll(credentialDir)
 }
 
 func addEnvironmentsFromConfig(environments []string, cloudStsConfig *config.CloudStsConfig) []string {
@@ -179,3 +288,25 @@ func addEnvironmentsFromConfig(environments []string, cloudStsConfig *config.Clo
 	}
 	return environments
 }
+
+// renderEnvTemplates renders env templates with credential's fields, e.g. TF_VAR_ak={{.AccessKeyId}},
+// templates are rendered once, not refreshed by --refreshing
+func renderEnvTemplates(envTemplates []string, credential cloud_common.Credential) ([]string, error) {
+	var environments []string
+	for i, envTemplate := range envTemplates {
+		key, _, found := strings.Cut(envTemplate, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid env template #%d, KEY=template is required", i)
+		}
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(envTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parse env template: %s failed: %v", key, err)
+		}
+		var environment strings.Builder
+		if err := tmpl.Execute(&environment, credential); err != nil {
+			return nil, fmt.Errorf("render env template: %s failed: %v", key, err)
+		}
+		environments = append(environments, environment.String())
+	}
+	return environments, nil
+}
@@ -0,0 +1,155 @@
+package execute
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/pkg/errors"
+)
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// credentialEndpoint serves fresh credentials of profile on random loopback port until command exits,
+// credentials are fetched on every request and refreshed by cache when expiring
+type credentialEndpoint struct {
+	profile  string
+	options  *cloud.FetchCloudStsOptions
+	token    string
+	listener net.Listener
+	server   *http.Server
+	lock     sync.Mutex
+}
+
+func startCredentialEndpoint(profile string, options *cloud.FetchCloudStsOptions) (*credentialEndpoint, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, errors.Wrap(err, "generate credential endpoint token failed")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "listen credential endpoint failed")
+	}
+	endpoint := &credentialEndpoint{
+		profile:  profile,
+		options:  options,
+		token:    hex.EncodeToString(tokenBytes),
+		listener: listener,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(cloud_common.CredentialEndpointPathAlibabaCloud, endpoint.handleAlibabaCloud)
+	mux.HandleFunc(cloud_common.CredentialEndpointPathAws, endpoint.handleAws)
+	endpoint.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		serveErr := endpoint.server.Serve(listener)
+		if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			idaaslog.Error.PrintfLn("Credential endpoint stopped: %v", serveErr)
+		}
+	}()
+	idaaslog.Info.PrintfLn("Credential endpoint listen at: %s", listener.Addr())
+	return endpoint, nil
+}
+
+func (e *credentialEndpoint) cloudCredentialEndpoint() *cloud_common.CredentialEndpoint {
+	return &cloud_common.CredentialEndpoint{
+		Url:   "http://" + e.listener.Addr().String(),
+		Token: e.token,
+	}
+}
+
+func (e *credentialEndpoint) close() {
+	idaaslog.Debug.PrintfLn("Close credential endpoint: %s", e.listener.Addr())
+	_ = e.server.Close()
+}
+
+func (e *credentialEndpoint) handleAlibabaCloud(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, cloud_common.CredentialEndpointPathAlibabaCloud)
+	credential, ok := e.fetchCredential(w, r, token)
+	if !ok {
+		return
+	}
+	stsToken, ok := credential.(*alibaba_cloud.StsToken)
+	if !ok {
+		printCloudNotMatch(w, credential)
+		return
+	}
+	printJson(w, http.StatusOK, stsToken.ConvertToCredentialsUri())
+}
+
+func (e *credentialEndpoint) handleAws(w http.ResponseWriter, r *http.Request) {
+	credential, ok := e.fetchCredential(w, r, r.Header.Get("Authorization"))
+	if !ok {
+		return
+	}
+	awsStsToken, ok := credential.(*aws.AwsStsToken)
+	if !ok {
+		printCloudNotMatch(w, credential)
+		return
+	}
+	printJson(w, http.StatusOK, awsStsToken.ConvertToContainerCredentials())
+}
+
+func (e *credentialEndpoint) fetchCredential(w http.ResponseWriter, r *http.Request, token string) (
+	cloud_common.Credential, bool) {
+	if r.Method != http.MethodGet {
+		printJson(w, http.StatusMethodNotAllowed, &errorResponse{
+			Error:   "not_allowed",
+			Message: "Method not allowed.",
+		})
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(e.token)) != 1 {
+		idaaslog.Warn.PrintfLn("Credential endpoint request unauthorized: %s", r.URL.Path)
+		printJson(w, http.StatusUnauthorized, &errorResponse{
+			Error:   "unauthorized",
+			Message: "Invalid token.",
+		})
+		return nil, false
+	}
+	// serialize fetches, concurrent requests share the refreshed cache
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	credential, _, err := cloud.FetchCloudStsFromDefaultConfig(r.Context(), e.profile, e.options)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Credential endpoint fetch credential failed: %v", err)
+		printJson(w, http.StatusInternalServerError, &errorResponse{
+			Error:   "fetch_failed",
+			Message: err.Error(),
+		})
+		return nil, false
+	}
+	return credential, true
+}
+
+func printCloudNotMatch(w http.ResponseWriter, credential cloud_common.Credential) {
+	printJson(w, http.StatusNotFound, &errorResponse{
+		Error:   "not_found",
+		Message: "Profile credential is " + credential.CloudName() + ".",
+	})
+}
+
+func printJson(w http.ResponseWriter, code int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	responseJson, err := json.Marshal(response)
+	if err == nil {
+		_, _ = w.Write(responseJson)
+	}
+}
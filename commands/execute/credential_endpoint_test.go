@@ -0,0 +1,120 @@
+package execute
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+)
+
+func TestCredentialEndpointToken(t *testing.T) {
+	// no config file, requests with valid token fail at fetching credential
+	t.Setenv("HOME", t.TempDir())
+	endpoint, err := startCredentialEndpoint("p", nil)
+	if err != nil {
+		t.Fatalf("start credential endpoint failed: %v", err)
+	}
+	defer endpoint.close()
+	cloudCredentialEndpoint := endpoint.cloudCredentialEndpoint()
+	token := cloudCredentialEndpoint.Token
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "alibaba cloud valid token",
+			path:          cloud_common.CredentialEndpointPathAlibabaCloud + token,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "fetch_failed",
+		},
+		{
+			name:          "alibaba cloud invalid token",
+			path:          cloud_common.CredentialEndpointPathAlibabaCloud + "invalid",
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "alibaba cloud token prefix",
+			path:          cloud_common.CredentialEndpointPathAlibabaCloud + token[:len(token)-1],
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "alibaba cloud missing token",
+			path:          cloud_common.CredentialEndpointPathAlibabaCloud,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "alibaba cloud token in header",
+			path:          cloud_common.CredentialEndpointPathAlibabaCloud,
+			authorization: token,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "aws valid token",
+			path:          cloud_common.CredentialEndpointPathAws,
+			authorization: token,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "fetch_failed",
+		},
+		{
+			name:          "aws invalid token",
+			path:          cloud_common.CredentialEndpointPathAws,
+			authorization: "Bearer " + token,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "aws missing token",
+			path:          cloud_common.CredentialEndpointPathAws,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unauthorized",
+		},
+		{
+			name:          "method not allowed",
+			method:        http.MethodPost,
+			path:          cloud_common.CredentialEndpointPathAws,
+			authorization: token,
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedError: "not_allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, err := http.NewRequest(method, cloudCredentialEndpoint.Url+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request credential endpoint failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("expected status: %d, got: %d", tt.expectedCode, resp.StatusCode)
+			}
+			var response errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("decode response failed: %v", err)
+			}
+			if response.Error != tt.expectedError {
+				t.Errorf("expected error: %s, got: %s, message: %s", tt.expectedError, response.Error, response.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package execute
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/urfave/cli/v2"
+)
+
+type groupResult struct {
+	Profile  string
+	ExitCode int
+	Err      error
+	Duration time.Duration
+}
+
+func (r *groupResult) failed() bool {
+	return r.Err != nil || r.ExitCode != 0
+}
+
+// executeGroup execute command once per member profile of group with bounded parallelism,
+// profiles sharing the same OIDC token provider reuse one login, exit with 1 when any profile failed
+func executeGroup(ctx context.Context, options *executeOptions, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+	if len(options.Profiles) > 0 {
+		return fmt.Errorf("--group is not supported with --profile")
+	}
+	if options.ExecReplace {
+		return fmt.Errorf("--exec-replace is not supported with --group")
+	}
+	if options.Parallel < 1 {
+		return fmt.Errorf("--parallel must be greater than 0")
+	}
+	profiles, err := config.FindGroupProfiles(options.Group)
+	if err != nil {
+		return err
+	}
+	defaultEnvRegion, profileEnvRegions, err := parseEnvRegions(options.EnvRegions, profiles)
+	if err != nil {
+		return err
+	}
+	prefixWidth := 0
+	for _, profile := range profiles {
+		prefixWidth = max(prefixWidth, len(profile))
+	}
+
+	// stop starting profiles when interrupted, running commands receive signals by runCommand
+	var interrupted atomic.Bool
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, relaySignals...)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case sig := <-signals:
+			idaaslog.Warn.PrintfLn("Group interrupted by signal: %s", sig)
+			interrupted.Store(true)
+		case <-done:
+		}
+	}()
+
+	var outputLock sync.Mutex
+	results := make([]*groupResult, len(profiles))
+	jobs := make(chan int, len(profiles))
+	for i := range profiles {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for worker := 0; worker < min(options.Parallel, len(profiles)); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				profile := profiles[i]
+				if interrupted.Load() {
+					results[i] = &groupResult{Profile: profile, Err: fmt.Errorf("skipped, interrupted")}
+					continue
+				}
+				envRegion, ok := profileEnvRegions[profile]
+				if !ok {
+					envRegion = defaultEnvRegion
+				}
+				prefix := fmt.Sprintf("%-*s | ", prefixWidth, profile)
+				results[i] = executeGroupProfile(ctx, options, profile, envRegion, args, prefix, &outputLock)
+			}
+		}()
+	}
+	wg.Wait()
+
+	printGroupResults(os.Stderr, results)
+	for _, result := range results {
+		if result.failed() {
+			return cli.Exit("", 1)
+		}
+	}
+	return nil
+}
+
+func executeGroupProfile(ctx context.Context, options *executeOptions, profile, envRegion string, args []string,
+	prefix string, outputLock *sync.Mutex) *groupResult {
+	startTime := time.Now()
+	result := &groupResult{Profile: profile}
+	defer func() {
+		result.Duration = time.Since(startTime)
+	}()
+	fetchOptions := &cloud.FetchCloudStsOptions{
+		ForceNew:   options.ForceNew,
+		PolicyFile: options.PolicyFile,
+	}
+	idaaslog.Debug.PrintfLn("Fetch credential of profile: %s", profile)
+	credential, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(ctx, profile, fetchOptions)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if options.ShowToken {
+		outputLock.Lock()
+		_ = common.ShowProfileToken(profile, credential, &cloud_common.FormatOptions{}, false, true)
+		outputLock.Unlock()
+	}
+	groupProfileCredential := &profileCredential{
+		Profile:        profile,
+		Credential:     credential,
+		CloudStsConfig: cloudStsConfig,
+	}
+	profileEnvironment, err := buildProfileEnvironment(ctx, options, groupProfileCredential, envRegion)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer profileEnvironment.close()
+	environment := buildCommandEnvironment(options, []*profileCredential{groupProfileCredential},
+		profileEnvironment.Environments)
+
+	stdout := &prefixWriter{prefix: prefix, writer: os.Stdout, lock: outputLock}
+	stderr := &prefixWriter{prefix: prefix, writer: os.Stderr, lock: outputLock}
+	defer stdout.flush()
+	defer stderr.flush()
+	// commands run in parallel, stdin is not shared
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = environment
+	idaaslog.Debug.PrintfLn("Exec profile: %s args: %+v", profile, args)
+	result.ExitCode, result.Err = runCommand(cmd)
+	return result
+}
+
+func printGroupResults(w io.Writer, results []*groupResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "PROFILE\tEXIT CODE\tDURATION\tERROR")
+	failedCount := 0
+	for _, result := range results {
+		exitCode := fmt.Sprintf("%d", result.ExitCode)
+		errMessage := ""
+		if result.Err != nil {
+			exitCode = "-"
+			errMessage = result.Err.Error()
+		}
+		if result.failed() {
+			failedCount++
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Profile, exitCode,
+			result.Duration.Round(100*time.Millisecond), errMessage)
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintf(w, "Total: %d profile(s), succeeded: %d, failed: %d\n",
+		len(results), len(results)-failedCount, failedCount)
+}
+
+// prefixWriter prefixes lines with profile, whole lines are written with lock so lines of profiles do not interleave
+type prefixWriter struct {
+	prefix string
+	writer io.Writer
+	lock   *sync.Mutex
+	buffer []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+		w.writeLine(w.buffer[:index+1])
+		w.buffer = w.buffer[index+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes last line without new line
+func (w *prefixWriter) flush() {
+	if len(w.buffer) > 0 {
+		w.writeLine(append(w.buffer, '\n'))
+		w.buffer = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, _ = io.WriteString(w.writer, w.prefix)
+	_, _ = w.writer.Write(line)
+}
@@ -0,0 +1,63 @@
+package execute
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+)
+
+// executeOidcTokenFile execute command with OIDC token file kept up to date until command exits,
+// SDKs assume role with OIDC token file by themselves, STS token is not fetched
+func executeOidcTokenFile(ctx context.Context, options *executeOptions, args []string) error {
+	if len(options.Profiles) > 1 || options.Group != "" {
+		return fmt.Errorf("--oidc-token-file supports only one profile")
+	}
+	if options.Refreshing || options.CredentialFiles || options.ExecReplace {
+		return fmt.Errorf("--oidc-token-file is not supported with --refreshing, --credential-files or --exec-replace")
+	}
+	profile := ""
+	if len(options.Profiles) == 1 {
+		profile = options.Profiles[0]
+	}
+	defaultEnvRegion, profileEnvRegions, err := parseEnvRegions(options.EnvRegions, []string{profile})
+	if err != nil {
+		return err
+	}
+	envRegion, ok := profileEnvRegions[profile]
+	if !ok {
+		envRegion = defaultEnvRegion
+	}
+	profile, cloudStsConfig, err := config.FindProfile(profile)
+	if err != nil {
+		return err
+	}
+	credentialDir, err := createCredentialDir("alibaba-cloud")
+	if err != nil {
+		return err
+	}
+	defer removeCredentialDir(credentialDir)
+	tokenFile := filepath.Join(credentialDir, alibaba_cloud.OidcTokenFileName)
+	writer, err := alibaba_cloud.NewOidcTokenFileWriter(profile, cloudStsConfig, tokenFile, options.ForceNew)
+	if err != nil {
+		return err
+	}
+	expiration, err := writer.Write(ctx)
+	if err != nil {
+		return err
+	}
+	writerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go writer.Run(writerCtx, expiration)
+
+	environments := addEnvironmentsFromConfig(nil, cloudStsConfig)
+	environments = append(environments, writer.Environ()...)
+	environments = alibaba_cloud.AppendRegionEnviron(environments, envRegion)
+	environment := buildCommandEnvironment(options, []*profileCredential{{
+		Profile:        profile,
+		CloudStsConfig: cloudStsConfig,
+	}}, environments)
+	return executeCommand(args, environment, false)
+}
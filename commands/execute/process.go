@@ -0,0 +1,87 @@
+package execute
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// executeCommand run command until exits, relay signals to command, and exit with command's exit code,
+// when execReplace is set, replace current process with command (Unix only)
+func executeCommand(args, environment []string, execReplace bool) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+	idaaslog.Debug.PrintfLn("Exec args: %+v", args)
+	idaaslog.Unsafe.PrintfLn("Env: %s", strings.Join(environment, "\n"))
+	if execReplace {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			return err
+		}
+		idaaslog.Debug.PrintfLn("Exec replace current process: %s", path)
+		return replaceProcess(path, args, environment)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = environment
+
+	exitCode, err := runCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		idaaslog.Info.PrintfLn("Command exited with code: %d", exitCode)
+		// exit silently with the same code, command has printed its errors
+		return cli.Exit("", exitCode)
+	}
+	return nil
+}
+
+// runCommand start command and relay signals to command until exits, returns command's exit code
+func runCommand(cmd *exec.Cmd) (int, error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, relaySignals...)
+	defer signal.Stop(signals)
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go relaySignalsToProcess(cmd.Process, signals, done)
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return getExitCode(exitErr.ProcessState), nil
+	}
+	return 0, err
+}
+
+func relaySignalsToProcess(process *os.Process, signals chan os.Signal, done chan struct{}) {
+	for {
+		select {
+		case sig := <-signals:
+			// terminal sends signals (e.g. Ctrl-C) to the foreground process group, command has received it,
+			// relay again may force some commands(e.g. terraform) to quit without clean up
+			if isForegroundTerminalSignal(sig) {
+				idaaslog.Debug.PrintfLn("Skip relay terminal signal: %s", sig)
+				continue
+			}
+			idaaslog.Debug.PrintfLn("Relay signal: %s", sig)
+			if err := process.Signal(sig); err != nil {
+				idaaslog.Warn.PrintfLn("Relay signal: %s failed: %v", sig, err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package execute
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var relaySignals = []os.Signal{
+	syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// isForegroundTerminalSignal SIGINT or SIGQUIT may be sent by terminal only when execute (and command in the same
+// process group) is the foreground process group of stdin's terminal, e.g. `kill -INT` to execute running in
+// background or without terminal is relayed, `kill -INT` to execute in foreground can not be distinguished from
+// Ctrl-C, send SIGTERM or signal the process group instead
+func isForegroundTerminalSignal(sig os.Signal) bool {
+	if sig != syscall.SIGINT && sig != syscall.SIGQUIT {
+		return false
+	}
+	foregroundProcessGroup, err := unix.IoctlGetInt(int(os.Stdin.Fd()), unix.TIOCGPGRP)
+	if err != nil {
+		// stdin is not a terminal
+		return false
+	}
+	return foregroundProcessGroup == syscall.Getpgrp()
+}
+
+// getExitCode returns 128 + signal number when command is killed by signal, same as shells
+func getExitCode(processState *os.ProcessState) int {
+	if waitStatus, ok := processState.Sys().(syscall.WaitStatus); ok && waitStatus.Signaled() {
+		return 128 + int(waitStatus.Signal())
+	}
+	return processState.ExitCode()
+}
+
+func replaceProcess(path string, args, environment []string) error {
+	return syscall.Exec(path, args, environment)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package execute
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// relaySignals Ctrl-C is sent to all processes attached to the console, caught only to keep running until
+// command exits, os.Interrupt can not be sent to process on Windows
+var relaySignals = []os.Signal{
+	os.Interrupt,
+}
+
+// isForegroundTerminalSignal Ctrl-C is sent to command by console
+func isForegroundTerminalSignal(sig os.Signal) bool {
+	return sig == os.Interrupt
+}
+
+func getExitCode(processState *os.ProcessState) int {
+	return processState.ExitCode()
+}
+
+func replaceProcess(path string, args, environment []string) error {
+	return errors.New("exec replace is not supported on Windows")
+}
@@ -0,0 +1,211 @@
+package execute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+)
+
+type profileCredential struct {
+	Profile        string
+	Credential     cloud_common.Credential
+	CloudStsConfig *config.CloudStsConfig
+}
+
+type profileEnvironment struct {
+	Profile      string
+	Environments []string
+	// CredentialDirs private temp dirs of credential files, not shared between profiles, files of the same cloud may conflict
+	CredentialDirs        []string
+	credentialDirsByCloud map[string]string
+	endpoint              *credentialEndpoint
+}
+
+func (e *profileEnvironment) credentialDir(cloud string) (string, error) {
+	if credentialDir, ok := e.credentialDirsByCloud[cloud]; ok {
+		return credentialDir, nil
+	}
+	credentialDir, err := createCredentialDir(cloud)
+	if err != nil {
+		return "", err
+	}
+	e.credentialDirsByCloud[cloud] = credentialDir
+	e.CredentialDirs = append(e.CredentialDirs, credentialDir)
+	return credentialDir, nil
+}
+
+// close removes credential dirs and stops credential endpoint after command exits
+func (e *profileEnvironment) close() {
+	for _, credentialDir := range e.CredentialDirs {
+		removeCredentialDir(credentialDir)
+	}
+	if e.endpoint != nil {
+		e.endpoint.close()
+	}
+}
+
+// fetchProfileCredentials fetch credentials of profiles concurrently, results are in profiles order
+func fetchProfileCredentials(ctx context.Context, profiles []string, options *cloud.FetchCloudStsOptions) (
+	[]*profileCredential, error) {
+	foundProfiles := map[string]bool{}
+	for _, profile := range profiles {
+		if foundProfiles[profile] {
+			return nil, fmt.Errorf("duplicate profile: %s", profile)
+		}
+		foundProfiles[profile] = true
+	}
+
+	profileCredentials := make([]*profileCredential, len(profiles))
+	errs := make([]error, len(profiles))
+	var wg sync.WaitGroup
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			idaaslog.Debug.PrintfLn("Fetch credential of profile: %s", profile)
+			credential, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(ctx, profile, options)
+			if err != nil {
+				errs[i] = wrapProfileError(profile, len(profiles), err)
+				return
+			}
+			profileCredentials[i] = &profileCredential{
+				Profile:        profile,
+				Credential:     credential,
+				CloudStsConfig: cloudStsConfig,
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return profileCredentials, nil
+}
+
+// parseEnvRegions returns region for all profiles and regions by profile, env region is `region` or `profile=region`
+func parseEnvRegions(envRegions, profiles []string) (string, map[string]string, error) {
+	defaultEnvRegion := ""
+	profileEnvRegions := map[string]string{}
+	for _, envRegion := range envRegions {
+		profile, region, found := strings.Cut(envRegion, "=")
+		if !found {
+			if defaultEnvRegion != "" && defaultEnvRegion != envRegion {
+				return "", nil, fmt.Errorf("multiple env regions: %s, %s, use profile=region for profile",
+					defaultEnvRegion, envRegion)
+			}
+			defaultEnvRegion = envRegion
+			continue
+		}
+		if !containsProfile(profiles, profile) {
+			return "", nil, fmt.Errorf("env region: %s profile: %s is not executed", envRegion, profile)
+		}
+		profileEnvRegions[profile] = region
+	}
+	return defaultEnvRegion, profileEnvRegions, nil
+}
+
+// mergeProfileEnvironments merge environments of profiles, same variable with different values from
+// different profiles is a conflict, e.g. two Alibaba Cloud profiles
+func mergeProfileEnvironments(profileEnvironments []*profileEnvironment) ([]string, error) {
+	type environmentValue struct {
+		profile string
+		value   string
+	}
+	environmentValues := map[string]*environmentValue{}
+	var merged []string
+	for _, profileEnvironment := range profileEnvironments {
+		for _, environment := range profileEnvironment.Environments {
+			key, value, _ := strings.Cut(environment, "=")
+			if existing, ok := environmentValues[key]; ok &&
+				existing.profile != profileEnvironment.Profile && existing.value != value {
+				return nil, fmt.Errorf("environment variable: %s conflicts between profile: %s and %s",
+					key, existing.profile, profileEnvironment.Profile)
+			}
+			environmentValues[key] = &environmentValue{
+				profile: profileEnvironment.Profile,
+				value:   value,
+			}
+			merged = append(merged, environment)
+		}
+	}
+	return merged, nil
+}
+
+// getCredentialEnvironments credential environments of all clouds, or clouds of profiles
+func getCredentialEnvironments(profileCredentials []*profileCredential, allClouds bool) []string {
+	var cloudProviders []*cloud.CloudProvider
+	if allClouds {
+		cloudProviders = cloud.GetCloudProviders()
+	} else {
+		for _, profileCredential := range profileCredentials {
+			if cloudProvider := cloud.FindCloudProvider(profileCredential.CloudStsConfig); cloudProvider != nil {
+				cloudProviders = append(cloudProviders, cloudProvider)
+			}
+		}
+	}
+	var credentialEnvironments []string
+	for _, cloudProvider := range cloudProviders {
+		credentialEnvironments = append(credentialEnvironments, cloudProvider.CredentialEnvironments...)
+	}
+	return credentialEnvironments
+}
+
+// unsetEnvironments remove environments by keys, keys are case-sensitive
+func unsetEnvironments(environments, keys []string) []string {
+	unsetKeys := map[string]bool{}
+	for _, key := range keys {
+		unsetKeys[key] = true
+	}
+	var result []string
+	for _, environment := range environments {
+		key, _, _ := strings.Cut(environment, "=")
+		if unsetKeys[key] {
+			idaaslog.Debug.PrintfLn("Unset inherited environment: %s", key)
+			continue
+		}
+		result = append(result, environment)
+	}
+	return result
+}
+
+// dedupEnvironments remove duplicated variables, the last value wins, order of last values is kept
+func dedupEnvironments(environments []string) []string {
+	lastIndexes := map[string]int{}
+	for i, environment := range environments {
+		key, _, _ := strings.Cut(environment, "=")
+		lastIndexes[key] = i
+	}
+	result := make([]string, 0, len(lastIndexes))
+	for i, environment := range environments {
+		key, _, _ := strings.Cut(environment, "=")
+		if lastIndexes[key] == i {
+			result = append(result, environment)
+		}
+	}
+	return result
+}
+
+func containsProfile(profiles []string, profile string) bool {
+	for _, p := range profiles {
+		if p == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapProfileError wrap profile to error when multiple profiles are executed
+func wrapProfileError(profile string, profileCount int, err error) error {
+	if profileCount <= 1 {
+		return err
+	}
+	return fmt.Errorf("profile: %s, %v", profile, err)
+}
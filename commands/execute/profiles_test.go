@@ -0,0 +1,196 @@
+package execute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeProfileEnvironments(t *testing.T) {
+	tests := []struct {
+		name                string
+		profileEnvironments []*profileEnvironment
+		expected            []string
+		err                 string
+	}{
+		{
+			name: "different clouds",
+			profileEnvironments: []*profileEnvironment{
+				{Profile: "aliyun", Environments: []string{"ALIBABA_CLOUD_ACCESS_KEY_ID=ak1",
+					"ALIBABA_CLOUD_REGION_ID=cn-hangzhou"}},
+				{Profile: "aws", Environments: []string{"AWS_ACCESS_KEY_ID=ak2"}},
+			},
+			expected: []string{"ALIBABA_CLOUD_ACCESS_KEY_ID=ak1", "ALIBABA_CLOUD_REGION_ID=cn-hangzhou",
+				"AWS_ACCESS_KEY_ID=ak2"},
+		},
+		{
+			name: "same value from different profiles",
+			profileEnvironments: []*profileEnvironment{
+				{Profile: "aws", Environments: []string{"AWS_REGION=us-east-1"}},
+				{Profile: "gcp", Environments: []string{"AWS_REGION=us-east-1"}},
+			},
+			expected: []string{"AWS_REGION=us-east-1", "AWS_REGION=us-east-1"},
+		},
+		{
+			name: "value containing equals sign",
+			profileEnvironments: []*profileEnvironment{
+				{Profile: "aliyun", Environments: []string{"ALIBABA_CLOUD_SECURITY_TOKEN=a=b"}},
+				{Profile: "aws", Environments: []string{"ALIBABA_CLOUD_SECURITY_TOKEN=a=b"}},
+			},
+			expected: []string{"ALIBABA_CLOUD_SECURITY_TOKEN=a=b", "ALIBABA_CLOUD_SECURITY_TOKEN=a=b"},
+		},
+		{
+			name: "same profile overrides",
+			profileEnvironments: []*profileEnvironment{
+				{Profile: "aliyun", Environments: []string{"ALIBABA_CLOUD_REGION_ID=cn-hangzhou",
+					"ALIBABA_CLOUD_REGION_ID=cn-shanghai"}},
+			},
+			expected: []string{"ALIBABA_CLOUD_REGION_ID=cn-hangzhou", "ALIBABA_CLOUD_REGION_ID=cn-shanghai"},
+		},
+		{
+			name: "conflict",
+			profileEnvironments: []*profileEnvironment{
+				{Profile: "aliyun1", Environments: []string{"ALIBABA_CLOUD_ACCESS_KEY_ID=ak1"}},
+				{Profile: "aliyun2", Environments: []string{"ALIBABA_CLOUD_ACCESS_KEY_ID=ak2"}},
+			},
+			err: "environment variable: ALIBABA_CLOUD_ACCESS_KEY_ID conflicts between profile: aliyun1 and aliyun2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeProfileEnvironments(tt.profileEnvironments)
+			assertError(t, tt.err, err)
+			if tt.err == "" && !reflect.DeepEqual(tt.expected, merged) {
+				t.Errorf("expected: %v, got: %v", tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestParseEnvRegions(t *testing.T) {
+	profiles := []string{"aliyun", "aws"}
+	tests := []struct {
+		name                      string
+		envRegions                []string
+		expectedDefaultEnvRegion  string
+		expectedProfileEnvRegions map[string]string
+		err                       string
+	}{
+		{
+			name:                      "empty",
+			expectedProfileEnvRegions: map[string]string{},
+		},
+		{
+			name:                      "default region",
+			envRegions:                []string{"cn-hangzhou", "cn-hangzhou"},
+			expectedDefaultEnvRegion:  "cn-hangzhou",
+			expectedProfileEnvRegions: map[string]string{},
+		},
+		{
+			name:                      "profile regions",
+			envRegions:                []string{"cn-hangzhou", "aws=us-east-1"},
+			expectedDefaultEnvRegion:  "cn-hangzhou",
+			expectedProfileEnvRegions: map[string]string{"aws": "us-east-1"},
+		},
+		{
+			name:       "multiple default regions",
+			envRegions: []string{"cn-hangzhou", "us-east-1"},
+			err:        "multiple env regions: cn-hangzhou, us-east-1, use profile=region for profile",
+		},
+		{
+			name:       "profile not executed",
+			envRegions: []string{"gcp=us-east1"},
+			err:        "env region: gcp=us-east1 profile: gcp is not executed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultEnvRegion, profileEnvRegions, err := parseEnvRegions(tt.envRegions, profiles)
+			assertError(t, tt.err, err)
+			if tt.err != "" {
+				return
+			}
+			if defaultEnvRegion != tt.expectedDefaultEnvRegion {
+				t.Errorf("expected default env region: %s, got: %s", tt.expectedDefaultEnvRegion, defaultEnvRegion)
+			}
+			if !reflect.DeepEqual(tt.expectedProfileEnvRegions, profileEnvRegions) {
+				t.Errorf("expected profile env regions: %v, got: %v", tt.expectedProfileEnvRegions, profileEnvRegions)
+			}
+		})
+	}
+}
+
+func assertError(t *testing.T, expected string, err error) {
+	t.Helper()
+	if expected == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error: %s, got: %v", expected, err)
+	}
+}
+
+func TestDedupEnvironments(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments []string
+		expected     []string
+	}{
+		{name: "empty", environments: nil, expected: []string{}},
+		{name: "no duplicates", environments: []string{"A=1", "B=2"}, expected: []string{"A=1", "B=2"}},
+		{
+			name:         "last value wins",
+			environments: []string{"A=1", "B=2", "A=3", "C=4"},
+			expected:     []string{"B=2", "A=3", "C=4"},
+		},
+		{
+			name:         "inherited value overridden",
+			environments: []string{"PATH=/usr/bin", "AWS_REGION=us-east-1", "AWS_REGION=us-west-2", "PATH=/bin"},
+			expected:     []string{"AWS_REGION=us-west-2", "PATH=/bin"},
+		},
+		{name: "empty value", environments: []string{"A=1", "A="}, expected: []string{"A="}},
+		{name: "value with equals sign", environments: []string{"A=b=c", "B=1"}, expected: []string{"A=b=c", "B=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := dedupEnvironments(tt.environments)
+			if !reflect.DeepEqual(tt.expected, result) {
+				t.Errorf("expected: %v, got: %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestUnsetEnvironments(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments []string
+		keys         []string
+		expected     []string
+	}{
+		{name: "no keys", environments: []string{"A=1"}, expected: []string{"A=1"}},
+		{
+			name:         "unset credentials",
+			environments: []string{"PATH=/bin", "AWS_ACCESS_KEY_ID=ak1", "AWS_SESSION_TOKEN=tok1"},
+			keys:         []string{"AWS_ACCESS_KEY_ID", "AWS_SESSION_TOKEN", "AWS_SECRET_ACCESS_KEY"},
+			expected:     []string{"PATH=/bin"},
+		},
+		{
+			name:         "case sensitive",
+			environments: []string{"aws_access_key_id=ak1"},
+			keys:         []string{"AWS_ACCESS_KEY_ID"},
+			expected:     []string{"aws_access_key_id=ak1"},
+		},
+		{name: "prefix is not matched", environments: []string{"AB=1"}, keys: []string{"A"}, expected: []string{"AB=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := unsetEnvironments(tt.environments, tt.keys)
+			if !reflect.DeepEqual(tt.expected, result) {
+				t.Errorf("expected: %v, got: %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -1,12 +1,11 @@
 package fetch_token
 
 import (
-	"fmt"
+	"context"
 	"os"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
@@ -33,6 +32,10 @@ var (
 		Aliases: []string{"o"},
 		Usage:   "Output to file",
 	}
+	stringFlagPolicyFile = &cli.StringFlag{
+		Name:  "policy-file",
+		Usage: "Override Alibaba Cloud STS session policy with policy JSON file",
+	}
 	boolFlagForceNew = &cli.BoolFlag{
 		Name:    "force-new",
 		Aliases: []string{"N"},
@@ -46,6 +49,7 @@ func BuildCommand() *cli.Command {
 		stringFlagFormat,
 		stringFlagOidcField,
 		stringFlagOutput,
+		stringFlagPolicyFile,
 		boolFlagForceNew,
 	}
 	return &cli.Command{
@@ -57,55 +61,41 @@ func BuildCommand() *cli.Command {
 			format := context.String("format")
 			oidcField := context.String("oidc-field")
 			output := context.String("output")
+			policyFile := context.String("policy-file")
 			forceNew := context.Bool("force-new")
 
-			return fetchToken(profile, format, oidcField, output, forceNew)
+			return fetchToken(profile, format, oidcField, output, policyFile, forceNew)
 		},
 	}
 }
 
-func fetchToken(profile, format, oidcField, output string, forceNew bool) error {
+func fetchToken(profile, format, oidcField, output, policyFile string, forceNew bool) error {
 	options := &cloud.FetchCloudStsOptions{
-		ForceNew: forceNew,
+		ForceNew:   forceNew,
+		PolicyFile: policyFile,
 	}
 	oidcTokenType := oidc.GetOidcTokenType(oidcField)
 	options.FetchOidcTokenType = oidcTokenType
 
-	sts, _, err := cloud.FetchCloudStsFromDefaultConfig(profile, options)
+	credential, _, err := cloud.FetchCloudStsFromDefaultConfig(context.Background(), profile, options)
 	if err != nil {
 		return err
 	}
 
-	var stdOutput string
-	var stdOutputErr error
-	printNewLine := true
-
-	if alibabaCloudSts, ok := sts.(*alibaba_cloud.StsToken); ok {
-		stdOutput, stdOutputErr = alibabaCloudSts.MarshalWithFormat(format)
-	} else if awsStsToken, ok := sts.(*aws.AwsStsToken); ok {
-		stdOutput, stdOutputErr = awsStsToken.Marshal()
-	} else if oidcToken, ok := sts.(*oidc.OidcToken); ok {
-		if oidcTokenType == oidc.FetchIdToken {
-			printNewLine = false
-			stdOutput = oidcToken.IdToken
-		} else if oidcTokenType == oidc.FetchAccessToken {
-			printNewLine = false
-			stdOutput = oidcToken.AccessToken
-		} else {
-			stdOutput, stdOutputErr = oidcToken.Marshal()
-		}
-	} else {
-		return fmt.Errorf("unknown cloud STS token type")
+	formatOptions := &cloud_common.FormatOptions{
+		Format:    format,
+		OidcField: oidcField,
 	}
-
-	if stdOutputErr != nil {
-		return stdOutputErr
+	credentialOutput, err := credential.Output(formatOptions)
+	if err != nil {
+		return err
 	}
+	stdOutput := credentialOutput.Content
 	if output == "" {
-		if printNewLine {
-			utils.Stdout.Println(stdOutput)
-		} else {
+		if credentialOutput.Raw {
 			utils.Stdout.Print(stdOutput)
+		} else {
+			utils.Stdout.Println(stdOutput)
 		}
 	} else {
 		// write to file output
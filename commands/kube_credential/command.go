@@ -0,0 +1,73 @@
+package kube_credential
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagOidcField = &cli.StringFlag{
+		Name:  "oidc-field",
+		Usage: "Use OIDC filed as token (id_token or access_token), default id_token",
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch OIDC token, ignore cache (including OpenId configuration etc.)",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagOidcField,
+		boolFlagForceNew,
+	}
+	return &cli.Command{
+		Name:  "kube-credential",
+		Usage: "Fetch OIDC token as Kubernetes ExecCredential",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			oidcField := context.String("oidc-field")
+			forceNew := context.Bool("force-new")
+			return fetchKubeCredential(profile, oidcField, forceNew)
+		},
+	}
+}
+
+func fetchKubeCredential(profile, oidcField string, forceNew bool) error {
+	oidcTokenType := oidc.GetOidcTokenType(oidcField)
+	options := &cloud.FetchCloudStsOptions{
+		ForceNew:           forceNew,
+		FetchOidcTokenType: oidcTokenType,
+	}
+	credential, _, err := cloud.FetchCloudStsFromDefaultConfig(context.Background(), profile, options)
+	if err != nil {
+		return err
+	}
+	oidcToken, ok := credential.(*oidc.OidcToken)
+	if !ok {
+		return fmt.Errorf("kube-credential requires profile with oidc_token, profile: %s", profile)
+	}
+	execCredential, err := oidcToken.ToExecCredential(oidcTokenType)
+	if err != nil {
+		return err
+	}
+	execCredentialJson, err := execCredential.Marshal()
+	if err != nil {
+		return err
+	}
+	utils.Stdout.Println(execCredentialJson)
+	return nil
+}
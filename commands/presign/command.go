@@ -0,0 +1,117 @@
+package presign
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagMethod = &cli.StringFlag{
+		Name:    "method",
+		Aliases: []string{"X"},
+		Usage:   "HTTP method, GET or PUT",
+		Value:   "GET",
+	}
+	durationFlagExpires = &cli.DurationFlag{
+		Name:    "expires",
+		Aliases: []string{"e"},
+		Usage:   "URL validity, capped at STS token expiration",
+		Value:   15 * time.Minute,
+	}
+	stringFlagSignatureVersion = &cli.StringFlag{
+		Name:  "signature-version",
+		Usage: "OSS signature version, v4 or v1",
+		Value: alibaba_cloud.OssSignatureVersionV4,
+	}
+	stringFlagRegion = &cli.StringFlag{
+		Name:  "region",
+		Usage: "OSS region, e.g. cn-hangzhou, default profile's region",
+	}
+	stringFlagEndpoint = &cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "OSS endpoint, e.g. oss-cn-hangzhou-internal.aliyuncs.com, default oss-<region>.aliyuncs.com",
+	}
+	stringFlagContentType = &cli.StringFlag{
+		Name:  "content-type",
+		Usage: "Signed Content-Type, PUT request must send the same Content-Type",
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch cloud STS token, ignore cache (including OpenId configuration etc.)",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagMethod,
+		durationFlagExpires,
+		stringFlagSignatureVersion,
+		stringFlagRegion,
+		stringFlagEndpoint,
+		stringFlagContentType,
+		boolFlagForceNew,
+	}
+	return &cli.Command{
+		Name:      "presign",
+		Usage:     "Generate OSS presigned URL with STS token locally",
+		ArgsUsage: "oss://bucket/key",
+		Flags:     flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			forceNew := context.Bool("force-new")
+			options := &alibaba_cloud.PresignOssUrlOptions{
+				Method:           context.String("method"),
+				Expires:          context.Duration("expires"),
+				SignatureVersion: context.String("signature-version"),
+				Region:           context.String("region"),
+				Endpoint:         context.String("endpoint"),
+				ContentType:      context.String("content-type"),
+			}
+			return presign(profile, context.Args().First(), forceNew, options)
+		},
+	}
+}
+
+func presign(profile, ossUrl string, forceNew bool, options *alibaba_cloud.PresignOssUrlOptions) error {
+	bucket, key, err := alibaba_cloud.ParseOssUrl(ossUrl)
+	if err != nil {
+		return err
+	}
+	options.Bucket = bucket
+	options.Key = key
+
+	fetchOptions := &cloud.FetchCloudStsOptions{
+		ForceNew: forceNew,
+	}
+	credential, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(context.Background(), profile, fetchOptions)
+	if err != nil {
+		return err
+	}
+	stsToken, ok := credential.(*alibaba_cloud.StsToken)
+	if !ok {
+		return fmt.Errorf("presign only supports Alibaba Cloud STS token")
+	}
+	if options.Region == "" {
+		options.Region = cloudStsConfig.AlibabaCloud.Region
+	}
+	presignedUrl, expiration, err := stsToken.PresignOssUrl(options)
+	if err != nil {
+		return err
+	}
+	utils.Stdout.Println(presignedUrl)
+	utils.Stderr.Fprintf("Expires at: %s\n", expiration.Local().Format(time.RFC3339))
+	return nil
+}
@@ -35,19 +35,23 @@ func BuildCommand() *cli.Command {
 				content = "https://www.aliyun.com/product/idaas"
 			}
 
-			qrCode, err := qrcode.New(content, qrcode.Low)
-			if err != nil {
-				return errors.Errorf("failed to display QR Code: %v\n", err)
-			}
-			if qrCode != nil {
-				if small {
-					utils.Stderr.Print("QR code [small]:\n" + qrCode.ToSmallString(false))
-				} else {
-					utils.Stderr.Print("QR code [normal]:\n" + qrCode.ToString(false))
-				}
-			}
-
-			return nil
+			return PrintQrCode(content, small)
 		},
 	}
 }
+
+// PrintQrCode prints QR code to stderr
+func PrintQrCode(content string, small bool) error {
+	qrCode, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		return errors.Errorf("failed to display QR Code: %v\n", err)
+	}
+	if qrCode != nil {
+		if small {
+			utils.Stderr.Print("QR code [small]:\n" + qrCode.ToSmallString(false))
+		} else {
+			utils.Stderr.Print("QR code [normal]:\n" + qrCode.ToString(false))
+		}
+	}
+	return nil
+}
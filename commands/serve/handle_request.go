@@ -1,9 +1,9 @@
 package serve
 
 import (
+	"fmt"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
-	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
 	"net/http"
 )
 
@@ -22,7 +22,7 @@ func handleCloudToken(w http.ResponseWriter, r *http.Request) {
 	options := &cloud.FetchCloudStsOptions{
 		ForceNew: forceNew == "true",
 	}
-	sts, _, err := cloud.FetchCloudStsFromDefaultConfig(profile, options)
+	credential, _, err := cloud.FetchCloudStsFromDefaultConfig(r.Context(), profile, options)
 	if err != nil {
 		// TODO logging
 		printResponse(w, http.StatusInternalServerError, ErrorResponse{
@@ -32,24 +32,14 @@ func handleCloudToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	alibabaCloudSts, ok := sts.(*alibaba_cloud.StsToken)
+	alibabaCloudSts, ok := credential.(*alibaba_cloud.StsToken)
 	if ok {
 		printResponse(w, http.StatusOK, alibabaCloudSts.ConvertToCredentialsUri())
 		return
 	}
 
-	_, ok = sts.(*aws.AwsStsToken)
-	if ok {
-		printResponse(w, http.StatusNotImplemented, ErrorResponse{
-			Error:   "not_implemented",
-			Message: "AWS sts token sts not implemented.",
-		})
-		return
-	}
-
-	printResponse(w, http.StatusInternalServerError, ErrorResponse{
-		Error:   "bad_request",
-		Message: "Unknown cloud sts token.",
+	printResponse(w, http.StatusNotImplemented, ErrorResponse{
+		Error:   "not_implemented",
+		Message: fmt.Sprintf("%s token not implemented.", credential.CloudName()),
 	})
-	return
 }
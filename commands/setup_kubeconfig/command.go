@@ -0,0 +1,172 @@
+package setup_kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+const execInteractiveModeIfAvailable = "IfAvailable"
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagCluster = &cli.StringFlag{
+		Name:     "cluster",
+		Usage:    "Cluster name in kubeconfig",
+		Required: true,
+	}
+	stringFlagUser = &cli.StringFlag{
+		Name:  "user",
+		Usage: "User name in kubeconfig, default alibaba-cloud-idaas-<profile>",
+	}
+	stringFlagContext = &cli.StringFlag{
+		Name:  "context",
+		Usage: "Context name in kubeconfig, default <cluster>-<user>",
+	}
+	stringFlagKubeconfig = &cli.StringFlag{
+		Name:  "kubeconfig",
+		Usage: "Kubeconfig file, default by kubectl",
+	}
+	stringFlagOidcField = &cli.StringFlag{
+		Name:  "oidc-field",
+		Usage: "Use OIDC filed as token (id_token or access_token), default id_token",
+	}
+	boolFlagPrint = &cli.BoolFlag{
+		Name:  "print",
+		Usage: "Print users stanza only, do not modify kubeconfig",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagCluster,
+		stringFlagUser,
+		stringFlagContext,
+		stringFlagKubeconfig,
+		stringFlagOidcField,
+		boolFlagPrint,
+	}
+	return &cli.Command{
+		Name:  "setup-kubeconfig",
+		Usage: "Setup kubeconfig user with kube-credential exec plugin",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			cluster := context.String("cluster")
+			user := context.String("user")
+			kubeContext := context.String("context")
+			kubeconfig := context.String("kubeconfig")
+			oidcField := context.String("oidc-field")
+			printOnly := context.Bool("print")
+			return setupKubeconfig(profile, cluster, user, kubeContext, kubeconfig, oidcField, printOnly)
+		},
+	}
+}
+
+func setupKubeconfig(profile, cluster, user, kubeContext, kubeconfig, oidcField string, printOnly bool) error {
+	resolvedProfile, cloudStsConfig, err := config.FindProfile(profile)
+	if err != nil {
+		return fmt.Errorf("find profie `%s` error: %s", profile, err)
+	}
+	if cloudStsConfig.OidcToken == nil {
+		return fmt.Errorf("kube-credential requires profile with oidc_token, profile: %s", resolvedProfile)
+	}
+	if oidcField != "" && oidcField != oidc.TokenIdToken && oidcField != oidc.TokenAccessToken {
+		return fmt.Errorf("invalid oidc field: %s, must be id_token or access_token", oidcField)
+	}
+	// keep inline profile as is, otherwise pin the resolved profile
+	if profile == "" {
+		profile = resolvedProfile
+	}
+	if user == "" {
+		user = "alibaba-cloud-idaas-" + resolvedProfile
+	}
+	if kubeContext == "" {
+		kubeContext = cluster + "-" + user
+	}
+	command, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "get executable failed")
+	}
+	execArgs := []string{"kube-credential", "--profile", profile}
+	if oidcField != "" {
+		execArgs = append(execArgs, "--oidc-field", oidcField)
+	}
+
+	if printOnly {
+		utils.Stdout.Print(buildUsersStanza(user, command, execArgs))
+		return nil
+	}
+
+	setCredentialsArgs := []string{"config", "set-credentials", user,
+		"--exec-api-version=" + oidc.ExecCredentialApiVersion,
+		"--exec-command=" + command,
+		"--exec-interactive-mode=" + execInteractiveModeIfAvailable,
+	}
+	for _, execArg := range execArgs {
+		setCredentialsArgs = append(setCredentialsArgs, "--exec-arg="+execArg)
+	}
+	err = runKubectl(kubeconfig, setCredentialsArgs)
+	if err != nil {
+		return err
+	}
+	err = runKubectl(kubeconfig, []string{"config", "set-context", kubeContext,
+		"--cluster=" + cluster, "--user=" + user})
+	if err != nil {
+		return err
+	}
+	utils.Stderr.Fprintf("Kubeconfig user: %s, context: %s for cluster: %s is set\n", user, kubeContext, cluster)
+	utils.Stderr.Fprintf("Use context: kubectl config use-context %s\n", kubeContext)
+	return nil
+}
+
+func runKubectl(kubeconfig string, args []string) error {
+	if kubeconfig != "" {
+		args = append([]string{"--kubeconfig=" + kubeconfig}, args...)
+	}
+	idaaslog.Debug.PrintfLn("Run kubectl: %s", strings.Join(args, " "))
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return errors.Wrap(err, "run kubectl failed")
+	}
+	return nil
+}
+
+// buildUsersStanza builds YAML users stanza, values are single-quoted YAML scalars
+func buildUsersStanza(user, command string, execArgs []string) string {
+	var stanza strings.Builder
+	stanza.WriteString("users:\n")
+	stanza.WriteString(fmt.Sprintf("- name: %s\n", quoteYaml(user)))
+	stanza.WriteString("  user:\n")
+	stanza.WriteString("    exec:\n")
+	stanza.WriteString(fmt.Sprintf("      apiVersion: %s\n", quoteYaml(oidc.ExecCredentialApiVersion)))
+	stanza.WriteString(fmt.Sprintf("      command: %s\n", quoteYaml(command)))
+	stanza.WriteString("      args:\n")
+	for _, execArg := range execArgs {
+		stanza.WriteString(fmt.Sprintf("      - %s\n", quoteYaml(execArg)))
+	}
+	stanza.WriteString(fmt.Sprintf("      interactiveMode: %s\n", quoteYaml(execInteractiveModeIfAvailable)))
+	return stanza.String()
+}
+
+// quoteYaml single-quoted YAML scalar, no escapes except single quote is doubled,
+// e.g. Windows paths with backslashes are kept as is
+func quoteYaml(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
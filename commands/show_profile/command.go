@@ -62,9 +62,18 @@ func showProfiles(profileFilter string, color bool) error {
 			comment = fmt.Sprintf(" , with comment: %s", utils.Under(profile.Comment, color))
 		}
 		fmt.Printf("Profile: %s%s\n", utils.Bold(utils.Blue(utils.Under(name, color), color), color), comment)
+		if profile.Extends != "" {
+			fmt.Printf(" %s: %s\n", pad("Extends"), utils.Green(profile.Extends, color))
+		}
+		if len(profile.Tags) > 0 {
+			fmt.Printf(" %s: %s\n", pad("Tags"), utils.Green(strings.Join(profile.Tags, ", "), color))
+		}
 
 		showAlibabaCloud(color, profile)
 		showAws(color, profile)
+		showAwsRolesAnywhere(color, profile)
+		showGcp(color, profile)
+		showAzureAd(color, profile)
 		showOidc(color, profile)
 
 		println()
@@ -83,17 +92,94 @@ func showProfiles(profileFilter string, color bool) error {
 func showAlibabaCloud(color bool, profile *config.CloudStsConfig) {
 	if profile.AlibabaCloud != nil {
 		alibabaCloud := profile.AlibabaCloud
-		fmt.Printf(" %s: %s\n", pad("OidcProviderArn"), utils.Green(alibabaCloud.OidcProviderArn, color))
+		if alibabaCloud.OidcProviderArn != "" {
+			fmt.Printf(" %s: %s\n", pad("OidcProviderArn"), utils.Green(alibabaCloud.OidcProviderArn, color))
+		}
 		fmt.Printf(" %s: %s\n", pad("RoleArn"), utils.Green(alibabaCloud.RoleArn, color))
+		if alibabaCloud.Network != "" {
+			fmt.Printf(" %s: %s\n", pad("Network"), utils.Green(alibabaCloud.Network, color))
+		}
+		for i, fallbackEndpoint := range alibabaCloud.FallbackEndpoints {
+			if fallbackEndpoint != nil {
+				fmt.Printf(" %s: %s\n", pad(fmt.Sprintf("Fallback Endpoint #%d", i)),
+					utils.Green(fallbackEndpoint.Endpoint, color))
+			}
+		}
 		if alibabaCloud.DurationSeconds > 0 {
 			fmt.Printf("  %s: %s seconds\n", pad("DurationSeconds"), utils.Green(fmt.Sprintf("%d", alibabaCloud.DurationSeconds), color))
 		}
 		if alibabaCloud.RoleSessionName != "" {
 			fmt.Printf("  %s: %s\n", pad("RoleSessionName"), utils.Green(alibabaCloud.RoleSessionName, color))
 		}
+		if alibabaCloud.Policy != "" {
+			fmt.Printf(" %s: %s\n", pad("Policy"), utils.Green(alibabaCloud.Policy, color))
+		}
+		if alibabaCloud.PolicyFile != "" {
+			fmt.Printf(" %s: %s\n", pad("PolicyFile"), utils.Green(alibabaCloud.PolicyFile, color))
+		}
 
 		oidcTokenProvider := alibabaCloud.OidcTokenProvider
 		showOidcTokenProvider(color, oidcTokenProvider)
+		showCredentialSource(color, alibabaCloud.CredentialSource)
+		if alibabaCloud.Acr != nil {
+			fmt.Printf(" %s: %s\n", pad("ACR Registries"),
+				utils.Green(strings.Join(alibabaCloud.Acr.Registries, ", "), color))
+			if alibabaCloud.Acr.InstanceId != "" {
+				fmt.Printf(" - %s: %s\n", pad2("InstanceId"), utils.Green(alibabaCloud.Acr.InstanceId, color))
+			}
+		}
+
+		for i, assumeRole := range alibabaCloud.AssumeRoleChain {
+			fmt.Printf(" %s: %s\n", pad(fmt.Sprintf("AssumeRole #%d", i+1)), utils.Green(assumeRole.RoleArn, color))
+			if assumeRole.DurationSeconds > 0 {
+				fmt.Printf(" - %s: %s seconds\n", pad2("DurationSeconds"), utils.Green(fmt.Sprintf("%d", assumeRole.DurationSeconds), color))
+			}
+			if assumeRole.RoleSessionName != "" {
+				fmt.Printf(" - %s: %s\n", pad2("RoleSessionName"), utils.Green(assumeRole.RoleSessionName, color))
+			}
+			if assumeRole.ExternalId != "" {
+				fmt.Printf(" - %s: %s\n", pad2("ExternalId"), utils.Green(assumeRole.ExternalId, color))
+			}
+			if assumeRole.Policy != "" {
+				fmt.Printf(" - %s: %s\n", pad2("Policy"), utils.Green(assumeRole.Policy, color))
+			}
+			if assumeRole.PolicyFile != "" {
+				fmt.Printf(" - %s: %s\n", pad2("PolicyFile"), utils.Green(assumeRole.PolicyFile, color))
+			}
+		}
+	}
+}
+
+func showCredentialSource(color bool, credentialSource *config.AlibabaCloudCredentialSource) {
+	if credentialSource == nil {
+		return
+	}
+	if credentialSource.EcsRamRole != nil {
+		ecsRamRole := credentialSource.EcsRamRole
+		fmt.Printf(" %s: %s\n", pad("Credential Source"), utils.Green("ECS RAM Role", color))
+		if ecsRamRole.RoleName != "" {
+			fmt.Printf(" - %s: %s\n", pad2("RoleName"), utils.Green(ecsRamRole.RoleName, color))
+		}
+		if ecsRamRole.MetadataEndpoint != "" {
+			fmt.Printf(" - %s: %s\n", pad2("MetadataEndpoint"), utils.Green(ecsRamRole.MetadataEndpoint, color))
+		}
+		if ecsRamRole.DisableImdsV1 {
+			fmt.Printf(" - %s: %s\n", pad2("DisableImdsV1"), utils.Green("true", color))
+		}
+	}
+	if credentialSource.AccessKey != nil {
+		accessKey := credentialSource.AccessKey
+		fmt.Printf(" %s: %s\n", pad("Credential Source"), utils.Green("Access Key", color))
+		fmt.Printf(" - %s: %s\n", pad2("AccessKeyId"), utils.Green(accessKey.AccessKeyId, color))
+		if accessKey.AccessKeySecret != "" {
+			fmt.Printf(" - %s: %s\n", pad2("AccessKeySecret"), utils.Green("******", color))
+		}
+		if accessKey.AccessKeySecretFile != "" {
+			fmt.Printf(" - %s: %s\n", pad2("AccessKeySecretFile"), utils.Green(accessKey.AccessKeySecretFile, color))
+		}
+		if accessKey.AccessKeySecretEnv != "" {
+			fmt.Printf(" - %s: %s\n", pad2("AccessKeySecretEnv"), utils.Green(accessKey.AccessKeySecretEnv, color))
+		}
 	}
 }
 
@@ -101,6 +187,12 @@ func showAws(color bool, profile *config.CloudStsConfig) {
 	if profile.Aws != nil {
 		aws := profile.Aws
 		fmt.Printf(" %s: %s\n", pad("Region"), utils.Green(aws.Region, color))
+		if aws.StsEndpoint != "" {
+			fmt.Printf(" %s: %s\n", pad("StsEndpoint"), utils.Green(aws.StsEndpoint, color))
+		}
+		if aws.Partition != "" {
+			fmt.Printf(" %s: %s\n", pad("Partition"), utils.Green(aws.Partition, color))
+		}
 		fmt.Printf(" %s: %s\n", pad("RoleArn"), utils.Green(aws.RoleArn, color))
 		if aws.DurationSeconds > 0 {
 			fmt.Printf("  %s: %s seconds\n", pad("DurationSeconds"), utils.Green(fmt.Sprintf("%d", aws.DurationSeconds), color))
@@ -111,6 +203,118 @@ func showAws(color bool, profile *config.CloudStsConfig) {
 
 		oidcTokenProvider := aws.OidcTokenProvider
 		showOidcTokenProvider(color, oidcTokenProvider)
+
+		for i, assumeRole := range aws.AssumeRole {
+			fmt.Printf(" %s: %s\n", pad(fmt.Sprintf("AssumeRole #%d", i+1)), utils.Green(assumeRole.RoleArn, color))
+			if assumeRole.DurationSeconds > 0 {
+				fmt.Printf(" - %s: %s seconds\n", pad2("DurationSeconds"), utils.Green(fmt.Sprintf("%d", assumeRole.DurationSeconds), color))
+			}
+			if assumeRole.RoleSessionName != "" {
+				fmt.Printf(" - %s: %s\n", pad2("RoleSessionName"), utils.Green(assumeRole.RoleSessionName, color))
+			}
+			if assumeRole.ExternalId != "" {
+				fmt.Printf(" - %s: %s\n", pad2("ExternalId"), utils.Green(assumeRole.ExternalId, color))
+			}
+			if assumeRole.SourceIdentity != "" {
+				fmt.Printf(" - %s: %s\n", pad2("SourceIdentity"), utils.Green(assumeRole.SourceIdentity, color))
+			}
+			if assumeRole.Policy != "" {
+				fmt.Printf(" - %s: %s\n", pad2("Policy"), utils.Green(assumeRole.Policy, color))
+			}
+			if len(assumeRole.PolicyArns) > 0 {
+				fmt.Printf(" - %s: %s\n", pad2("PolicyArns"), utils.Green(strings.Join(assumeRole.PolicyArns, ", "), color))
+			}
+			var tagKeys []string
+			for tagKey := range assumeRole.Tags {
+				tagKeys = append(tagKeys, tagKey)
+			}
+			sort.Strings(tagKeys)
+			for _, tagKey := range tagKeys {
+				fmt.Printf(" - %s: %s\n", pad2("Tag "+tagKey), utils.Green(assumeRole.Tags[tagKey], color))
+			}
+			if len(assumeRole.TransitiveTagKeys) > 0 {
+				fmt.Printf(" - %s: %s\n", pad2("TransitiveTagKeys"), utils.Green(strings.Join(assumeRole.TransitiveTagKeys, ", "), color))
+			}
+			if assumeRole.MfaSerialNumber != "" {
+				fmt.Printf(" - %s: %s\n", pad2("MfaSerialNumber"), utils.Green(assumeRole.MfaSerialNumber, color))
+			}
+		}
+	}
+}
+
+func showAwsRolesAnywhere(color bool, profile *config.CloudStsConfig) {
+	if profile.AwsRolesAnywhere != nil {
+		rolesAnywhere := profile.AwsRolesAnywhere
+		fmt.Printf(" %s: %s\n", pad("Region"), utils.Green(rolesAnywhere.Region, color))
+		if rolesAnywhere.Endpoint != "" {
+			fmt.Printf(" %s: %s\n", pad("Endpoint"), utils.Green(rolesAnywhere.Endpoint, color))
+		}
+		fmt.Printf(" %s: %s\n", pad("TrustAnchorArn"), utils.Green(rolesAnywhere.TrustAnchorArn, color))
+		fmt.Printf(" %s: %s\n", pad("ProfileArn"), utils.Green(rolesAnywhere.ProfileArn, color))
+		fmt.Printf(" %s: %s\n", pad("RoleArn"), utils.Green(rolesAnywhere.RoleArn, color))
+		if rolesAnywhere.DurationSeconds > 0 {
+			fmt.Printf("  %s: %s seconds\n", pad("DurationSeconds"), utils.Green(fmt.Sprintf("%d", rolesAnywhere.DurationSeconds), color))
+		}
+		if rolesAnywhere.RoleSessionName != "" {
+			fmt.Printf("  %s: %s\n", pad("RoleSessionName"), utils.Green(rolesAnywhere.RoleSessionName, color))
+		}
+
+		x509Certificate := rolesAnywhere.X509Certificate
+		if x509Certificate != nil {
+			fmt.Printf(" %s: %s\n", pad("X509Certificate"), utils.Green(x509Certificate.CertificateFile, color))
+			if x509Certificate.CertificateChainFile != "" {
+				fmt.Printf(" - %s: %s\n", pad2("CertificateChainFile"), utils.Green(x509Certificate.CertificateChainFile, color))
+			}
+			certificateKeySigner := x509Certificate.CertificateKeySigner
+			if certificateKeySigner != nil {
+				showPkcs11(color, certificateKeySigner, "")
+				showYubiKeyPiv(color, certificateKeySigner, "")
+				showExternalCommand(color, certificateKeySigner, "")
+				showKeyFile(color, certificateKeySigner, "")
+			}
+		}
+	}
+}
+
+func showGcp(color bool, profile *config.CloudStsConfig) {
+	if profile.Gcp != nil {
+		gcp := profile.Gcp
+		fmt.Printf(" %s: %s\n", pad("Audience"), utils.Green(gcp.Audience, color))
+		if gcp.StsEndpoint != "" {
+			fmt.Printf(" %s: %s\n", pad("StsEndpoint"), utils.Green(gcp.StsEndpoint, color))
+		}
+		if gcp.Scope != "" {
+			fmt.Printf(" %s: %s\n", pad("Scope"), utils.Green(gcp.Scope, color))
+		}
+		if gcp.ServiceAccountEmail != "" {
+			fmt.Printf(" %s: %s\n", pad("ServiceAccountEmail"), utils.Green(gcp.ServiceAccountEmail, color))
+		}
+		if gcp.IamCredentialsEndpoint != "" {
+			fmt.Printf(" %s: %s\n", pad("IamCredentialsEndpoint"), utils.Green(gcp.IamCredentialsEndpoint, color))
+		}
+		if gcp.TokenLifetimeSeconds > 0 {
+			fmt.Printf("  %s: %s seconds\n", pad("TokenLifetimeSeconds"), utils.Green(fmt.Sprintf("%d", gcp.TokenLifetimeSeconds), color))
+		}
+
+		oidcTokenProvider := gcp.OidcTokenProvider
+		showOidcTokenProvider(color, oidcTokenProvider)
+	}
+}
+
+func showAzureAd(color bool, profile *config.CloudStsConfig) {
+	if profile.AzureAd != nil {
+		azureAd := profile.AzureAd
+		fmt.Printf(" %s: %s\n", pad("TenantId"), utils.Green(azureAd.TenantId, color))
+		fmt.Printf(" %s: %s\n", pad("ClientId"), utils.Green(azureAd.ClientId, color))
+		if azureAd.Scope != "" {
+			fmt.Printf(" %s: %s\n", pad("Scope"), utils.Green(azureAd.Scope, color))
+		}
+		if azureAd.AuthorityHost != "" {
+			fmt.Printf(" %s: %s\n", pad("AuthorityHost"), utils.Green(azureAd.AuthorityHost, color))
+		}
+
+		oidcTokenProvider := azureAd.OidcTokenProvider
+		showOidcTokenProvider(color, oidcTokenProvider)
 	}
 }
 
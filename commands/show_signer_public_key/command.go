@@ -55,6 +55,16 @@ func printClientAssertionSignerPublicKey(cloudStsConfig *config.CloudStsConfig)
 			oidcTokenProvider = cloudStsConfig.Aws.OidcTokenProvider
 		}
 	}
+	if cloudStsConfig.Gcp != nil {
+		if cloudStsConfig.Gcp.OidcTokenProvider != nil {
+			oidcTokenProvider = cloudStsConfig.Gcp.OidcTokenProvider
+		}
+	}
+	if cloudStsConfig.AzureAd != nil {
+		if cloudStsConfig.AzureAd.OidcTokenProvider != nil {
+			oidcTokenProvider = cloudStsConfig.AzureAd.OidcTokenProvider
+		}
+	}
 	if oidcTokenProvider != nil && oidcTokenProvider.OidcTokenProviderClientCredentials != nil {
 		oidcTokenProviderClientCredentials := oidcTokenProvider.OidcTokenProviderClientCredentials
 
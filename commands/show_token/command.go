@@ -1,9 +1,14 @@
 package show_token
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/oidc"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/urfave/cli/v2"
 )
 
@@ -50,16 +55,39 @@ func BuildCommand() *cli.Command {
 }
 
 func fetchAndShowToken(profile, oidcField string, forceNew bool, color bool) error {
+	ctx := context.Background()
 	options := &cloud.FetchCloudStsOptions{
 		ForceNew: forceNew,
 	}
 	oidcTokenType := oidc.GetOidcTokenType(oidcField)
 	options.FetchOidcTokenType = oidcTokenType
 
-	sts, _, err := cloud.FetchCloudStsFromDefaultConfig(profile, options)
+	profile, cloudStsConfig, err := config.FindProfile(profile)
+	if err != nil {
+		return fmt.Errorf("find profie `%s` error: %s", profile, err)
+	}
+	if cloudStsConfig.AlibabaCloud != nil && len(cloudStsConfig.AlibabaCloud.AssumeRoleChain) > 0 {
+		stsTokenHops, err := cloud.FetchAlibabaCloudStsChain(ctx, profile, cloudStsConfig, options)
+		if err != nil {
+			return err
+		}
+		return common.ShowStsTokenChain(stsTokenHops, true, color)
+	}
+	if cloudStsConfig.Aws != nil && len(cloudStsConfig.Aws.AssumeRole) > 0 {
+		awsStsTokenHops, err := cloud.FetchAwsStsChain(ctx, profile, cloudStsConfig, options)
+		if err != nil {
+			return err
+		}
+		return common.ShowAwsStsTokenChain(awsStsTokenHops, true, color)
+	}
+
+	credential, err := cloud.FetchCloudSts(ctx, profile, cloudStsConfig, options)
 	if err != nil {
 		return err
 	}
 
-	return common.ShowToken(sts, oidcTokenType, true, color)
+	formatOptions := &cloud_common.FormatOptions{
+		OidcField: oidcField,
+	}
+	return common.ShowToken(credential, formatOptions, true, color)
 }
@@ -0,0 +1,98 @@
+package token_file_writer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagTokenFile = &cli.StringFlag{
+		Name:     "token-file",
+		Usage:    "OIDC token file, written with mode 0600 and removed when exits",
+		Required: true,
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch the first OIDC token, ignore cache",
+	}
+	boolFlagPrintEnv = &cli.BoolFlag{
+		Name:  "print-env",
+		Usage: "Print environments of SDK-native OIDC credential provider after the first token written",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagTokenFile,
+		boolFlagForceNew,
+		boolFlagPrintEnv,
+	}
+	return &cli.Command{
+		Name:  "token-file-writer",
+		Usage: "Keep Alibaba Cloud OIDC token file up to date until interrupted",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			tokenFile := context.String("token-file")
+			forceNew := context.Bool("force-new")
+			printEnv := context.Bool("print-env")
+			return writeTokenFile(profile, tokenFile, forceNew, printEnv)
+		},
+	}
+}
+
+func writeTokenFile(profile, tokenFile string, forceNew, printEnv bool) error {
+	tokenFile, err := filepath.Abs(tokenFile)
+	if err != nil {
+		return fmt.Errorf("invalid token file: %s, %v", tokenFile, err)
+	}
+	profile, cloudStsConfig, err := config.FindProfile(profile)
+	if err != nil {
+		return err
+	}
+	writer, err := alibaba_cloud.NewOidcTokenFileWriter(profile, cloudStsConfig, tokenFile, forceNew)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	expiration, err := writer.Write(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		idaaslog.Info.PrintfLn("Remove OIDC token file: %s", tokenFile)
+		_ = os.Remove(tokenFile)
+	}()
+	if printEnv {
+		for _, env := range writer.Environ() {
+			key, value, _ := strings.Cut(env, "=")
+			utils.Stdout.Fprintf("export %s=%s\n", key, quoteShell(value))
+		}
+	}
+	writer.Run(ctx, expiration)
+	return nil
+}
+
+// quoteShell quote value with single quotes for POSIX shells, e.g. eval "$(token-file-writer ... --print-env)"
+func quoteShell(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
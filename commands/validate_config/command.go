@@ -0,0 +1,82 @@
+package validate_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagConfigFile = &cli.StringFlag{
+		Name:    "config-file",
+		Aliases: []string{"c"},
+		Usage:   "Config file, default ~/.aliyun/alibaba-cloud-idaas.json",
+	}
+	boolFlagSchema = &cli.BoolFlag{
+		Name:  "schema",
+		Usage: "Print JSON Schema of config file",
+	}
+	boolFlagNoColor = &cli.BoolFlag{
+		Name:  "no-color",
+		Usage: "Output without color",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagConfigFile,
+		boolFlagSchema,
+		boolFlagNoColor,
+	}
+	return &cli.Command{
+		Name:  "validate-config",
+		Usage: "Validate config file, or print JSON Schema of config file",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			if context.Bool("schema") {
+				return printJsonSchema()
+			}
+			color := !context.Bool("no-color")
+			return validateConfig(context.String("config-file"), color)
+		},
+	}
+}
+
+func printJsonSchema() error {
+	schema, err := json.MarshalIndent(config.GenerateJsonSchema(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal JSON Schema")
+	}
+	utils.Stdout.Println(string(schema))
+	return nil
+}
+
+func validateConfig(configFilename string, color bool) error {
+	if configFilename == "" {
+		var err error
+		configFilename, err = config.GetDefaultCloudCredentialConfigFile()
+		if err != nil {
+			return err
+		}
+	}
+	configContent, err := os.ReadFile(configFilename)
+	if err != nil {
+		return fmt.Errorf("read config file: %s failed: %v", configFilename, err)
+	}
+	validationErrors := config.ValidateCloudCredentialConfig(configContent)
+	if len(validationErrors) == 0 {
+		utils.Stderr.Println(utils.Green(fmt.Sprintf("Config file: %s is valid", configFilename), color))
+		return nil
+	}
+	for _, validationError := range validationErrors {
+		utils.Stderr.Fprintf("%s: %s\n", utils.Bold(validationError.Path, color), validationError.Message)
+	}
+	utils.Stderr.Println(utils.Red(fmt.Sprintf("Found %d error(s) in config file: %s",
+		len(validationErrors), configFilename), color))
+	return cli.Exit("", 1)
+}
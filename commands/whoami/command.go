@@ -0,0 +1,95 @@
+package whoami
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stringFlagProfile = &cli.StringFlag{
+		Name:    "profile",
+		Aliases: []string{"p"},
+		Usage:   "IDaaS Profile",
+	}
+	stringFlagEndpoint = &cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "Override STS endpoint, e.g. sts.aliyuncs.com, https://sts.amazonaws.com",
+	}
+	boolFlagNoColor = &cli.BoolFlag{
+		Name:  "no-color",
+		Usage: "Output without color",
+	}
+	boolFlagForceNew = &cli.BoolFlag{
+		Name:    "force-new",
+		Aliases: []string{"N"},
+		Usage:   "Force fetch cloud STS token, ignore cache (including OpenID configuration etc.)",
+	}
+)
+
+func BuildCommand() *cli.Command {
+	flags := []cli.Flag{
+		stringFlagProfile,
+		stringFlagEndpoint,
+		boolFlagNoColor,
+		boolFlagForceNew,
+	}
+	return &cli.Command{
+		Name:  "whoami",
+		Usage: "Verify cloud STS token and show caller identity",
+		Flags: flags,
+		Action: func(context *cli.Context) error {
+			profile := context.String("profile")
+			endpoint := context.String("endpoint")
+			color := !context.Bool("no-color")
+			forceNew := context.Bool("force-new")
+			return whoami(profile, endpoint, forceNew, color)
+		},
+	}
+}
+
+func whoami(profile, endpoint string, forceNew, color bool) error {
+	ctx := context.Background()
+	options := &cloud.FetchCloudStsOptions{
+		ForceNew: forceNew,
+	}
+	credential, cloudStsConfig, err := cloud.FetchCloudStsFromDefaultConfig(ctx, profile, options)
+	if err != nil {
+		return err
+	}
+
+	var callerIdentity *cloud_common.CallerIdentity
+	if alibabaCloudSts, ok := credential.(*alibaba_cloud.StsToken); ok {
+		callerIdentity, err = alibaba_cloud.GetCallerIdentity(ctx, alibabaCloudSts, cloudStsConfig.AlibabaCloud, endpoint)
+	} else if awsStsToken, ok := credential.(*aws.AwsStsToken); ok {
+		region, stsEndpoint := getAwsRegionAndEndpoint(cloudStsConfig)
+		if endpoint != "" {
+			stsEndpoint = endpoint
+		}
+		callerIdentity, err = aws.GetCallerIdentity(ctx, awsStsToken, region, stsEndpoint)
+	} else {
+		return fmt.Errorf("whoami only supports Alibaba Cloud and AWS STS token")
+	}
+	if err != nil {
+		return fmt.Errorf("credentials are rejected: %v", err)
+	}
+	common.ShowCallerIdentity(callerIdentity, true, color)
+	return nil
+}
+
+func getAwsRegionAndEndpoint(cloudStsConfig *config.CloudStsConfig) (string, string) {
+	if cloudStsConfig.Aws != nil {
+		return cloudStsConfig.Aws.Region, cloudStsConfig.Aws.StsEndpoint
+	}
+	if cloudStsConfig.AwsRolesAnywhere != nil {
+		return cloudStsConfig.AwsRolesAnywhere.Region, ""
+	}
+	return "", ""
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"path"
+	"sort"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
@@ -14,9 +16,13 @@ const (
 )
 
 type CloudCredentialConfig struct {
-	Version        string                     `json:"version"` // current version always ("1" - Version1)
-	CurrentProfile string                     `json:"current_profile"`
-	Profile        map[string]*CloudStsConfig `json:"profile"` // required
+	Schema         string                              `json:"$schema,omitempty"` // optional, JSON Schema for editors, see JsonSchemaId
+	Version        string                              `json:"version"`           // current version always ("1" - Version1)
+	CurrentProfile string                              `json:"current_profile"`
+	Profile        map[string]*CloudStsConfig          `json:"profile"`                  // required
+	Groups         map[string][]string                 `json:"groups,omitempty"`         // optional, group name to profiles, for execute --group
+	OidcProviders  map[string]*OidcTokenProviderConfig `json:"oidc_providers,omitempty"` // optional, named OIDC token providers, referenced by ref
+	Signers        map[string]*ExSingerConfig          `json:"signers,omitempty"`        // optional, named signers, referenced by ref
 }
 
 func FindProfile(profile string) (string, *CloudStsConfig, error) {
@@ -59,6 +65,52 @@ func (c *CloudCredentialConfig) FindProfile(profile string) (string, *CloudStsCo
 	}
 }
 
+// FindProfileByAcrRegistry find profile which ACR registries matches registry hostname
+func FindProfileByAcrRegistry(registry string) (string, *CloudStsConfig, error) {
+	cloudCredentialConfig, err := LoadDefaultCloudCredentialConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	profile, cloudStsConfig := cloudCredentialConfig.FindProfileByAcrRegistry(registry)
+	if cloudStsConfig == nil {
+		return "", nil, fmt.Errorf("no profile found for registry: %s", registry)
+	}
+	return profile, cloudStsConfig, nil
+}
+
+// FindProfileByAcrRegistry registries supports wildcard, e.g. *.cr.aliyuncs.com, exact match first,
+// then profiles in name order
+func (c *CloudCredentialConfig) FindProfileByAcrRegistry(registry string) (string, *CloudStsConfig) {
+	if c == nil {
+		return "", nil
+	}
+	var profiles []string
+	for profile := range c.Profile {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	for _, exactMatch := range []bool{true, false} {
+		for _, profile := range profiles {
+			cloudStsConfig := c.Profile[profile]
+			if cloudStsConfig == nil || cloudStsConfig.AlibabaCloud == nil || cloudStsConfig.AlibabaCloud.Acr == nil {
+				continue
+			}
+			for _, acrRegistry := range cloudStsConfig.AlibabaCloud.Acr.Registries {
+				matched := acrRegistry == registry
+				if !exactMatch {
+					matched, _ = path.Match(acrRegistry, registry)
+				}
+				if matched {
+					idaaslog.Info.PrintfLn("Profile found: %s for registry: %s", profile, registry)
+					return profile, cloudStsConfig
+				}
+			}
+		}
+	}
+	idaaslog.Info.PrintfLn("Profile not found for registry: %s", registry)
+	return "", nil
+}
+
 func TryParseProfileFromInput(profile string) (string, *CloudStsConfig) {
 	if profile != "" {
 		tempProfile := fmt.Sprintf("temp-%s", utils.Sha256ToHex(profile))
@@ -76,32 +128,156 @@ func TryParseProfileFromInput(profile string) (string, *CloudStsConfig) {
 }
 
 type CloudStsConfig struct {
-	AlibabaCloud *AlibabaCloudStsConfig   `json:"alibaba_cloud_sts"` // optional, AlibabaCloud, Aws or OidcToken one required
-	Aws          *AwsCloudStsConfig       `json:"aws_sts"`           // optional, see AlibabaCloud
-	OidcToken    *OidcTokenProviderConfig `json:"oidc_token"`        // optional, AlibabaCloud
-	Environments []string                 `json:"environments"`      // optional, environments for execute
-	Comment      string                   `json:"comment"`           // optional
+	Extends          string                   `json:"extends,omitempty"`  // optional, profile name, inherits config of the profile
+	AlibabaCloud     *AlibabaCloudStsConfig   `json:"alibaba_cloud_sts"`  // optional, AlibabaCloud, Aws, AwsRolesAnywhere, Gcp, AzureAd or OidcToken one required
+	Aws              *AwsCloudStsConfig       `json:"aws_sts"`            // optional, see AlibabaCloud
+	AwsRolesAnywhere *AwsRolesAnywhereConfig  `json:"aws_roles_anywhere"` // optional, see AlibabaCloud
+	Gcp              *GcpStsConfig            `json:"gcp_sts"`            // optional, see AlibabaCloud
+	AzureAd          *AzureAdConfig           `json:"azure_ad"`           // optional, see AlibabaCloud
+	OidcToken        *OidcTokenProviderConfig `json:"oidc_token"`         // optional, AlibabaCloud
+	Environments     []string                 `json:"environments"`       // optional, environments for execute
+	EnvTemplates     []string                 `json:"env_templates"`      // optional, e.g. TF_VAR_ak={{.AccessKeyId}}
+	Tags             []string                 `json:"tags"`               // optional, profile is member of groups with tag names
+	Comment          string                   `json:"comment"`            // optional
 }
 
 type AlibabaCloudStsConfig struct {
-	Region            string                   `json:"region"`
-	StsEndpoint       string                   `json:"sts_endpoint"`        // required
-	OidcProviderArn   string                   `json:"oidc_provider_arn"`   // required
-	RoleArn           string                   `json:"role_arn"`            // required
-	DurationSeconds   int64                    `json:"duration_seconds"`    // optional
-	RoleSessionName   string                   `json:"role_session_name"`   // optional, generate role session name when absent
-	OidcTokenProvider *OidcTokenProviderConfig `json:"oidc_token_provider"` // required at this moment
+	Region            string                           `json:"region"`
+	StsEndpoint       string                           `json:"sts_endpoint"`           // required
+	Network           string                           `json:"network"`                // optional, public(default), vpc or dualstack, used when StsEndpoint absent
+	StsConnectTimeout int                              `json:"sts_connect_timeout"`    // optional, milliseconds
+	StsReadTimeout    int                              `json:"sts_read_timeout"`       // optional, milliseconds
+	StsMaxAttempts    int                              `json:"sts_max_attempts"`       // optional, default 3, retry budget of STS endpoint
+	FallbackEndpoints []*AlibabaCloudStsEndpointConfig `json:"fallback_sts_endpoints"` // optional, tried in order on network or 5xx errors
+	OidcProviderArn   string                           `json:"oidc_provider_arn"`      // required
+	RoleArn           string                           `json:"role_arn"`               // required
+	DurationSeconds   int64                            `json:"duration_seconds"`       // optional
+	RoleSessionName   string                           `json:"role_session_name"`      // optional, generate role session name when absent
+	Policy            string                           `json:"policy"`                 // optional, inline session policy JSON
+	PolicyFile        string                           `json:"policy_file"`            // optional, session policy JSON file, Policy and PolicyFile only one
+	OidcTokenProvider *OidcTokenProviderConfig         `json:"oidc_token_provider"`    // optional *
+	CredentialSource  *AlibabaCloudCredentialSource    `json:"credential_source"`      // optional *, AssumeRole with source credentials
+	AssumeRoleChain   []*AlibabaCloudAssumeRoleConfig  `json:"assume_role_chain"`      // optional, assume roles after AssumeRoleWithOIDC in order
+	Acr               *AlibabaCloudAcrConfig           `json:"acr"`                    // optional, for docker credential helper
+	// * oidc_token_provider, credential_source requires one
+}
+
+// AlibabaCloudStsEndpointConfig fallback STS endpoint, timeouts and max attempts default to the profile's
+type AlibabaCloudStsEndpointConfig struct {
+	Endpoint       string `json:"endpoint"`        // required, e.g. sts.cn-shanghai.aliyuncs.com
+	ConnectTimeout int    `json:"connect_timeout"` // optional, milliseconds
+	ReadTimeout    int    `json:"read_timeout"`    // optional, milliseconds
+	MaxAttempts    int    `json:"max_attempts"`    // optional
+}
+
+// AlibabaCloudAcrConfig Container Registry, Enterprise Edition when InstanceId present, or Personal Edition
+// reference: https://help.aliyun.com/zh/acr/user-guide/log-on-to-an-instance
+type AlibabaCloudAcrConfig struct {
+	Registries []string `json:"registries"`  // required, registry hostnames, supports wildcard, e.g. registry.cn-hangzhou.aliyuncs.com
+	InstanceId string   `json:"instance_id"` // optional, Enterprise Edition instance ID, e.g. cri-xxxxxxxx
+	Region     string   `json:"region"`      // optional, parse from registry hostname or use profile's region when absent
+	Endpoint   string   `json:"endpoint"`    // optional, ACR API endpoint, default cr.<region>.aliyuncs.com, supports scheme, e.g. http://127.0.0.1:8080
+}
+
+// AlibabaCloudCredentialSource source credentials of AssumeRole, OidcProviderArn is not used
+type AlibabaCloudCredentialSource struct {
+	EcsRamRole *AlibabaCloudEcsRamRoleConfig `json:"ecs_ram_role"` // optional *
+	AccessKey  *AlibabaCloudAccessKeyConfig  `json:"access_key"`   // optional *
+	// * ecs_ram_role, access_key requires one
+}
+
+// AlibabaCloudEcsRamRoleConfig
+// reference: https://help.aliyun.com/zh/ecs/user-guide/attach-an-instance-ram-role-to-an-ecs-instance
+type AlibabaCloudEcsRamRoleConfig struct {
+	RoleName         string `json:"role_name"`         // optional, fetch from metadata when absent
+	MetadataEndpoint string `json:"metadata_endpoint"` // optional, default http://100.100.100.200
+	DisableImdsV1    bool   `json:"disable_imds_v1"`   // optional, fail when IMDSv2 token cannot be fetched
+}
+
+type AlibabaCloudAccessKeyConfig struct {
+	AccessKeyId         string `json:"access_key_id"`          // required
+	AccessKeySecret     string `json:"access_key_secret"`      // optional *
+	AccessKeySecretFile string `json:"access_key_secret_file"` // optional *
+	AccessKeySecretEnv  string `json:"access_key_secret_env"`  // optional *, environment variable name
+	// optional *, encrypted by `configure`, can only be decrypted on the same machine
+	AccessKeySecretEncrypted string `json:"access_key_secret_encrypted"`
+	// * access_key_secret, access_key_secret_file, access_key_secret_env, access_key_secret_encrypted requires one
+}
+
+// AlibabaCloudAssumeRoleConfig
+// reference: https://api.aliyun.com/document/Sts/2015-04-01/AssumeRole
+type AlibabaCloudAssumeRoleConfig struct {
+	RoleArn         string `json:"role_arn"`          // required
+	DurationSeconds int64  `json:"duration_seconds"`  // optional
+	Policy          string `json:"policy"`            // optional, inline session policy JSON
+	PolicyFile      string `json:"policy_file"`       // optional, session policy JSON file, Policy and PolicyFile only one
+	ExternalId      string `json:"external_id"`       // optional
+	RoleSessionName string `json:"role_session_name"` // optional, generate role session name when absent
 }
 
 type AwsCloudStsConfig struct {
 	Region            string                   `json:"region"`              // required
+	StsEndpoint       string                   `json:"sts_endpoint"`        // optional, e.g. VPC endpoint https://vpce-***.sts.us-east-1.vpce.amazonaws.com
+	Partition         string                   `json:"partition"`           // optional, aws(default), aws-cn or aws-us-gov
 	RoleArn           string                   `json:"role_arn"`            // required
 	DurationSeconds   int32                    `json:"duration_seconds"`    // optional
 	RoleSessionName   string                   `json:"role_session_name"`   // optional, generate role session name when absent
 	OidcTokenProvider *OidcTokenProviderConfig `json:"oidc_token_provider"` // required at this moment
+	AssumeRole        []*AwsAssumeRoleConfig   `json:"assume_role"`         // optional, assume roles after AssumeRoleWithWebIdentity in order
+}
+
+// AwsAssumeRoleConfig
+// reference: https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+type AwsAssumeRoleConfig struct {
+	RoleArn           string            `json:"role_arn"`            // required
+	DurationSeconds   int32             `json:"duration_seconds"`    // optional
+	RoleSessionName   string            `json:"role_session_name"`   // optional, generate role session name when absent
+	ExternalId        string            `json:"external_id"`         // optional
+	SourceIdentity    string            `json:"source_identity"`     // optional
+	Policy            string            `json:"policy"`              // optional, inline session policy
+	PolicyArns        []string          `json:"policy_arns"`         // optional, managed session policies
+	Tags              map[string]string `json:"tags"`                // optional, session tags
+	TransitiveTagKeys []string          `json:"transitive_tag_keys"` // optional, session tags passed to following hops
+	MfaSerialNumber   string            `json:"mfa_serial_number"`   // optional, prompt MFA token code when present
+}
+
+// AwsRolesAnywhereConfig
+// reference: https://docs.aws.amazon.com/rolesanywhere/latest/userguide/authentication-create-session.html
+type AwsRolesAnywhereConfig struct {
+	Region          string           `json:"region"`            // required
+	Endpoint        string           `json:"endpoint"`          // optional, default https://rolesanywhere.<region>.amazonaws.com
+	TrustAnchorArn  string           `json:"trust_anchor_arn"`  // required
+	ProfileArn      string           `json:"profile_arn"`       // required
+	RoleArn         string           `json:"role_arn"`          // required
+	RoleSessionName string           `json:"role_session_name"` // optional
+	DurationSeconds int32            `json:"duration_seconds"`  // optional
+	X509Certificate *PrivateCaConfig `json:"x509_certificate"`  // required, certificate_key_signer is required
+}
+
+// GcpStsConfig
+// reference: https://cloud.google.com/iam/docs/workload-identity-federation-with-other-providers
+type GcpStsConfig struct {
+	Audience               string                   `json:"audience"`                 // required, //iam.googleapis.com/projects/<project-number>/locations/global/workloadIdentityPools/<pool-id>/providers/<provider-id>
+	StsEndpoint            string                   `json:"sts_endpoint"`             // optional, default https://sts.googleapis.com/v1/token
+	Scope                  string                   `json:"scope"`                    // optional, default https://www.googleapis.com/auth/cloud-platform
+	ServiceAccountEmail    string                   `json:"service_account_email"`    // optional, impersonate service account when present
+	IamCredentialsEndpoint string                   `json:"iam_credentials_endpoint"` // optional, default https://iamcredentials.googleapis.com
+	TokenLifetimeSeconds   int64                    `json:"token_lifetime_seconds"`   // optional, service account access token lifetime
+	OidcTokenProvider      *OidcTokenProviderConfig `json:"oidc_token_provider"`      // required at this moment
+}
+
+// AzureAdConfig
+// reference: https://learn.microsoft.com/en-us/entra/workload-id/workload-identity-federation
+type AzureAdConfig struct {
+	TenantId          string                   `json:"tenant_id"`           // required
+	ClientId          string                   `json:"client_id"`           // required, application (client) ID with federated credential
+	Scope             string                   `json:"scope"`               // optional, default https://management.azure.com/.default
+	AuthorityHost     string                   `json:"authority_host"`      // optional, default https://login.microsoftonline.com
+	OidcTokenProvider *OidcTokenProviderConfig `json:"oidc_token_provider"` // required at this moment
 }
 
 type OidcTokenProviderConfig struct {
+	Ref                                string                                    `json:"ref,omitempty"`      // optional, name in oidc_providers, resolved when config is parsed
 	OidcTokenProviderClientCredentials *OidcTokenProviderClientCredentialsConfig `json:"client_credentials"` // optional *
 	OidcTokenProviderDeviceCode        *OidcTokenProviderDeviceCodeConfig        `json:"device_code"`        // optional *
 	// * only requires one
@@ -182,6 +358,7 @@ type OidcTokenConfig struct {
 }
 
 type ExSingerConfig struct {
+	Ref             string                         `json:"ref,omitempty"`    // optional, name in signers, resolved when config is parsed
 	KeyID           string                         `json:"key_id"`           // optional, PCA do not requires key_id
 	Algorithm       string                         `json:"algorithm"`        // required, RS256, RS384, RS512, ES256, ES384, ES512
 	Pkcs11          *ExSignerPkcs11Config          `json:"pkcs11"`           // optional *
@@ -201,7 +378,7 @@ type ExSignerPkcs11Config struct {
 type ExSignerYubikeyPivConfig struct {
 	Slot      string `json:"slot"`       // required, auth,sign or rN
 	Pin       string `json:"pin"`        // optional, or set env YUBIKEY_PIN
-	PinPolicy string `json:"pin_policy"` // required, none, once or always
+	PinPolicy string `json:"pin_policy"` // required, never, once or always
 }
 
 type ExSignerExternalCommandConfig struct {
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 )
 
 func (c *CloudStsConfig) Digest() string {
@@ -12,23 +14,130 @@ func (c *CloudStsConfig) Digest() string {
 		return ""
 	}
 	// Comment do not effect digest(cache)
-	return digest(c.AlibabaCloud.Digest(), c.Aws.Digest(), c.OidcToken.Digest())
+	return digest(c.AlibabaCloud.Digest(), c.Aws.Digest(), c.OidcToken.Digest(), c.Gcp.Digest(), c.AzureAd.Digest(),
+		c.AwsRolesAnywhere.Digest())
 }
 
 func (c *AlibabaCloudStsConfig) Digest() string {
 	if c == nil {
 		return ""
 	}
-	return digest(c.Region, c.StsEndpoint, c.OidcProviderArn, c.RoleArn,
-		fmt.Sprintf("%d", c.DurationSeconds), c.RoleSessionName, c.OidcTokenProvider.Digest())
+	return c.AssumeRoleChainDigest(len(c.AssumeRoleChain))
+}
+
+// AssumeRoleChainDigest digest of AssumeRoleWithOIDC and the first `hops` AssumeRole calls,
+// hops 0 means AssumeRoleWithOIDC only, every hop is cached under its own digest
+func (c *AlibabaCloudStsConfig) AssumeRoleChainDigest(hops int) string {
+	if c == nil {
+		return ""
+	}
+	// StsConnectTimeout, StsReadTimeout, StsMaxAttempts, FallbackEndpoints, Acr do not effect digest(cache)
+	chainDigest := digest(c.Region, c.StsEndpoint, c.OidcProviderArn, c.RoleArn,
+		fmt.Sprintf("%d", c.DurationSeconds), c.RoleSessionName, c.OidcTokenProvider.Digest(),
+		c.Policy, policyFileContent(c.PolicyFile), c.CredentialSource.Digest(), c.Network)
+	for i := 0; i < hops && i < len(c.AssumeRoleChain); i++ {
+		chainDigest = digest(chainDigest, c.AssumeRoleChain[i].Digest())
+	}
+	return chainDigest
+}
+
+func (c *AlibabaCloudAcrConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(strings.Join(c.Registries, ","), c.InstanceId, c.Region, c.Endpoint)
+}
+
+func (c *AlibabaCloudCredentialSource) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(c.EcsRamRole.Digest(), c.AccessKey.Digest())
+}
+
+func (c *AlibabaCloudEcsRamRoleConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest("ecs_ram_role", c.RoleName, c.MetadataEndpoint, fmt.Sprintf("%t", c.DisableImdsV1))
+}
+
+func (c *AlibabaCloudAccessKeyConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	// AccessKeySecret do not effect digest(cache)
+	return digest("access_key", c.AccessKeyId, c.AccessKeySecretFile, c.AccessKeySecretEnv)
+}
+
+func (c *AlibabaCloudAssumeRoleConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(c.RoleArn, fmt.Sprintf("%d", c.DurationSeconds), c.Policy, c.ExternalId, c.RoleSessionName,
+		policyFileContent(c.PolicyFile))
 }
 
 func (c *AwsCloudStsConfig) Digest() string {
 	if c == nil {
 		return ""
 	}
-	return digest(c.Region, c.RoleArn, fmt.Sprintf("%d", c.DurationSeconds),
-		c.RoleSessionName, c.OidcTokenProvider.Digest())
+	return c.AssumeRoleChainDigest(len(c.AssumeRole))
+}
+
+// AssumeRoleChainDigest digest of AssumeRoleWithWebIdentity and the first `hops` AssumeRole calls,
+// hops 0 means AssumeRoleWithWebIdentity only, every hop is cached under its own digest
+func (c *AwsCloudStsConfig) AssumeRoleChainDigest(hops int) string {
+	if c == nil {
+		return ""
+	}
+	chainDigest := digest(c.Region, c.RoleArn, fmt.Sprintf("%d", c.DurationSeconds),
+		c.RoleSessionName, c.OidcTokenProvider.Digest(), c.StsEndpoint, c.Partition)
+	for i := 0; i < hops && i < len(c.AssumeRole); i++ {
+		chainDigest = digest(chainDigest, c.AssumeRole[i].Digest())
+	}
+	return chainDigest
+}
+
+func (c *AwsAssumeRoleConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	var tagKeys []string
+	for k := range c.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	var tags []string
+	for _, k := range tagKeys {
+		tags = append(tags, k+"="+c.Tags[k])
+	}
+	return digest(c.RoleArn, fmt.Sprintf("%d", c.DurationSeconds), c.RoleSessionName,
+		c.ExternalId, c.SourceIdentity, c.Policy, strings.Join(c.PolicyArns, ","),
+		strings.Join(tags, ","), strings.Join(c.TransitiveTagKeys, ","), c.MfaSerialNumber)
+}
+
+func (c *AwsRolesAnywhereConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(c.Region, c.Endpoint, c.TrustAnchorArn, c.ProfileArn, c.RoleArn, c.RoleSessionName,
+		fmt.Sprintf("%d", c.DurationSeconds), c.X509Certificate.Digest())
+}
+
+func (c *GcpStsConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(c.Audience, c.StsEndpoint, c.Scope, c.ServiceAccountEmail, c.IamCredentialsEndpoint,
+		fmt.Sprintf("%d", c.TokenLifetimeSeconds), c.OidcTokenProvider.Digest())
+}
+
+func (c *AzureAdConfig) Digest() string {
+	if c == nil {
+		return ""
+	}
+	return digest(c.TenantId, c.ClientId, c.Scope, c.AuthorityHost, c.OidcTokenProvider.Digest())
 }
 
 func (c *OidcTokenProviderConfig) Digest() string {
@@ -139,3 +248,16 @@ func fileModTime(filename string) string {
 	}
 	return fmt.Sprintf("%x", fileInfo.ModTime().Unix())
 }
+
+// policyFileContent policy is sent to STS as trimmed content, the same policy in another path or touched file
+// shares cache, empty when file cannot be read, fetch fails later
+func policyFileContent(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	policyBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(policyBytes))
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+// ConfigDocument config file edited as JSON document by configure, fields unknown to CloudCredentialConfig
+// (e.g. written by newer versions) are preserved
+type ConfigDocument struct {
+	Filename string
+	document map[string]any
+}
+
+func LoadConfigDocument(configFilename string) (*ConfigDocument, error) {
+	configContent, err := os.ReadFile(configFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("config file: %s not found, run `alibaba-cloud-idaas configure init` first",
+				configFilename)
+		}
+		return nil, errors.Wrapf(err, "failed to read config file: %s", configFilename)
+	}
+	if _, err := ParseCloudCredentialConfig(configContent); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file: %s", configFilename)
+	}
+	var document map[string]any
+	if err := unmarshalDocument(configContent, &document); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file: %s", configFilename)
+	}
+	if document == nil {
+		document = map[string]any{}
+	}
+	return &ConfigDocument{
+		Filename: configFilename,
+		document: document,
+	}, nil
+}
+
+// ProfileNames profile names in name order
+func (d *ConfigDocument) ProfileNames() []string {
+	var names []string
+	for name := range d.profiles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns profile document, changes are saved by SetProfile
+func (d *ConfigDocument) GetProfile(profile string) (map[string]any, bool) {
+	profileDocument, ok := d.profiles()[profile].(map[string]any)
+	return profileDocument, ok
+}
+
+// SetProfile set profile document, profile document must be decoded as CloudStsConfig
+func (d *ConfigDocument) SetProfile(profile string, profileDocument map[string]any) error {
+	if _, err := DecodeProfileDocument(profileDocument); err != nil {
+		return err
+	}
+	d.profiles()[profile] = profileDocument
+	return nil
+}
+
+// RemoveProfile remove profile, and remove it from groups and current profile, profile extended by others
+// can not be removed
+func (d *ConfigDocument) RemoveProfile(profile string) error {
+	profiles := d.profiles()
+	if _, ok := profiles[profile]; !ok {
+		return fmt.Errorf("profile: %s not found", profile)
+	}
+	if extendingProfiles := d.findExtendingProfiles(profile); len(extendingProfiles) > 0 {
+		return fmt.Errorf("profile: %s is extended by: %s", profile, strings.Join(extendingProfiles, ", "))
+	}
+	delete(profiles, profile)
+	d.replaceGroupMember(profile, "")
+	if d.CurrentProfile() == profile {
+		delete(d.document, "current_profile")
+	}
+	return nil
+}
+
+// RenameProfile rename profile, groups, extends and current profile are renamed
+func (d *ConfigDocument) RenameProfile(profile, newProfile string) error {
+	profiles := d.profiles()
+	profileDocument, ok := profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile: %s not found", profile)
+	}
+	if _, ok := profiles[newProfile]; ok {
+		return fmt.Errorf("profile: %s already exists", newProfile)
+	}
+	extendingProfiles := d.findExtendingProfiles(profile)
+	delete(profiles, profile)
+	profiles[newProfile] = profileDocument
+	for _, extendingProfile := range extendingProfiles {
+		profiles[extendingProfile].(map[string]any)["extends"] = newProfile
+	}
+	d.replaceGroupMember(profile, newProfile)
+	if d.CurrentProfile() == profile {
+		d.document["current_profile"] = newProfile
+	}
+	return nil
+}
+
+func (d *ConfigDocument) CurrentProfile() string {
+	currentProfile, _ := d.document["current_profile"].(string)
+	return currentProfile
+}
+
+func (d *ConfigDocument) UseProfile(profile string) error {
+	if _, ok := d.profiles()[profile]; !ok {
+		return fmt.Errorf("profile: %s not found", profile)
+	}
+	d.document["current_profile"] = profile
+	return nil
+}
+
+// Save validate and write config file atomically with mode 0600
+func (d *ConfigDocument) Save() error {
+	configContent, err := json.MarshalIndent(d.document, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal config")
+	}
+	if _, err := ParseCloudCredentialConfig(configContent); err != nil {
+		return fmt.Errorf("invalid config: %v", errors.Cause(err))
+	}
+	return utils.WriteFileAtomic(d.Filename, append(configContent, '\n'), 0600)
+}
+
+func (d *ConfigDocument) profiles() map[string]any {
+	profiles, ok := d.document["profile"].(map[string]any)
+	if !ok {
+		profiles = map[string]any{}
+		d.document["profile"] = profiles
+	}
+	return profiles
+}
+
+// findExtendingProfiles profiles extends profile in name order
+func (d *ConfigDocument) findExtendingProfiles(profile string) []string {
+	var extendingProfiles []string
+	for _, name := range d.ProfileNames() {
+		profileDocument, ok := d.GetProfile(name)
+		if ok && profileDocument["extends"] == profile {
+			extendingProfiles = append(extendingProfiles, name)
+		}
+	}
+	return extendingProfiles
+}
+
+// replaceGroupMember replace profile in groups, or remove it when newProfile is empty
+func (d *ConfigDocument) replaceGroupMember(profile, newProfile string) {
+	groups, ok := d.document["groups"].(map[string]any)
+	if !ok {
+		return
+	}
+	for group, members := range groups {
+		memberList, ok := members.([]any)
+		if !ok {
+			continue
+		}
+		var newMembers []any
+		for _, member := range memberList {
+			if member == profile {
+				if newProfile == "" {
+					continue
+				}
+				member = newProfile
+			}
+			newMembers = append(newMembers, member)
+		}
+		if newMembers == nil {
+			newMembers = []any{}
+		}
+		groups[group] = newMembers
+	}
+}
+
+// DecodeProfileDocument decode profile document as CloudStsConfig
+func DecodeProfileDocument(profileDocument map[string]any) (*CloudStsConfig, error) {
+	profileContent, err := json.Marshal(profileDocument)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal profile")
+	}
+	var cloudStsConfig CloudStsConfig
+	if err := json.Unmarshal(profileContent, &cloudStsConfig); err != nil {
+		return nil, fmt.Errorf("invalid profile: %v", err)
+	}
+	return &cloudStsConfig, nil
+}
+
+// unmarshalDocument numbers are decoded as json.Number, so they are written as is
+func unmarshalDocument(content []byte, document any) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	return decoder.Decode(document)
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const documentTestConfig = `{
+  "version": "1",
+  "current_profile": "base",
+  "future_field": {"enabled": true},
+  "groups": {"g": ["base", "child"]},
+  "profile": {
+    "base": {"future_profile_field": 1.50, "alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role1"}},
+    "child": {"extends": "base", "alibaba_cloud_sts": {"role_arn": "role2"}},
+    "other": {"alibaba_cloud_sts": {"region": "cn-shanghai", "role_arn": "role3", "future_sts_field": "x"}}
+  }
+}`
+
+func TestConfigDocument(t *testing.T) {
+	tests := []struct {
+		name           string
+		edit           func(d *ConfigDocument) error
+		currentProfile string
+		groups         map[string]any
+		profiles       []string
+		childExtends   string
+	}{
+		{
+			name:           "save without change",
+			edit:           func(d *ConfigDocument) error { return nil },
+			currentProfile: "base",
+			groups:         map[string]any{"g": []any{"base", "child"}},
+			profiles:       []string{"base", "child", "other"},
+		},
+		{
+			name: "set profile",
+			edit: func(d *ConfigDocument) error {
+				return d.SetProfile("new", map[string]any{
+					"alibaba_cloud_sts": map[string]any{"region": "cn-beijing", "role_arn": "role4"},
+				})
+			},
+			currentProfile: "base",
+			groups:         map[string]any{"g": []any{"base", "child"}},
+			profiles:       []string{"base", "child", "new", "other"},
+		},
+		{
+			name:           "remove profile",
+			edit:           func(d *ConfigDocument) error { return d.RemoveProfile("child") },
+			currentProfile: "base",
+			groups:         map[string]any{"g": []any{"base"}},
+			profiles:       []string{"base", "other"},
+		},
+		{
+			name:           "rename profile",
+			edit:           func(d *ConfigDocument) error { return d.RenameProfile("base", "renamed") },
+			currentProfile: "renamed",
+			groups:         map[string]any{"g": []any{"renamed", "child"}},
+			profiles:       []string{"child", "other", "renamed"},
+			childExtends:   "renamed",
+		},
+		{
+			name:           "use profile",
+			edit:           func(d *ConfigDocument) error { return d.UseProfile("other") },
+			currentProfile: "other",
+			groups:         map[string]any{"g": []any{"base", "child"}},
+			profiles:       []string{"base", "child", "other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFilename := writeDocumentTestConfig(t, documentTestConfig)
+			configDocument, err := LoadConfigDocument(configFilename)
+			if err != nil {
+				t.Fatalf("load config failed: %v", err)
+			}
+			if err := tt.edit(configDocument); err != nil {
+				t.Fatalf("edit config failed: %v", err)
+			}
+			if err := configDocument.Save(); err != nil {
+				t.Fatalf("save config failed: %v", err)
+			}
+			document := readDocumentTestConfig(t, configFilename)
+
+			assertEqual(t, "future_field", map[string]any{"enabled": true}, document["future_field"])
+			profiles := document["profile"].(map[string]any)
+			if base, ok := profiles["base"].(map[string]any); ok {
+				assertEqual(t, "future_profile_field", json.Number("1.50"), base["future_profile_field"])
+			}
+			if renamed, ok := profiles["renamed"].(map[string]any); ok {
+				assertEqual(t, "future_profile_field", json.Number("1.50"), renamed["future_profile_field"])
+			}
+			otherSts := profiles["other"].(map[string]any)["alibaba_cloud_sts"].(map[string]any)
+			assertEqual(t, "future_sts_field", "x", otherSts["future_sts_field"])
+
+			assertEqual(t, "current_profile", tt.currentProfile, document["current_profile"])
+			assertEqual(t, "groups", tt.groups, document["groups"])
+			reloadedDocument, err := LoadConfigDocument(configFilename)
+			if err != nil {
+				t.Fatalf("reload config failed: %v", err)
+			}
+			assertEqual(t, "profiles", tt.profiles, reloadedDocument.ProfileNames())
+			if child, ok := profiles["child"].(map[string]any); ok && tt.childExtends != "" {
+				assertEqual(t, "child extends", tt.childExtends, child["extends"])
+			}
+		})
+	}
+}
+
+func TestConfigDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(d *ConfigDocument) error
+		message string
+	}{
+		{
+			name:    "remove extended profile",
+			edit:    func(d *ConfigDocument) error { return d.RemoveProfile("base") },
+			message: "profile: base is extended by: child",
+		},
+		{
+			name:    "remove unknown profile",
+			edit:    func(d *ConfigDocument) error { return d.RemoveProfile("missing") },
+			message: "profile: missing not found",
+		},
+		{
+			name:    "rename to existing profile",
+			edit:    func(d *ConfigDocument) error { return d.RenameProfile("base", "other") },
+			message: "profile: other already exists",
+		},
+		{
+			name:    "use unknown profile",
+			edit:    func(d *ConfigDocument) error { return d.UseProfile("missing") },
+			message: "profile: missing not found",
+		},
+		{
+			name: "set invalid profile",
+			edit: func(d *ConfigDocument) error {
+				return d.SetProfile("new", map[string]any{"alibaba_cloud_sts": "invalid"})
+			},
+			message: "invalid profile: ",
+		},
+		{
+			name: "save extends cycle",
+			edit: func(d *ConfigDocument) error {
+				profileDocument, _ := d.GetProfile("base")
+				profileDocument["extends"] = "child"
+				return d.Save()
+			},
+			message: "invalid config: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFilename := writeDocumentTestConfig(t, documentTestConfig)
+			configDocument, err := LoadConfigDocument(configFilename)
+			if err != nil {
+				t.Fatalf("load config failed: %v", err)
+			}
+			err = tt.edit(configDocument)
+			if err == nil {
+				t.Fatalf("expected error: %s", tt.message)
+			}
+			if !strings.HasPrefix(err.Error(), tt.message) {
+				t.Errorf("expected error: %s, got: %v", tt.message, err)
+			}
+			configContent, err := os.ReadFile(configFilename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqual(t, "config file", documentTestConfig, string(configContent))
+		})
+	}
+}
+
+func TestLoadConfigDocumentNotFound(t *testing.T) {
+	configFilename := filepath.Join(t.TempDir(), "config.json")
+	_, err := LoadConfigDocument(configFilename)
+	if err == nil || !strings.Contains(err.Error(), "run `alibaba-cloud-idaas configure init` first") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func writeDocumentTestConfig(t *testing.T, configContent string) string {
+	t.Helper()
+	configFilename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFilename, []byte(configContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return configFilename
+}
+
+func readDocumentTestConfig(t *testing.T, configFilename string) map[string]any {
+	t.Helper()
+	configContent, err := os.ReadFile(configFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]any
+	if err := unmarshalDocument(configContent, &document); err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func assertEqual(t *testing.T, name string, expected, actual any) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("%s: expected: %#v, got: %#v", name, expected, actual)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+)
+
+// FindGroupProfiles find member profiles of group from default config
+func FindGroupProfiles(group string) ([]string, error) {
+	cloudCredentialConfig, err := LoadDefaultCloudCredentialConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cloudCredentialConfig.FindGroupProfiles(group)
+}
+
+// FindGroupProfiles members are profiles of groups in order, then profiles tagged with group in name order
+func (c *CloudCredentialConfig) FindGroupProfiles(group string) ([]string, error) {
+	if c == nil {
+		return nil, fmt.Errorf("group: %s not found", group)
+	}
+	var profiles []string
+	foundProfiles := map[string]bool{}
+	for _, profile := range c.Groups[group] {
+		if c.Profile[profile] == nil {
+			return nil, fmt.Errorf("group: %s profile: %s not found", group, profile)
+		}
+		if !foundProfiles[profile] {
+			foundProfiles[profile] = true
+			profiles = append(profiles, profile)
+		}
+	}
+	var taggedProfiles []string
+	for profile, cloudStsConfig := range c.Profile {
+		if cloudStsConfig != nil && !foundProfiles[profile] && containsTag(cloudStsConfig.Tags, group) {
+			taggedProfiles = append(taggedProfiles, profile)
+		}
+	}
+	sort.Strings(taggedProfiles)
+	profiles = append(profiles, taggedProfiles...)
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("group: %s not found", group)
+	}
+	idaaslog.Info.PrintfLn("Group: %s profiles: %v", group, profiles)
+	return profiles, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
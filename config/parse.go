@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
 	"io"
 	"os"
@@ -22,16 +23,14 @@ func CreateCloudCredentialConfig(configFilename string) error {
 		Version: Version1,
 		Profile: map[string]*CloudStsConfig{},
 	}
-	configBytes, marshalErr := json.Marshal(config)
+	configBytes, marshalErr := json.MarshalIndent(config, "", "  ")
 	if marshalErr != nil {
 		return errors.Wrap(marshalErr, "failed to marshal config")
 	}
-	configFile, createErr := os.Create(configFilename)
-	if createErr != nil {
-		return errors.Wrap(createErr, "failed to create config file")
+	if mkdirErr := os.MkdirAll(filepath.Dir(configFilename), 0700); mkdirErr != nil {
+		return errors.Wrap(mkdirErr, "failed to create config dir")
 	}
-	defer configFile.Close()
-	_, writeErr := configFile.Write(configBytes)
+	writeErr := utils.WriteFileAtomic(configFilename, append(configBytes, '\n'), 0600)
 	if writeErr != nil {
 		return errors.Wrap(writeErr, "failed to write config file")
 	}
@@ -74,19 +73,31 @@ func ReadCloudCredentialConfig(configFilename string) (*CloudCredentialConfig, e
 		return nil, errors.Wrapf(readAllErr, "failed to read config file: %s", configFilename)
 	}
 
+	config, err := ParseCloudCredentialConfig(configContent)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config file: %s", configFilename)
+	}
+	return config, nil
+}
+
+// ParseCloudCredentialConfig parse config content, e.g. config embedded or loaded by other programs
+func ParseCloudCredentialConfig(configContent []byte) (*CloudCredentialConfig, error) {
 	var config CloudCredentialConfig
 	configUnmarshalErr := json.Unmarshal(configContent, &config)
 	if configUnmarshalErr != nil {
-		return nil, errors.Wrapf(configUnmarshalErr, "failed to unmarshal config file: %s", configFilename)
+		return nil, errors.Wrap(configUnmarshalErr, "failed to unmarshal config")
 	}
 
 	// current we only know version1
 	if config.Version != Version1 {
-		return nil, errors.Errorf("config file version %s is not supported, "+
+		return nil, errors.Errorf("config version %s is not supported, "+
 			"please consider upgrade alibaba-cloud-idaas, get latest version from: %s",
 			config.Version, constants.UrlIdaasProduct)
 	}
 
+	if err := config.resolve(configContent); err != nil {
+		return nil, errors.Wrap(err, "failed to resolve profiles")
+	}
 	return &config, nil
 }
 
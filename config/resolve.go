@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	oidcTokenProviderConfigType = reflect.TypeOf(OidcTokenProviderConfig{})
+	exSingerConfigType          = reflect.TypeOf(ExSingerConfig{})
+)
+
+// rawCloudCredentialConfig profiles, OIDC providers and signers before resolution
+type rawCloudCredentialConfig struct {
+	Profile       map[string]map[string]any `json:"profile"`
+	OidcProviders map[string]map[string]any `json:"oidc_providers"`
+	Signers       map[string]map[string]any `json:"signers"`
+}
+
+// resolve profiles `extends` and `ref` of oidc_token_provider and signers, resolution is done on JSON documents:
+//   - profile extends profile: objects are merged recursively, other values (including arrays) of profile replace
+//     values of extended profile, null removes inherited value, tags are not inherited, object with `ref`
+//     replaces inherited object
+//   - oidc_token_provider, client_assertion_singer or certificate_key_signer with `ref`: named block in
+//     oidc_providers or signers is merged the same way, fields besides `ref` override named block
+//
+// errors are *ValidationError with JSON path, cycles are reported with the chain
+func (c *CloudCredentialConfig) resolve(configContent []byte) error {
+	var rawConfig rawCloudCredentialConfig
+	decoder := json.NewDecoder(bytes.NewReader(configContent))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rawConfig); err != nil {
+		return &ValidationError{Path: "$", Message: fmt.Sprintf("invalid config: %s", err)}
+	}
+	r := &resolver{
+		rawConfig:        &rawConfig,
+		resolvedProfiles: map[string]map[string]any{},
+	}
+
+	oidcProviders := map[string]*OidcTokenProviderConfig{}
+	for _, name := range sortedKeys(rawConfig.OidcProviders) {
+		var oidcProvider OidcTokenProviderConfig
+		if err := r.resolveNamed(jsonPath("$.oidc_providers", name), "oidc provider", name, &oidcProvider); err != nil {
+			return err
+		}
+		oidcProviders[name] = &oidcProvider
+	}
+	signers := map[string]*ExSingerConfig{}
+	for _, name := range sortedKeys(rawConfig.Signers) {
+		var signer ExSingerConfig
+		if err := r.resolveNamed(jsonPath("$.signers", name), "signer", name, &signer); err != nil {
+			return err
+		}
+		signers[name] = &signer
+	}
+	profiles := map[string]*CloudStsConfig{}
+	for _, name := range sortedKeys(rawConfig.Profile) {
+		path := jsonPath("$.profile", name)
+		if rawConfig.Profile[name] == nil {
+			profiles[name] = nil
+			continue
+		}
+		profileDocument, err := r.resolveProfile(name, nil)
+		if err != nil {
+			return err
+		}
+		resolvedDocument, err := r.resolveRefs(path, profileDocument, reflect.TypeOf(CloudStsConfig{}), nil)
+		if err != nil {
+			return err
+		}
+		var profile CloudStsConfig
+		if err := decodeResolved(path, resolvedDocument, &profile); err != nil {
+			return err
+		}
+		profiles[name] = &profile
+	}
+
+	c.Profile = profiles
+	if len(oidcProviders) > 0 {
+		c.OidcProviders = oidcProviders
+	}
+	if len(signers) > 0 {
+		c.Signers = signers
+	}
+	return nil
+}
+
+type resolver struct {
+	rawConfig        *rawCloudCredentialConfig
+	resolvedProfiles map[string]map[string]any
+}
+
+// resolveProfile merge profile onto extended profiles, extending is the chain of profiles being resolved
+func (r *resolver) resolveProfile(profile string, extending []string) (map[string]any, error) {
+	if resolvedProfile, ok := r.resolvedProfiles[profile]; ok {
+		return resolvedProfile, nil
+	}
+	path := jsonPath("$.profile", profile)
+	for i, extendingProfile := range extending {
+		if extendingProfile == profile {
+			cycle := append(append([]string{}, extending[i:]...), profile)
+			return nil, &ValidationError{
+				Path:    jsonPath(jsonPath("$.profile", extending[len(extending)-1]), "extends"),
+				Message: fmt.Sprintf("extends cycle: %s", strings.Join(cycle, " -> ")),
+			}
+		}
+	}
+	rawProfile := r.rawConfig.Profile[profile]
+	resolvedProfile := rawProfile
+	if extendsValue, ok := rawProfile["extends"]; ok && extendsValue != nil {
+		extends, ok := extendsValue.(string)
+		if !ok || extends == "" {
+			return nil, &ValidationError{Path: jsonPath(path, "extends"), Message: "expected profile name"}
+		}
+		if r.rawConfig.Profile[extends] == nil {
+			return nil, &ValidationError{
+				Path:    jsonPath(path, "extends"),
+				Message: fmt.Sprintf("profile: %s not found", extends),
+			}
+		}
+		extendedProfile, err := r.resolveProfile(extends, append(extending, profile))
+		if err != nil {
+			return nil, err
+		}
+		// tags are group membership of the extended profile itself, children join groups by their own tags
+		inheritedProfile := map[string]any{}
+		for k, v := range extendedProfile {
+			if k != "tags" {
+				inheritedProfile[k] = v
+			}
+		}
+		resolvedProfile = mergeDocument(inheritedProfile, rawProfile)
+	}
+	r.resolvedProfiles[profile] = resolvedProfile
+	return resolvedProfile, nil
+}
+
+// resolveNamed resolve named block in oidc_providers or signers
+func (r *resolver) resolveNamed(path, kind, name string, target any) error {
+	t := reflect.TypeOf(target).Elem()
+	resolvedDocument, err := r.resolveRefs(path, map[string]any{"ref": name}, t, nil)
+	if err != nil {
+		return err
+	}
+	return decodeResolved(path, resolvedDocument, target)
+}
+
+// resolveRefs replace `ref` of OIDC providers and signers in document of type t,
+// referencing is the chain of refs being resolved, e.g. signer:yubikey1
+func (r *resolver) resolveRefs(path string, value any, t reflect.Type, referencing []string) (any, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			// type mismatches are reported by decoding
+			return value, nil
+		}
+		if refValue, ok := object["ref"]; ok && (t == oidcTokenProviderConfigType || t == exSingerConfigType) {
+			kind, namedBlocks, namedPath := "oidc provider", r.rawConfig.OidcProviders, "$.oidc_providers"
+			if t == exSingerConfigType {
+				kind, namedBlocks, namedPath = "signer", r.rawConfig.Signers, "$.signers"
+			}
+			ref, ok := refValue.(string)
+			if !ok || ref == "" {
+				return nil, &ValidationError{Path: jsonPath(path, "ref"), Message: fmt.Sprintf("expected %s name", kind)}
+			}
+			if namedBlocks[ref] == nil {
+				return nil, &ValidationError{
+					Path:    jsonPath(path, "ref"),
+					Message: fmt.Sprintf("%s: %s not found", kind, ref),
+				}
+			}
+			key := kind + ": " + ref
+			for i, referencingKey := range referencing {
+				if referencingKey == key {
+					cycle := append(append([]string{}, referencing[i:]...), key)
+					return nil, &ValidationError{
+						Path:    jsonPath(path, "ref"),
+						Message: fmt.Sprintf("ref cycle: %s", strings.Join(cycle, " -> ")),
+					}
+				}
+			}
+			namedBlock, err := r.resolveRefs(jsonPath(namedPath, ref), namedBlocks[ref], t, append(referencing, key))
+			if err != nil {
+				return nil, err
+			}
+			overrides := map[string]any{}
+			for k, v := range object {
+				if k != "ref" {
+					overrides[k] = v
+				}
+			}
+			object = mergeDocument(namedBlock.(map[string]any), overrides)
+		}
+		fields := jsonFields(t)
+		resolvedObject := map[string]any{}
+		for k, v := range object {
+			field, ok := fields[k]
+			if !ok {
+				resolvedObject[k] = v
+				continue
+			}
+			resolvedValue, err := r.resolveRefs(jsonPath(path, k), v, field.Type, referencing)
+			if err != nil {
+				return nil, err
+			}
+			resolvedObject[k] = resolvedValue
+		}
+		return resolvedObject, nil
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value, nil
+		}
+		resolvedObject := map[string]any{}
+		for k, v := range object {
+			resolvedValue, err := r.resolveRefs(jsonPath(path, k), v, t.Elem(), referencing)
+			if err != nil {
+				return nil, err
+			}
+			resolvedObject[k] = resolvedValue
+		}
+		return resolvedObject, nil
+	case reflect.Slice:
+		array, ok := value.([]any)
+		if !ok {
+			return value, nil
+		}
+		resolvedArray := make([]any, len(array))
+		for i, item := range array {
+			resolvedItem, err := r.resolveRefs(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), referencing)
+			if err != nil {
+				return nil, err
+			}
+			resolvedArray[i] = resolvedItem
+		}
+		return resolvedArray, nil
+	}
+	return value, nil
+}
+
+// mergeDocument merge overrides onto base recursively, base and overrides are not modified,
+// object with `ref` in overrides replaces object in base, it is resolved to the named block later
+func mergeDocument(base, overrides map[string]any) map[string]any {
+	merged := map[string]any{}
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(merged, k)
+			continue
+		}
+		baseObject, baseIsObject := merged[k].(map[string]any)
+		overrideObject, overrideIsObject := v.(map[string]any)
+		_, overrideHasRef := overrideObject["ref"]
+		if baseIsObject && overrideIsObject && !overrideHasRef {
+			merged[k] = mergeDocument(baseObject, overrideObject)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func decodeResolved(path string, document any, target any) error {
+	content, err := json.Marshal(document)
+	if err != nil {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("marshal resolved config failed: %s", err)}
+	}
+	if err := json.Unmarshal(content, target); err != nil {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("invalid config: %s", err)}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const resolveTestNamedBlocks = `
+  "oidc_providers": {
+    "m2m": {
+      "client_credentials": {
+        "token_endpoint": "https://idaas.example.com/token",
+        "client_id": "app1",
+        "client_assertion_singer": {"ref": "key1"}
+      }
+    },
+    "m2m-scoped": {
+      "ref": "m2m",
+      "client_credentials": {"scope": "scope1"}
+    }
+  },
+  "signers": {
+    "key1": {"algorithm": "RS256", "key_file": {"file": "/tmp/key1.pem"}}
+  },`
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		check   func(t *testing.T, config *CloudCredentialConfig)
+	}{
+		{
+			name: "extends merges objects and replaces other values",
+			profile: `
+    "base": {
+      "tags": ["dev"],
+      "comment": "base",
+      "alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role-dev", "oidc_provider_arn": "oidc1"}
+    },
+    "child": {
+      "extends": "base",
+      "comment": "child",
+      "alibaba_cloud_sts": {"role_arn": "role-prod"}
+    }`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				child := config.Profile["child"]
+				assertEqual(t, "comment", "child", child.Comment)
+				assertEqual(t, "region", "cn-hangzhou", child.AlibabaCloud.Region)
+				assertEqual(t, "role_arn", "role-prod", child.AlibabaCloud.RoleArn)
+				assertEqual(t, "oidc_provider_arn", "oidc1", child.AlibabaCloud.OidcProviderArn)
+				assertEqual(t, "base role_arn", "role-dev", config.Profile["base"].AlibabaCloud.RoleArn)
+			},
+		},
+		{
+			name: "extends does not inherit tags",
+			profile: `
+    "base": {"tags": ["dev"], "alibaba_cloud_sts": {"region": "cn-hangzhou"}},
+    "child": {"extends": "base"},
+    "tagged": {"extends": "base", "tags": ["prod"]}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				assertEqual(t, "base tags", []string{"dev"}, config.Profile["base"].Tags)
+				assertEqual(t, "child tags", []string(nil), config.Profile["child"].Tags)
+				assertEqual(t, "tagged tags", []string{"prod"}, config.Profile["tagged"].Tags)
+			},
+		},
+		{
+			name: "extends null removes inherited value",
+			profile: `
+    "base": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "oidc_token_provider": {"ref": "m2m"}}},
+    "child": {"extends": "base", "alibaba_cloud_sts": {"oidc_token_provider": null}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				assertEqual(t, "region", "cn-hangzhou", config.Profile["child"].AlibabaCloud.Region)
+				if config.Profile["child"].AlibabaCloud.OidcTokenProvider != nil {
+					t.Errorf("oidc_token_provider should be removed")
+				}
+			},
+		},
+		{
+			name: "extends chain",
+			profile: `
+    "a": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role-a"}},
+    "b": {"extends": "a", "alibaba_cloud_sts": {"role_arn": "role-b", "network": "vpc"}},
+    "c": {"extends": "b", "alibaba_cloud_sts": {"role_arn": "role-c"}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				c := config.Profile["c"].AlibabaCloud
+				assertEqual(t, "region", "cn-hangzhou", c.Region)
+				assertEqual(t, "network", "vpc", c.Network)
+				assertEqual(t, "role_arn", "role-c", c.RoleArn)
+			},
+		},
+		{
+			name: "extends object with ref replaces inherited object",
+			profile: `
+    "base": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "oidc_token_provider": {"client_credentials": {
+      "token_endpoint": "https://inline.example.com/token", "client_id": "inline", "client_secret": "secret1"}}}},
+    "child": {"extends": "base", "alibaba_cloud_sts": {"oidc_token_provider": {"ref": "m2m"}}},
+    "scoped": {"extends": "base", "alibaba_cloud_sts": {"oidc_token_provider": {
+      "ref": "m2m", "client_credentials": {"scope": "scope2"}}}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				child := config.Profile["child"].AlibabaCloud
+				childCredentials := child.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "region", "cn-hangzhou", child.Region)
+				assertEqual(t, "client_id", "app1", childCredentials.ClientId)
+				assertEqual(t, "token_endpoint", "https://idaas.example.com/token", childCredentials.TokenEndpoint)
+				assertEqual(t, "client_secret", "", childCredentials.ClientSecret)
+				assertEqual(t, "signer file", "/tmp/key1.pem", childCredentials.ClientAssertionSinger.KeyFile.File)
+				scopedCredentials := config.Profile["scoped"].AlibabaCloud.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "scoped client_id", "app1", scopedCredentials.ClientId)
+				assertEqual(t, "scoped scope", "scope2", scopedCredentials.Scope)
+				assertEqual(t, "scoped client_secret", "", scopedCredentials.ClientSecret)
+				baseCredentials := config.Profile["base"].AlibabaCloud.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "base client_id", "inline", baseCredentials.ClientId)
+			},
+		},
+		{
+			name: "extends merges onto inherited ref",
+			profile: `
+    "base": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "oidc_token_provider": {"ref": "m2m"}}},
+    "child": {"extends": "base", "alibaba_cloud_sts": {"oidc_token_provider": {
+      "client_credentials": {"scope": "scope2"}}}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				clientCredentials := config.Profile["child"].AlibabaCloud.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "client_id", "app1", clientCredentials.ClientId)
+				assertEqual(t, "scope", "scope2", clientCredentials.Scope)
+			},
+		},
+		{
+			name: "ref resolves named blocks recursively",
+			profile: `
+    "p": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "oidc_token_provider": {"ref": "m2m"}}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				clientCredentials := config.Profile["p"].AlibabaCloud.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "client_id", "app1", clientCredentials.ClientId)
+				assertEqual(t, "signer algorithm", "RS256", clientCredentials.ClientAssertionSinger.Algorithm)
+				assertEqual(t, "signer file", "/tmp/key1.pem", clientCredentials.ClientAssertionSinger.KeyFile.File)
+				assertEqual(t, "named oidc providers", 2, len(config.OidcProviders))
+				assertEqual(t, "named signers", 1, len(config.Signers))
+			},
+		},
+		{
+			name: "ref fields override named block",
+			profile: `
+    "p": {"alibaba_cloud_sts": {"region": "cn-hangzhou",
+      "oidc_token_provider": {"ref": "m2m-scoped", "client_credentials": {"client_id": "app2"}}}}`,
+			check: func(t *testing.T, config *CloudCredentialConfig) {
+				clientCredentials := config.Profile["p"].AlibabaCloud.OidcTokenProvider.OidcTokenProviderClientCredentials
+				assertEqual(t, "client_id", "app2", clientCredentials.ClientId)
+				assertEqual(t, "scope", "scope1", clientCredentials.Scope)
+				assertEqual(t, "token_endpoint", "https://idaas.example.com/token", clientCredentials.TokenEndpoint)
+				assertEqual(t, "named client_id", "app1",
+					config.OidcProviders["m2m"].OidcTokenProviderClientCredentials.ClientId)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseCloudCredentialConfig([]byte(buildResolveTestConfig(tt.profile)))
+			if err != nil {
+				t.Fatalf("parse config failed: %v", err)
+			}
+			tt.check(t, config)
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		path    string
+		message string
+	}{
+		{
+			name:    "extends cycle",
+			profile: `"a": {"extends": "b"}, "b": {"extends": "c"}, "c": {"extends": "a"}`,
+			path:    "$.profile.c.extends",
+			message: "extends cycle: a -> b -> c -> a",
+		},
+		{
+			name:    "extends self",
+			profile: `"a": {"extends": "a"}`,
+			path:    "$.profile.a.extends",
+			message: "extends cycle: a -> a",
+		},
+		{
+			name:    "extends unknown profile",
+			profile: `"a": {"extends": "missing"}`,
+			path:    "$.profile.a.extends",
+			message: "profile: missing not found",
+		},
+		{
+			name:    "extends not string",
+			profile: `"a": {"extends": 1}`,
+			path:    "$.profile.a.extends",
+			message: "expected profile name",
+		},
+		{
+			name:    "ref unknown oidc provider",
+			profile: `"a": {"alibaba_cloud_sts": {"oidc_token_provider": {"ref": "missing"}}}`,
+			path:    "$.profile.a.alibaba_cloud_sts.oidc_token_provider.ref",
+			message: "oidc provider: missing not found",
+		},
+		{
+			name: "ref unknown signer",
+			profile: `"a": {"alibaba_cloud_sts": {"oidc_token_provider": {"client_credentials":
+        {"client_assertion_singer": {"ref": "missing"}}}}}`,
+			path:    "$.profile.a.alibaba_cloud_sts.oidc_token_provider.client_credentials.client_assertion_singer.ref",
+			message: "signer: missing not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validationError := resolveTestConfig(t, buildResolveTestConfig(tt.profile))
+			assertEqual(t, "path", tt.path, validationError.Path)
+			assertEqual(t, "message", tt.message, validationError.Message)
+		})
+	}
+}
+
+func TestResolveRefCycle(t *testing.T) {
+	configContent := `{
+  "version": "1",
+  "oidc_providers": {
+    "a": {"ref": "b"},
+    "b": {"ref": "a"}
+  },
+  "profile": {}
+}`
+	validationError := resolveTestConfig(t, configContent)
+	if !strings.HasPrefix(validationError.Message, "ref cycle: ") {
+		t.Errorf("message: expected ref cycle, got: %s", validationError.Message)
+	}
+	if !strings.Contains(validationError.Message, "oidc provider: a -> oidc provider: b -> oidc provider: a") {
+		t.Errorf("message: expected cycle chain, got: %s", validationError.Message)
+	}
+}
+
+func buildResolveTestConfig(profile string) string {
+	return `{
+  "version": "1",` + resolveTestNamedBlocks + `
+  "profile": {` + profile + `
+  }
+}`
+}
+
+func resolveTestConfig(t *testing.T, configContent string) *ValidationError {
+	t.Helper()
+	var config CloudCredentialConfig
+	err := config.resolve([]byte(configContent))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	validationError, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got: %T %v", err, err)
+	}
+	return validationError
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+)
+
+const (
+	JsonSchemaId = "https://raw.githubusercontent.com/aliyunidaas/alibaba-cloud-idaas/main/alibaba-cloud-idaas.schema.json"
+)
+
+// GenerateJsonSchema JSON Schema (draft 2020-12) of config file generated from config structs,
+// enum and mutual exclusion rules are the same as ValidateCloudCredentialConfig
+func GenerateJsonSchema() map[string]any {
+	definitions := map[string]any{}
+	rootType := reflect.TypeOf(CloudCredentialConfig{})
+	schema := structSchema(rootType, definitions)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = JsonSchemaId
+	schema["title"] = "alibaba-cloud-idaas config"
+	schema["$defs"] = definitions
+	return schema
+}
+
+func typeSchema(t reflect.Type, definitions map[string]any) map[string]any {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if _, ok := definitions[t.Name()]; !ok {
+			// placeholder for recursive types
+			definitions[t.Name()] = map[string]any{}
+			definitions[t.Name()] = structSchema(t, definitions)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), definitions),
+		}
+	case reflect.Slice:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), definitions),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type, definitions map[string]any) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		property := typeSchema(t.Field(i).Type, definitions)
+		if enum, ok := enumFields[t][name]; ok {
+			property["enum"] = enum
+		}
+		properties[name] = property
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	// required and one of rules are not in schema, profile with extends or block with ref may be partial,
+	// they are checked by ValidateCloudCredentialConfig on resolved config
+	var allOf []any
+	for _, group := range append(oneOfFields[t], exclusiveFields[t]...) {
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				allOf = append(allOf, map[string]any{"not": map[string]any{"required": []string{group[i], group[j]}}})
+			}
+		}
+	}
+	if len(allOf) > 0 {
+		schema["allOf"] = allOf
+	}
+	return schema
+}
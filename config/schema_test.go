@@ -0,0 +1,133 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJsonSchemaMatchesSchemaFile(t *testing.T) {
+	schemaFileContent, err := os.ReadFile("../alibaba-cloud-idaas.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := json.MarshalIndent(GenerateJsonSchema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(schemaFileContent), schema) {
+		t.Errorf("alibaba-cloud-idaas.schema.json is outdated, regenerate by: validate-config --schema")
+	}
+}
+
+func TestGenerateJsonSchema(t *testing.T) {
+	schema := GenerateJsonSchema()
+	definitions := schema["$defs"].(map[string]any)
+	tests := []struct {
+		name       string
+		definition string
+		property   string
+		expected   map[string]any
+	}{
+		{
+			name:       "string",
+			definition: "AlibabaCloudStsConfig",
+			property:   "role_arn",
+			expected:   map[string]any{"type": "string"},
+		},
+		{
+			name:       "integer",
+			definition: "AlibabaCloudStsConfig",
+			property:   "duration_seconds",
+			expected:   map[string]any{"type": "integer"},
+		},
+		{
+			name:       "enum",
+			definition: "AlibabaCloudStsConfig",
+			property:   "network",
+			expected:   map[string]any{"type": "string", "enum": []string{"public", "vpc", "dualstack"}},
+		},
+		{
+			name:       "struct reference",
+			definition: "AlibabaCloudStsConfig",
+			property:   "oidc_token_provider",
+			expected:   map[string]any{"$ref": "#/$defs/OidcTokenProviderConfig"},
+		},
+		{
+			name:       "array",
+			definition: "AlibabaCloudStsConfig",
+			property:   "assume_role_chain",
+			expected: map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/AlibabaCloudAssumeRoleConfig"},
+			},
+		},
+		{
+			name:       "map",
+			definition: "AwsAssumeRoleConfig",
+			property:   "tags",
+			expected: map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition, ok := definitions[tt.definition].(map[string]any)
+			if !ok {
+				t.Fatalf("definition: %s not found", tt.definition)
+			}
+			properties := definition["properties"].(map[string]any)
+			assertEqual(t, tt.property, tt.expected, properties[tt.property])
+		})
+	}
+}
+
+func TestGenerateJsonSchemaRules(t *testing.T) {
+	schema := GenerateJsonSchema()
+	definitions := schema["$defs"].(map[string]any)
+	tests := []struct {
+		name       string
+		definition string
+		notBoth    []string
+	}{
+		{name: "one of", definition: "OidcTokenProviderConfig", notBoth: []string{"client_credentials", "device_code"}},
+		{name: "exclusive", definition: "AlibabaCloudStsConfig", notBoth: []string{"policy", "policy_file"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			definition := definitions[tt.definition].(map[string]any)
+			assertEqual(t, "additionalProperties", false, definition["additionalProperties"])
+			if _, ok := definition["required"]; ok {
+				t.Errorf("required is not in schema, partial profiles and named blocks are allowed")
+			}
+			expected := map[string]any{"not": map[string]any{"required": tt.notBoth}}
+			allOf, _ := definition["allOf"].([]any)
+			for _, rule := range allOf {
+				if reflect.DeepEqual(expected, rule) {
+					return
+				}
+			}
+			t.Errorf("allOf: expected rule not both: %s", strings.Join(tt.notBoth, ", "))
+		})
+	}
+}
+
+func TestGenerateJsonSchemaReferences(t *testing.T) {
+	schema := GenerateJsonSchema()
+	definitions := schema["$defs"].(map[string]any)
+	schemaJson, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range strings.Split(string(schemaJson), `"$ref":"#/$defs/`)[1:] {
+		name, _, _ := strings.Cut(part, `"`)
+		if _, ok := definitions[name]; !ok {
+			t.Errorf("definition: %s is referenced but not defined", name)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValidationError config error at JSON path, e.g. $.profile.aliyun1.alibaba_cloud_sts.role_arn
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+var (
+	signAlgorithms = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+	// regexpYubikeyPivSlot slots supported by yubikey_piv signer, rN is retired key management slot
+	regexpYubikeyPivSlot = regexp.MustCompile(`^(auth|sign|cardAuth|[rR][0-9]+)$`)
+	regexpSimpleJsonKey  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// rules of config structs checked by ValidateCloudCredentialConfig, enum and mutual exclusion rules are also in
+// GenerateJsonSchema, fields are JSON names, field is set when it is not zero value
+var (
+	// requiredFields fields are required
+	requiredFields = map[reflect.Type][]string{
+		reflect.TypeOf(CloudCredentialConfig{}):                    {"version", "profile"},
+		reflect.TypeOf(AlibabaCloudStsConfig{}):                    {"role_arn"},
+		reflect.TypeOf(AlibabaCloudStsEndpointConfig{}):            {"endpoint"},
+		reflect.TypeOf(AlibabaCloudAcrConfig{}):                    {"registries"},
+		reflect.TypeOf(AlibabaCloudAccessKeyConfig{}):              {"access_key_id"},
+		reflect.TypeOf(AlibabaCloudAssumeRoleConfig{}):             {"role_arn"},
+		reflect.TypeOf(AwsCloudStsConfig{}):                        {"region", "role_arn", "oidc_token_provider"},
+		reflect.TypeOf(AwsAssumeRoleConfig{}):                      {"role_arn"},
+		reflect.TypeOf(AwsRolesAnywhereConfig{}):                   {"region", "trust_anchor_arn", "profile_arn", "role_arn", "x509_certificate"},
+		reflect.TypeOf(GcpStsConfig{}):                             {"audience", "oidc_token_provider"},
+		reflect.TypeOf(AzureAdConfig{}):                            {"tenant_id", "client_id", "oidc_token_provider"},
+		reflect.TypeOf(OidcTokenProviderClientCredentialsConfig{}): {"token_endpoint", "client_id"},
+		reflect.TypeOf(OidcTokenProviderDeviceCodeConfig{}):        {"issuer", "client_id"},
+		reflect.TypeOf(Pkcs7Config{}):                              {"provider"},
+		reflect.TypeOf(OidcTokenConfig{}):                          {"provider"},
+		reflect.TypeOf(ExSingerConfig{}):                           {"algorithm"},
+		reflect.TypeOf(ExSignerPkcs11Config{}):                     {"library_path", "token_label", "key_label"},
+		reflect.TypeOf(ExSignerYubikeyPivConfig{}):                 {"slot", "pin_policy"},
+		reflect.TypeOf(ExSignerExternalCommandConfig{}):            {"command", "parameter"},
+	}
+
+	// oneOfFields exactly one field of every group is required
+	oneOfFields = map[reflect.Type][][]string{
+		reflect.TypeOf(CloudStsConfig{}): {
+			{"alibaba_cloud_sts", "aws_sts", "aws_roles_anywhere", "gcp_sts", "azure_ad", "oidc_token"},
+		},
+		reflect.TypeOf(AlibabaCloudStsConfig{}):        {{"oidc_token_provider", "credential_source"}},
+		reflect.TypeOf(AlibabaCloudCredentialSource{}): {{"ecs_ram_role", "access_key"}},
+		reflect.TypeOf(AlibabaCloudAccessKeyConfig{}): {
+			{"access_key_secret", "access_key_secret_file", "access_key_secret_env", "access_key_secret_encrypted"},
+		},
+		reflect.TypeOf(OidcTokenProviderConfig{}): {{"client_credentials", "device_code"}},
+		reflect.TypeOf(OidcTokenProviderClientCredentialsConfig{}): {
+			{"client_secret", "client_assertion_singer", "client_assertion_pkcs7", "client_assertion_private_ca",
+				"client_assertion_oidc_token"},
+		},
+		reflect.TypeOf(PrivateCaConfig{}):       {{"certificate", "certificate_file"}},
+		reflect.TypeOf(ExSingerConfig{}):        {{"pkcs11", "yubikey_piv", "external_command", "key_file"}},
+		reflect.TypeOf(ExSingerKeyFileConfig{}): {{"key", "file"}},
+	}
+
+	// exclusiveFields at most one field of every group is set
+	exclusiveFields = map[reflect.Type][][]string{
+		reflect.TypeOf(AlibabaCloudStsConfig{}):        {{"policy", "policy_file"}},
+		reflect.TypeOf(AlibabaCloudAssumeRoleConfig{}): {{"policy", "policy_file"}},
+		reflect.TypeOf(PrivateCaConfig{}):              {{"certificate_chain", "certificate_chain_file"}},
+		reflect.TypeOf(OidcTokenConfig{}):              {{"oidc_token", "oidc_token_file"}},
+	}
+
+	// enumFields values of fields when set
+	enumFields = map[reflect.Type]map[string][]string{
+		reflect.TypeOf(CloudCredentialConfig{}):    {"version": {Version1}},
+		reflect.TypeOf(AlibabaCloudStsConfig{}):    {"network": {"public", "vpc", "dualstack"}},
+		reflect.TypeOf(AwsCloudStsConfig{}):        {"partition": {"aws", "aws-cn", "aws-us-gov"}},
+		reflect.TypeOf(Pkcs7Config{}):              {"provider": {"alibaba_cloud", "aws", "azure"}, "alibaba_cloud_mode": {"normal", "secure"}},
+		reflect.TypeOf(OidcTokenConfig{}):          {"provider": {"gcp", "custom"}},
+		reflect.TypeOf(ExSingerConfig{}):           {"algorithm": signAlgorithms},
+		reflect.TypeOf(ExSignerYubikeyPivConfig{}): {"pin_policy": {"never", "once", "always"}},
+	}
+
+	// fileFields files must exist when set
+	fileFields = map[reflect.Type][]string{
+		reflect.TypeOf(AlibabaCloudStsConfig{}):        {"policy_file"},
+		reflect.TypeOf(AlibabaCloudAssumeRoleConfig{}): {"policy_file"},
+		reflect.TypeOf(AlibabaCloudAccessKeyConfig{}):  {"access_key_secret_file"},
+		reflect.TypeOf(PrivateCaConfig{}):              {"certificate_file", "certificate_chain_file"},
+		reflect.TypeOf(OidcTokenConfig{}):              {"oidc_token_file"},
+		reflect.TypeOf(ExSingerKeyFileConfig{}):        {"file"},
+		reflect.TypeOf(ExSignerPkcs11Config{}):         {"library_path"},
+	}
+)
+
+// ValidateCloudCredentialConfig strict validate config content, unknown fields, types, mutual exclusion,
+// required fields, enums and file paths of every profile are checked, errors are in document order
+func ValidateCloudCredentialConfig(configContent []byte) []*ValidationError {
+	v := &validator{}
+	var document any
+	decoder := json.NewDecoder(bytes.NewReader(configContent))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		v.addf("$", "invalid JSON: %s", describeJsonError(configContent, err))
+		return v.errors
+	}
+	v.checkDocument("$", document, reflect.TypeOf(CloudCredentialConfig{}))
+	if len(v.errors) > 0 {
+		// rules are checked only when document matches config structs
+		return v.errors
+	}
+	var config CloudCredentialConfig
+	if err := json.Unmarshal(configContent, &config); err != nil {
+		v.addf("$", "invalid config: %s", err)
+		return v.errors
+	}
+	// rules are checked on resolved profiles, OIDC providers and signers
+	if err := config.resolve(configContent); err != nil {
+		var validationError *ValidationError
+		if errors.As(err, &validationError) {
+			v.errors = append(v.errors, validationError)
+		} else {
+			v.addf("$", "%s", err)
+		}
+		return v.errors
+	}
+	// named blocks may be partial, e.g. overridden fields are set by profiles, they are checked once merged into profiles
+	config.OidcProviders = nil
+	config.Signers = nil
+	v.checkRules("$", reflect.ValueOf(&config))
+	v.checkReferences(&config)
+	return v.errors
+}
+
+type validator struct {
+	errors []*ValidationError
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errors = append(v.errors, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkDocument check JSON document matches type t, unknown fields and type mismatches are reported
+func (v *validator) checkDocument(path string, value any, t reflect.Type) {
+	if value == nil {
+		// null is zero value
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			v.addf(path, "expected object, got %s", jsonTypeName(value))
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(object) {
+			field, ok := fields[key]
+			if !ok {
+				if suggestion := suggestField(key, fields); suggestion != "" {
+					v.addf(jsonPath(path, key), "unknown field, did you mean: %s", suggestion)
+				} else {
+					v.addf(jsonPath(path, key), "unknown field")
+				}
+				continue
+			}
+			v.checkDocument(jsonPath(path, key), object[key], field.Type)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			v.addf(path, "expected object, got %s", jsonTypeName(value))
+			return
+		}
+		for _, key := range sortedKeys(object) {
+			v.checkDocument(jsonPath(path, key), object[key], t.Elem())
+		}
+	case reflect.Slice:
+		array, ok := value.([]any)
+		if !ok {
+			v.addf(path, "expected array, got %s", jsonTypeName(value))
+			return
+		}
+		for i, item := range array {
+			v.checkDocument(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			v.addf(path, "expected string, got %s", jsonTypeName(value))
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			v.addf(path, "expected boolean, got %s", jsonTypeName(value))
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		number, ok := value.(json.Number)
+		if !ok {
+			v.addf(path, "expected integer, got %s", jsonTypeName(value))
+			return
+		}
+		if _, err := strconv.ParseInt(number.String(), 10, t.Bits()); err != nil {
+			v.addf(path, "expected %d bits integer, got %s", t.Bits(), number)
+		}
+	}
+}
+
+// checkRules check rules of structs in config
+func (v *validator) checkRules(path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			v.checkRules(path, value.Elem())
+		}
+	case reflect.Struct:
+		v.checkStruct(path, value)
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name := jsonName(t.Field(i)); name != "" {
+				v.checkRules(jsonPath(path, name), value.Field(i))
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			v.checkRules(jsonPath(path, key.String()), value.MapIndex(key))
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if value.Index(i).Kind() == reflect.Ptr && value.Index(i).IsNil() {
+				v.addf(fmt.Sprintf("%s[%d]", path, i), "null is not allowed")
+				continue
+			}
+			v.checkRules(fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+		}
+	}
+}
+
+func (v *validator) checkStruct(path string, value reflect.Value) {
+	t := value.Type()
+	fields := jsonFieldValues(value)
+	for _, field := range requiredFields[t] {
+		if fields[field].IsZero() {
+			v.addf(jsonPath(path, field), "required")
+		}
+	}
+	for _, group := range oneOfFields[t] {
+		setFields := findSetFields(fields, group)
+		if len(setFields) == 0 {
+			v.addf(path, "requires one of: %s", strings.Join(group, ", "))
+		} else if len(setFields) > 1 {
+			v.addf(path, "only one of: %s is allowed, set: %s", strings.Join(group, ", "), strings.Join(setFields, ", "))
+		}
+	}
+	for _, group := range exclusiveFields[t] {
+		if setFields := findSetFields(fields, group); len(setFields) > 1 {
+			v.addf(path, "only one of: %s is allowed", strings.Join(setFields, ", "))
+		}
+	}
+	for _, field := range sortedKeys(enumFields[t]) {
+		enum := enumFields[t][field]
+		fieldValue := fields[field].String()
+		if fieldValue != "" && !containsString(enum, fieldValue) {
+			v.addf(jsonPath(path, field), "invalid value: %s, supports: %s", fieldValue, strings.Join(enum, ", "))
+		}
+	}
+	for _, field := range fileFields[t] {
+		filename := fields[field].String()
+		if filename == "" {
+			continue
+		}
+		if stat, err := os.Stat(filename); err != nil {
+			v.addf(jsonPath(path, field), "file: %s not found", filename)
+		} else if stat.IsDir() {
+			v.addf(jsonPath(path, field), "file: %s is a directory", filename)
+		}
+	}
+
+	// rules depend on values
+	switch config := value.Addr().Interface().(type) {
+	case *AlibabaCloudStsConfig:
+		if config.StsEndpoint == "" && config.Region == "" {
+			v.addf(path, "requires one of: sts_endpoint, region")
+		}
+		if config.OidcTokenProvider != nil && config.OidcProviderArn == "" {
+			v.addf(jsonPath(path, "oidc_provider_arn"), "required by oidc_token_provider")
+		}
+	case *AwsRolesAnywhereConfig:
+		if config.X509Certificate != nil && config.X509Certificate.CertificateKeySigner == nil {
+			v.addf(jsonPath(path, "x509_certificate", "certificate_key_signer"), "required")
+		}
+	case *OidcTokenConfig:
+		if config.Provider == "custom" && config.OidcToken == "" && config.OidcTokenFile == "" {
+			v.addf(path, "requires one of: oidc_token, oidc_token_file")
+		}
+	case *ExSignerYubikeyPivConfig:
+		if config.Slot != "" && !regexpYubikeyPivSlot.MatchString(config.Slot) {
+			v.addf(jsonPath(path, "slot"), "invalid slot: %s, supports: auth, sign, cardAuth or rN", config.Slot)
+		}
+	}
+}
+
+// checkReferences current profile and group members must be profiles
+func (v *validator) checkReferences(config *CloudCredentialConfig) {
+	if config.CurrentProfile != "" {
+		if _, ok := config.Profile[config.CurrentProfile]; !ok {
+			v.addf("$.current_profile", "profile: %s not found", config.CurrentProfile)
+		}
+	}
+	for _, group := range sortedKeys(config.Groups) {
+		for i, member := range config.Groups[group] {
+			if _, ok := config.Profile[member]; !ok {
+				v.addf(fmt.Sprintf("%s[%d]", jsonPath("$.groups", group), i), "profile: %s not found", member)
+			}
+		}
+	}
+}
+
+// jsonName JSON field name, empty when field is not encoded
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields[name] = t.Field(i)
+		}
+	}
+	return fields
+}
+
+func jsonFieldValues(value reflect.Value) map[string]reflect.Value {
+	fields := map[string]reflect.Value{}
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonName(t.Field(i)); name != "" {
+			fields[name] = value.Field(i)
+		}
+	}
+	return fields
+}
+
+func findSetFields(fields map[string]reflect.Value, group []string) []string {
+	var setFields []string
+	for _, field := range group {
+		if !fields[field].IsZero() {
+			setFields = append(setFields, field)
+		}
+	}
+	return setFields
+}
+
+// jsonPath append keys to path, keys are quoted when they are not simple names
+func jsonPath(path string, keys ...string) string {
+	for _, key := range keys {
+		if regexpSimpleJsonKey.MatchString(key) {
+			path += "." + key
+		} else {
+			path += "[" + strconv.Quote(key) + "]"
+		}
+	}
+	return path
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return "null"
+}
+
+// describeJsonError adds line and column of syntax errors
+func describeJsonError(content []byte, err error) string {
+	var offset int64
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		offset = jsonErr.Offset
+	case *json.UnmarshalTypeError:
+		offset = jsonErr.Offset
+	default:
+		return err.Error()
+	}
+	before := content[:min(int(offset), len(content))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("%s, at line: %d, column: %d", err, line, column)
+}
+
+// suggestField suggest field when key is misspelled, e.g. client_assertion_signer
+func suggestField(key string, fields map[string]reflect.StructField) string {
+	suggestion := ""
+	bestDistance := 3
+	for _, name := range sortedKeys(fields) {
+		if distance := editDistance(key, name); distance < bestDistance {
+			suggestion = name
+			bestDistance = distance
+		}
+	}
+	return suggestion
+}
+
+// editDistance Levenshtein distance
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateCloudCredentialConfig(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(policyFile, []byte(`{"Version": "1"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		config   string
+		expected []*ValidationError
+	}{
+		{
+			name: "valid",
+			config: `{"version": "1", "current_profile": "p", "groups": {"g": ["p"]}, "profile": {"p": {"alibaba_cloud_sts": {
+  "region": "cn-hangzhou", "role_arn": "role1", "oidc_provider_arn": "oidc1", "policy_file": "` + policyFile + `",
+  "oidc_token_provider": {"client_credentials": {"token_endpoint": "https://idaas.example.com/token",
+    "client_id": "app1", "client_secret": "secret1"}}}}}}`,
+		},
+		{
+			name:   "invalid JSON",
+			config: `{"version": "1",}`,
+			expected: []*ValidationError{
+				{Path: "$", Message: "invalid JSON: invalid character '}' looking for beginning of object key string, at line: 1, column: 18"},
+			},
+		},
+		{
+			name:   "unknown fields with suggestion",
+			config: `{"version": "1", "profile": {"p": {"alibaba_cloud_sts": {"role_arm": "role1"}, "foo": 1}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.p.alibaba_cloud_sts.role_arm", Message: "unknown field, did you mean: role_arn"},
+				{Path: "$.profile.p.foo", Message: "unknown field"},
+			},
+		},
+		{
+			name:   "type mismatches",
+			config: `{"version": 1, "profile": {"p": {"alibaba_cloud_sts": {"duration_seconds": "3600", "role_arn": ["r"]}}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.p.alibaba_cloud_sts.duration_seconds", Message: "expected integer, got string"},
+				{Path: "$.profile.p.alibaba_cloud_sts.role_arn", Message: "expected string, got array"},
+				{Path: "$.version", Message: "expected string, got number"},
+			},
+		},
+		{
+			name: "required, one of and enum",
+			config: `{"version": "2", "profile": {"p": {"alibaba_cloud_sts": {"network": "intranet",
+  "oidc_token_provider": {"device_code": {"issuer": "https://idaas.example.com"}}}}}}`,
+			expected: []*ValidationError{
+				{Path: "$.version", Message: "invalid value: 2, supports: 1"},
+				{Path: "$.profile.p.alibaba_cloud_sts.role_arn", Message: "required"},
+				{Path: "$.profile.p.alibaba_cloud_sts.network", Message: "invalid value: intranet, supports: public, vpc, dualstack"},
+				{Path: "$.profile.p.alibaba_cloud_sts", Message: "requires one of: sts_endpoint, region"},
+				{Path: "$.profile.p.alibaba_cloud_sts.oidc_provider_arn", Message: "required by oidc_token_provider"},
+				{Path: "$.profile.p.alibaba_cloud_sts.oidc_token_provider.device_code.client_id", Message: "required"},
+			},
+		},
+		{
+			name: "mutual exclusion and missing file",
+			config: `{"version": "1", "profile": {"p": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role1",
+  "policy": "{}", "policy_file": "/not/exists/policy.json",
+  "credential_source": {"access_key": {"access_key_id": "ak", "access_key_secret": "sk", "access_key_secret_env": "SK"}}}}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.p.alibaba_cloud_sts", Message: "only one of: policy, policy_file is allowed"},
+				{Path: "$.profile.p.alibaba_cloud_sts.policy_file", Message: "file: /not/exists/policy.json not found"},
+				{Path: "$.profile.p.alibaba_cloud_sts.credential_source.access_key", Message: "only one of: " +
+					"access_key_secret, access_key_secret_file, access_key_secret_env, access_key_secret_encrypted is allowed, " +
+					"set: access_key_secret, access_key_secret_env"},
+			},
+		},
+		{
+			name:   "profile requires one cloud",
+			config: `{"version": "1", "profile": {"p": {"comment": "empty"}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.p", Message: "requires one of: alibaba_cloud_sts, aws_sts, aws_roles_anywhere, gcp_sts, azure_ad, oidc_token"},
+			},
+		},
+		{
+			name: "references",
+			config: `{"version": "1", "current_profile": "missing", "groups": {"g": ["p", "missing"]},
+  "profile": {"p": {"oidc_token": {"device_code": {"issuer": "https://idaas.example.com", "client_id": "app1"}}}}}`,
+			expected: []*ValidationError{
+				{Path: "$.current_profile", Message: "profile: missing not found"},
+				{Path: "$.groups.g[1]", Message: "profile: missing not found"},
+			},
+		},
+		{
+			name: "partial named block is checked after merge",
+			config: `{"version": "1",
+  "oidc_providers": {"m2m": {"client_credentials": {"token_endpoint": "https://idaas.example.com/token",
+    "client_secret": "secret1"}}},
+  "profile": {
+    "p": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role1", "oidc_provider_arn": "oidc1",
+      "oidc_token_provider": {"ref": "m2m", "client_credentials": {"client_id": "app1"}}}},
+    "missing": {"alibaba_cloud_sts": {"region": "cn-hangzhou", "role_arn": "role1", "oidc_provider_arn": "oidc1",
+      "oidc_token_provider": {"ref": "m2m"}}}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.missing.alibaba_cloud_sts.oidc_token_provider.client_credentials.client_id", Message: "required"},
+			},
+		},
+		{
+			name:   "resolve error",
+			config: `{"version": "1", "profile": {"a": {"extends": "b"}, "b": {"extends": "a"}}}`,
+			expected: []*ValidationError{
+				{Path: "$.profile.b.extends", Message: "extends cycle: a -> b -> a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validationErrors := ValidateCloudCredentialConfig([]byte(tt.config))
+			if len(validationErrors) != len(tt.expected) {
+				for _, validationError := range validationErrors {
+					t.Logf("got: %s", validationError)
+				}
+				t.Fatalf("expected %d error(s), got %d", len(tt.expected), len(validationErrors))
+			}
+			for i, expected := range tt.expected {
+				assertEqual(t, "error", expected.Error(), validationErrors[i].Error())
+			}
+		})
+	}
+}
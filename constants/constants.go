@@ -12,6 +12,7 @@ const (
 	CategoryCloudToken = "cloud_token"
 	CategoryOidc       = "oidc"
 	CategoryOidcToken  = "oidc_token"
+	CategoryClockSkew  = "clock_skew"
 
 	AlibabaCloudIdaasConfigFile = "alibaba-cloud-idaas.json"
 
@@ -21,6 +22,8 @@ const (
 	EnvPkcs11Pin          = "ALIBABA_CLOUD_IDAAS_PKSC11_PIN"
 	EnvYubiKeyPin         = "ALIBABA_CLOUD_IDAAS_YUBIKEY_PIN"
 	EnvPkcs8Password      = "ALIBABA_CLOUD_IDAAS_PKCS8_PASSWORD"
+	EnvDisableClockSkew   = "ALIBABA_CLOUD_IDAAS_DISABLE_CLOCK_SKEW"
+	EnvAcrProfile         = "ALIBABA_CLOUD_IDAAS_ACR_PROFILE"
 
 	UrlIdaasProduct                = "https://www.aliyun.com/product/idaas"
 	UrlAlibabaCloudIdaasRepository = "https://github.com/aliyunidaas/alibaba-cloud-idaas"
@@ -1,6 +1,7 @@
 package idp
 
 import (
+	"context"
 	"strings"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
@@ -8,7 +9,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func FetchAccessTokenClientCredentials(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentials(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	if credentialConfig == nil {
 		return nil, errors.New("oidcTokenProviderClientCredentialsConfig is nil")
 	}
@@ -47,15 +48,15 @@ func FetchAccessTokenClientCredentials(credentialConfig *config.OidcTokenProvide
 	}
 
 	if hasClientSecret {
-		return FetchAccessTokenClientCredentialsClientIdSecret(credentialConfig)
+		return FetchAccessTokenClientCredentialsClientIdSecret(ctx, credentialConfig)
 	} else if hasClientAssertionSigner {
-		return FetchAccessTokenClientCredentialsRfc7523(credentialConfig)
+		return FetchAccessTokenClientCredentialsRfc7523(ctx, credentialConfig)
 	} else if hasClientAssertionPkcs7 {
-		return FetchAccessTokenClientCredentialsPkcs7(credentialConfig)
+		return FetchAccessTokenClientCredentialsPkcs7(ctx, credentialConfig)
 	} else if hasClientAssertionPrivateCa {
-		return FetchAccessTokenClientCredentialsPrivateCa(credentialConfig)
+		return FetchAccessTokenClientCredentialsPrivateCa(ctx, credentialConfig)
 	} else if hasClientAssertionOidcToken {
-		return FetchAccessTokenClientCredentialsOidcToken(credentialConfig)
+		return FetchAccessTokenClientCredentialsOidcToken(ctx, credentialConfig)
 	} else {
 		return nil, errors.New("client auth method must set one")
 	}
@@ -1,6 +1,7 @@
 package idp
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -17,9 +18,9 @@ const (
 	OidcTokenProviderCustom = "custom"
 )
 
-func FetchAccessTokenClientCredentialsOidcToken(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentialsOidcToken(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	tokenEndpoint := credentialConfig.TokenEndpoint
-	idToken, err := fetchOidcToken(credentialConfig)
+	idToken, err := fetchOidcToken(ctx, credentialConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -27,15 +28,15 @@ func FetchAccessTokenClientCredentialsOidcToken(credentialConfig *config.OidcTok
 		FetchTokenCommonOptions: buildFetchTokenCommonOptions(credentialConfig),
 		IdToken:                 idToken,
 	}
-	tokenResponse, errorResponse, err := oidc.FetchTokenIdTokenBearer(tokenEndpoint, fetchTokenIdTokenBearerOptions)
+	tokenResponse, errorResponse, err := oidc.FetchTokenIdTokenBearer(ctx, tokenEndpoint, fetchTokenIdTokenBearerOptions)
 	return parseFetchAccessToken(tokenResponse, errorResponse, err)
 }
 
-func fetchOidcToken(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (string, error) {
+func fetchOidcToken(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (string, error) {
 	oidcTokenConfig := credentialConfig.ClientAssertionOidcTokenConfig
 	provider := oidcTokenConfig.Provider
 	if provider == OidcTokenProviderGcp {
-		return fetchOidcTokenForGcp(oidcTokenConfig.GoogleVmIdentityUrl, oidcTokenConfig.GoogleVmIdentityAud)
+		return fetchOidcTokenForGcp(ctx, oidcTokenConfig.GoogleVmIdentityUrl, oidcTokenConfig.GoogleVmIdentityAud)
 	} else if provider == OidcTokenProviderCustom {
 		if oidcTokenConfig.OidcToken != "" && oidcTokenConfig.OidcTokenFile != "" {
 			return "", errors.Errorf("OidcToken and OidcTokenFile cannot both be set")
@@ -57,8 +58,8 @@ func fetchOidcToken(credentialConfig *config.OidcTokenProviderClientCredentialsC
 }
 
 // reference: https://cloud.google.com/compute/docs/instances/verifying-instance-identity
-func fetchOidcTokenForGcp(endpoint, aud string) (string, error) {
-	client := utils.BuildHttpClient()
+func fetchOidcTokenForGcp(ctx context.Context, endpoint, aud string) (string, error) {
+	client := utils.GetHttpClient(ctx)
 	if aud == "" {
 		aud = constants.DefaultAudienceAlibabaCloudIdaas
 	}
@@ -70,5 +71,5 @@ func fetchOidcTokenForGcp(endpoint, aud string) (string, error) {
 	headers := map[string]string{
 		"Metadata-Flavor": "Google",
 	}
-	return utils.FetchAsString(client, utils.HttpMethodGet, endpoint, headers)
+	return utils.FetchAsString(ctx, client, utils.HttpMethodGet, endpoint, headers)
 }
@@ -1,12 +1,12 @@
 package idp
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
@@ -21,9 +21,9 @@ const (
 	Pkcs7ProviderAzure        = "azure"
 )
 
-func FetchAccessTokenClientCredentialsPkcs7(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentialsPkcs7(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	tokenEndpoint := credentialConfig.TokenEndpoint
-	pkcs7, err := fetchPkcs7(credentialConfig)
+	pkcs7, err := fetchPkcs7(ctx, credentialConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -31,22 +31,23 @@ func FetchAccessTokenClientCredentialsPkcs7(credentialConfig *config.OidcTokenPr
 		FetchTokenCommonOptions: buildFetchTokenCommonOptions(credentialConfig),
 		Pkcs7:                   base64.StdEncoding.EncodeToString(pkcs7),
 	}
-	tokenResponse, errorResponse, err := oidc.FetchTokenPkcs7Bearer(tokenEndpoint, fetchTokenPkcs7BearerOptions)
+	tokenResponse, errorResponse, err := oidc.FetchTokenPkcs7Bearer(ctx, tokenEndpoint, fetchTokenPkcs7BearerOptions)
 	return parseFetchAccessToken(tokenResponse, errorResponse, err)
 }
 
-func fetchPkcs7(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) ([]byte, error) {
+func fetchPkcs7(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) ([]byte, error) {
 	pkcs7Config := credentialConfig.ClientAssertionPkcs7Config
 	provider := pkcs7Config.Provider
 
 	var pkcs7 []byte
 	var pkcs7Err error
 	if provider == Pkcs7ProviderAlibabaCloud {
-		pkcs7, pkcs7Err = fetchPkcs7ForAlibabaCloud(pkcs7Config.AlibabaCloudIdaasInstanceId, pkcs7Config.AlibabaCloudMode)
+		pkcs7, pkcs7Err = fetchPkcs7ForAlibabaCloud(ctx, credentialConfig.TokenEndpoint,
+			pkcs7Config.AlibabaCloudIdaasInstanceId, pkcs7Config.AlibabaCloudMode)
 	} else if provider == Pkcs7ProviderAws {
-		pkcs7, pkcs7Err = fetchPkcs7ForAwsImdsv2Rsa2048()
+		pkcs7, pkcs7Err = fetchPkcs7ForAwsImdsv2Rsa2048(ctx)
 	} else if provider == Pkcs7ProviderAzure {
-		pkcs7, pkcs7Err = fetchPkcs7ForAzure()
+		pkcs7, pkcs7Err = fetchPkcs7ForAzure(ctx)
 	} else {
 		return nil, errors.New("unknown provider " + provider)
 	}
@@ -60,36 +61,36 @@ func fetchPkcs7(credentialConfig *config.OidcTokenProviderClientCredentialsConfi
 }
 
 // reference: https://www.alibabacloud.com/help/en/ecs/user-guide/use-instance-identities
-func fetchPkcs7ForAlibabaCloud(instanceId, mode string) ([]byte, error) {
+func fetchPkcs7ForAlibabaCloud(ctx context.Context, tokenEndpoint, instanceId, mode string) ([]byte, error) {
 	isHardenMode, err := getAlibabaCloudHardenMode(mode)
 	if err != nil {
 		return nil, err
 	}
-	client := utils.BuildHttpClient()
+	client := utils.GetHttpClient(ctx)
 	token := ""
 	if isHardenMode {
 		var err error
-		token, err = fetchAlibabaCloudSecureToken(client)
+		token, err = fetchAlibabaCloudSecureToken(ctx, client)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	audience, err := buildAlibabaCloudPkcs7Audience(instanceId)
+	audience, err := buildAlibabaCloudPkcs7Audience(tokenEndpoint, instanceId)
 	if err != nil {
 		return nil, err
 	}
-	return fetchAlibabaCloudPkcs7(client, audience, token)
+	return fetchAlibabaCloudPkcs7(ctx, client, audience, token)
 }
 
 // reference: https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/verify-iid.html
-func fetchPkcs7ForAwsImdsv2Rsa2048() ([]byte, error) {
-	client := utils.BuildHttpClient()
-	token, err := fetchAwsSecureToken(client)
+func fetchPkcs7ForAwsImdsv2Rsa2048(ctx context.Context) ([]byte, error) {
+	client := utils.GetHttpClient(ctx)
+	token, err := fetchAwsSecureToken(ctx, client)
 	if err != nil {
 		return nil, err
 	}
-	return fetchAwsPkcs7Imdsv2Rsa2048(client, token)
+	return fetchAwsPkcs7Imdsv2Rsa2048(ctx, client, token)
 }
 
 type AzurePkcs7Response struct {
@@ -98,13 +99,13 @@ type AzurePkcs7Response struct {
 }
 
 // reference: https://learn.microsoft.com/en-us/azure/virtual-machines/instance-metadata-service?tabs=windows
-func fetchPkcs7ForAzure() ([]byte, error) {
-	client := utils.BuildHttpClient()
+func fetchPkcs7ForAzure(ctx context.Context) ([]byte, error) {
+	client := utils.GetHttpClient(ctx)
 	azurePkcs7Endpoint := "http://169.254.169.254/metadata/attested/document?api-version=2020-09-01"
 	headers := map[string]string{
 		"Metadata": "true",
 	}
-	body, err := utils.Fetch(client, utils.HttpMethodGet, azurePkcs7Endpoint, headers)
+	body, err := utils.Fetch(ctx, client, utils.HttpMethodGet, azurePkcs7Endpoint, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -133,10 +134,11 @@ func getAlibabaCloudHardenMode(mode string) (bool, error) {
 	}
 }
 
-func buildAlibabaCloudPkcs7Audience(instanceId string) (string, error) {
+// buildAlibabaCloudPkcs7Audience signingTime is verified by IDaaS, compensated with clock skew of token endpoint
+func buildAlibabaCloudPkcs7Audience(tokenEndpoint, instanceId string) (string, error) {
 	audience := map[string]any{}
 	audience["aud"] = instanceId
-	audience["signingTime"] = time.Now().Unix()
+	audience["signingTime"] = utils.NowFor(tokenEndpoint).Unix()
 
 	audienceBytes, err := json.Marshal(audience)
 	if err != nil {
@@ -146,14 +148,14 @@ func buildAlibabaCloudPkcs7Audience(instanceId string) (string, error) {
 	return string(audienceBytes), nil
 }
 
-func fetchAlibabaCloudPkcs7(client *http.Client, audience, token string) ([]byte, error) {
+func fetchAlibabaCloudPkcs7(ctx context.Context, client *http.Client, audience, token string) ([]byte, error) {
 	alibabaCloudPkcs7Endpoint := fmt.Sprintf(
 		"http://100.100.100.200/latest/dynamic/instance-identity/pkcs7?audience=%s", url.QueryEscape(audience))
 	headers := map[string]string{}
 	if token != "" {
 		headers["X-aliyun-ecs-metadata-token"] = token
 	}
-	bytes, err := utils.Fetch(client, utils.HttpMethodGet, alibabaCloudPkcs7Endpoint, headers)
+	bytes, err := utils.Fetch(ctx, client, utils.HttpMethodGet, alibabaCloudPkcs7Endpoint, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -165,20 +167,20 @@ func fetchAlibabaCloudPkcs7(client *http.Client, audience, token string) ([]byte
 	return pkcs7, nil
 }
 
-func fetchAlibabaCloudSecureToken(client *http.Client) (string, error) {
+func fetchAlibabaCloudSecureToken(ctx context.Context, client *http.Client) (string, error) {
 	alibabaCloudTokenEndpoint := "http://100.100.100.200/latest/api/token"
 	headers := map[string]string{
 		"X-aliyun-ecs-metadata-token-ttl-seconds": "3600",
 	}
-	return utils.FetchAsString(client, utils.HttpMethodPut, alibabaCloudTokenEndpoint, headers)
+	return utils.FetchAsString(ctx, client, utils.HttpMethodPut, alibabaCloudTokenEndpoint, headers)
 }
 
-func fetchAwsPkcs7Imdsv2Rsa2048(client *http.Client, token string) ([]byte, error) {
+func fetchAwsPkcs7Imdsv2Rsa2048(ctx context.Context, client *http.Client, token string) ([]byte, error) {
 	alibabaCloudPkcs7Endpoint := "http://169.254.169.254/latest/dynamic/instance-identity/rsa2048"
 	headers := map[string]string{
 		"X-aws-ec2-metadata-token": token,
 	}
-	bytes, err := utils.Fetch(client, utils.HttpMethodGet, alibabaCloudPkcs7Endpoint, headers)
+	bytes, err := utils.Fetch(ctx, client, utils.HttpMethodGet, alibabaCloudPkcs7Endpoint, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -190,12 +192,12 @@ func fetchAwsPkcs7Imdsv2Rsa2048(client *http.Client, token string) ([]byte, erro
 	return pkcs7, nil
 }
 
-func fetchAwsSecureToken(client *http.Client) (string, error) {
+func fetchAwsSecureToken(ctx context.Context, client *http.Client) (string, error) {
 	awsTokenEndpoint := "http://169.254.169.254/latest/api/token"
 	headers := map[string]string{
 		"X-aws-ec2-metadata-token-ttl-seconds": "3600",
 	}
-	return utils.FetchAsString(client, utils.HttpMethodPut, awsTokenEndpoint, headers)
+	return utils.FetchAsString(ctx, client, utils.HttpMethodPut, awsTokenEndpoint, headers)
 }
 
 func trimAllSpaces(str string) string {
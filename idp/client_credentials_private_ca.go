@@ -1,6 +1,7 @@
 package idp
 
 import (
+	"context"
 	"os"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
@@ -8,7 +9,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func FetchAccessTokenClientCredentialsPrivateCa(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentialsPrivateCa(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	tokenEndpoint := credentialConfig.TokenEndpoint
 	clientAssertionPrivateCaConfig := credentialConfig.ClientAssertionPrivateCaConfig
 
@@ -33,7 +34,7 @@ func FetchAccessTokenClientCredentialsPrivateCa(credentialConfig *config.OidcTok
 		ClientX509Chain:         certificateChain,
 		JwtSigner:               jwtSigner,
 	}
-	tokenResponse, errorResponse, err := oidc.FetchTokenX509JwtBearer(tokenEndpoint, fetchTokenX509JwtBearerOptions)
+	tokenResponse, errorResponse, err := oidc.FetchTokenX509JwtBearer(ctx, tokenEndpoint, fetchTokenX509JwtBearerOptions)
 	return parseFetchAccessToken(tokenResponse, errorResponse, err)
 }
 
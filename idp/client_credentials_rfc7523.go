@@ -1,12 +1,13 @@
 package idp
 
 import (
+	"context"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/oidc"
 	"github.com/pkg/errors"
 )
 
-func FetchAccessTokenClientCredentialsRfc7523(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentialsRfc7523(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	tokenEndpoint := credentialConfig.TokenEndpoint
 	jwtSigner, err := config.NewExJwtSignerFromConfig(credentialConfig.ClientAssertionSinger)
 	if err != nil {
@@ -16,6 +17,6 @@ func FetchAccessTokenClientCredentialsRfc7523(credentialConfig *config.OidcToken
 		FetchTokenCommonOptions: buildFetchTokenCommonOptions(credentialConfig),
 		JwtSigner:               jwtSigner,
 	}
-	tokenResponse, errorResponse, err := oidc.FetchTokenRfc7523(tokenEndpoint, fetchTokenRfc7523Options)
+	tokenResponse, errorResponse, err := oidc.FetchTokenRfc7523(ctx, tokenEndpoint, fetchTokenRfc7523Options)
 	return parseFetchAccessToken(tokenResponse, errorResponse, err)
 }
@@ -1,11 +1,12 @@
 package idp
 
 import (
+	"context"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/oidc"
 )
 
-func FetchAccessTokenClientCredentialsClientIdSecret(credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
+func FetchAccessTokenClientCredentialsClientIdSecret(ctx context.Context, credentialConfig *config.OidcTokenProviderClientCredentialsConfig) (*oidc.TokenResponse, error) {
 	tokenEndpoint := credentialConfig.TokenEndpoint
 	fetchTokenOptions := &oidc.FetchTokenOptions{
 		ClientId:     credentialConfig.ClientId,
@@ -14,6 +15,6 @@ func FetchAccessTokenClientCredentialsClientIdSecret(credentialConfig *config.Oi
 		Scope:        credentialConfig.Scope,
 	}
 
-	tokenResponse, errorResponse, err := oidc.FetchToken(tokenEndpoint, fetchTokenOptions)
+	tokenResponse, errorResponse, err := oidc.FetchToken(ctx, tokenEndpoint, fetchTokenOptions)
 	return parseFetchAccessToken(tokenResponse, errorResponse, err)
 }
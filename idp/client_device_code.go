@@ -1,12 +1,13 @@
 package idp
 
 import (
+	"context"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/oidc"
 	"github.com/pkg/errors"
 )
 
-func FetchIdTokenDeviceCode(oidcTokenProviderDeviceCodeConfig *config.OidcTokenProviderDeviceCodeConfig,
+func FetchIdTokenDeviceCode(ctx context.Context, oidcTokenProviderDeviceCodeConfig *config.OidcTokenProviderDeviceCodeConfig,
 	fetchOptions *FetchOidcTokenOptions) (*oidc.TokenResponse, error) {
 	issuer := oidcTokenProviderDeviceCodeConfig.Issuer
 	options := &oidc.FetchDeviceCodeFlowOptions{
@@ -17,7 +18,7 @@ func FetchIdTokenDeviceCode(oidcTokenProviderDeviceCodeConfig *config.OidcTokenP
 		AutoOpenUrl:  oidcTokenProviderDeviceCodeConfig.AutoOpenUrl,
 		ForceNew:     fetchOptions.ForceNew,
 	}
-	tokenResponse, err := oidc.FetchTokenViaDeviceCodeFlow(issuer, options)
+	tokenResponse, err := oidc.FetchTokenViaDeviceCodeFlow(ctx, issuer, options)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed fetch id token via device code, issuer: %s", issuer)
 	}
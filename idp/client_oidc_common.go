@@ -1,10 +1,12 @@
 package idp
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
@@ -19,8 +21,35 @@ type FetchOidcTokenOptions struct {
 	ForceNew bool
 }
 
-func FetchOidcToken(profile string, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (string, error) {
+// oidcTokenLocks serialize fetching OIDC token by cache key, profiles sharing the same OIDC token provider
+// fetched concurrently reuse one login(e.g. device code) instead of prompting for each profile
+var oidcTokenLocks sync.Map
+
+type forceNewSessionKey struct{}
+
+// WithForceNewSession OIDC token of the same provider is fetched with ForceNew only once in session,
+// profiles sharing the provider reuse the login, e.g. execute multiple profiles with --force-new
+func WithForceNewSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceNewSessionKey{}, &sync.Map{})
+}
+
+func FetchOidcToken(ctx context.Context, profile string, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (string, error) {
 	digest := oidcTokenProviderConfig.Digest()
+	oidcTokenProviderId := oidcTokenProviderConfig.GetId()
+	cacheKey := fmt.Sprintf("%s_%s", oidcTokenProviderId, digest[0:32])
+
+	lockValue, _ := oidcTokenLocks.LoadOrStore(cacheKey, &sync.Mutex{})
+	lock := lockValue.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+	forceNewSession, _ := ctx.Value(forceNewSessionKey{}).(*sync.Map)
+	if forceNewSession != nil && options.ForceNew {
+		if _, ok := forceNewSession.Load(cacheKey); ok {
+			idaaslog.Debug.PrintfLn("OIDC token fetched with force new in session: %s", cacheKey)
+			options = &FetchOidcTokenOptions{}
+		}
+	}
+	serverHost := GetTokenServerHost(oidcTokenProviderConfig)
 	readCacheFileOptions := &utils.ReadCacheOptions{
 		Context: map[string]interface{}{
 			"profile": profile,
@@ -28,26 +57,27 @@ func FetchOidcToken(profile string, oidcTokenProviderConfig *config.OidcTokenPro
 			"config":  oidcTokenProviderConfig.Marshal(),
 		},
 		FetchContent: func() (int, string, error) {
-			return fetchJwt(oidcTokenProviderConfig, options)
+			return fetchJwt(ctx, oidcTokenProviderConfig, options)
 		},
 		IsContentExpiringOrExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpiringOrExpired(s)
+			return isContentExpiringOrExpired(serverHost, s)
 		},
 		IsContentExpired: func(s *utils.StringWithTime) bool {
-			return isContentExpired(s)
+			return isContentExpired(serverHost, s)
 		},
 		ForceNew: options.ForceNew,
 	}
 
-	oidcTokenProviderId := oidcTokenProviderConfig.GetId()
-	cacheKey := fmt.Sprintf("%s_%s", oidcTokenProviderId, digest[0:32])
 	idaaslog.Debug.PrintfLn("Cache key: %s %s", constants.CategoryOidcToken, cacheKey)
 	jwt, err := utils.ReadCacheFileWithEncryptionCallback(
 		constants.CategoryOidcToken, cacheKey, readCacheFileOptions)
+	if err == nil && forceNewSession != nil && options.ForceNew {
+		forceNewSession.Store(cacheKey, true)
+	}
 	return jwt, err
 }
 
-func FetchTokenResponse(oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (*oidc.TokenResponse, error) {
+func FetchTokenResponse(ctx context.Context, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (*oidc.TokenResponse, error) {
 	hasOidcTokenProviderDeviceCode := oidcTokenProviderConfig.OidcTokenProviderDeviceCode != nil
 	hasOidcTokenProviderClientCredentials := oidcTokenProviderConfig.OidcTokenProviderClientCredentials != nil
 
@@ -56,10 +86,10 @@ func FetchTokenResponse(oidcTokenProviderConfig *config.OidcTokenProviderConfig,
 			"OidcTokenProviderDeviceCode and OidcTokenProviderClientCredentials cannot both be set")
 	}
 	if hasOidcTokenProviderDeviceCode {
-		tokenResponse, fetchOidcTokenErr := FetchIdTokenDeviceCode(oidcTokenProviderConfig.OidcTokenProviderDeviceCode, options)
+		tokenResponse, fetchOidcTokenErr := FetchIdTokenDeviceCode(ctx, oidcTokenProviderConfig.OidcTokenProviderDeviceCode, options)
 		return tokenResponse, fetchOidcTokenErr
 	} else if hasOidcTokenProviderClientCredentials {
-		tokenResponse, fetchOidcTokenErr := FetchAccessTokenClientCredentials(oidcTokenProviderConfig.OidcTokenProviderClientCredentials)
+		tokenResponse, fetchOidcTokenErr := FetchAccessTokenClientCredentials(ctx, oidcTokenProviderConfig.OidcTokenProviderClientCredentials)
 		return tokenResponse, fetchOidcTokenErr
 	} else {
 		return nil, errors.New(
@@ -67,7 +97,7 @@ func FetchTokenResponse(oidcTokenProviderConfig *config.OidcTokenProviderConfig,
 	}
 }
 
-func fetchJwt(oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (int, string, error) {
+func fetchJwt(ctx context.Context, oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *FetchOidcTokenOptions) (int, string, error) {
 	hasOidcTokenProviderDeviceCode := oidcTokenProviderConfig.OidcTokenProviderDeviceCode != nil
 	hasOidcTokenProviderClientCredentials := oidcTokenProviderConfig.OidcTokenProviderClientCredentials != nil
 
@@ -79,12 +109,12 @@ func fetchJwt(oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *
 	var tokenResponse *oidc.TokenResponse
 	var fetchOidcTokenErr error
 	if hasOidcTokenProviderDeviceCode {
-		tokenResponse, fetchOidcTokenErr = FetchIdTokenDeviceCode(oidcTokenProviderConfig.OidcTokenProviderDeviceCode, options)
+		tokenResponse, fetchOidcTokenErr = FetchIdTokenDeviceCode(ctx, oidcTokenProviderConfig.OidcTokenProviderDeviceCode, options)
 		if tokenResponse != nil {
 			oidcToken = tokenResponse.IdToken
 		}
 	} else if hasOidcTokenProviderClientCredentials {
-		tokenResponse, fetchOidcTokenErr = FetchAccessTokenClientCredentials(oidcTokenProviderConfig.OidcTokenProviderClientCredentials)
+		tokenResponse, fetchOidcTokenErr = FetchAccessTokenClientCredentials(ctx, oidcTokenProviderConfig.OidcTokenProviderClientCredentials)
 		if tokenResponse != nil {
 			oidcToken = tokenResponse.AccessToken
 		}
@@ -98,22 +128,34 @@ func fetchJwt(oidcTokenProviderConfig *config.OidcTokenProviderConfig, options *
 	return 200, oidcToken, nil
 }
 
-func isContentExpiringOrExpired(s *utils.StringWithTime) bool {
+// GetTokenServerHost returns token endpoint of client credentials or issuer of device code,
+// tokens are issued by the server, and its clock skew is used to check expiry
+func GetTokenServerHost(oidcTokenProviderConfig *config.OidcTokenProviderConfig) string {
+	if oidcTokenProviderConfig.OidcTokenProviderClientCredentials != nil {
+		return oidcTokenProviderConfig.OidcTokenProviderClientCredentials.TokenEndpoint
+	}
+	if oidcTokenProviderConfig.OidcTokenProviderDeviceCode != nil {
+		return oidcTokenProviderConfig.OidcTokenProviderDeviceCode.Issuer
+	}
+	return ""
+}
+
+func isContentExpiringOrExpired(serverHost string, s *utils.StringWithTime) bool {
 	jwtTokenClaim, err := ParseJwtTokenClaim(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := jwtTokenClaim.IsValidAtLeastThreshold(2 * time.Minute)
+	valid := jwtTokenClaim.IsValidAtLeastThreshold(serverHost, 2*time.Minute)
 	idaaslog.Debug.PrintfLn("Check JWT is expiring or expired: %s", !valid)
 	return !valid
 }
 
-func isContentExpired(s *utils.StringWithTime) bool {
+func isContentExpired(serverHost string, s *utils.StringWithTime) bool {
 	jwtTokenClaim, err := ParseJwtTokenClaim(s.Content)
 	if err != nil {
 		return true
 	}
-	valid := jwtTokenClaim.IsValidAtLeastThreshold(1 * time.Minute)
+	valid := jwtTokenClaim.IsValidAtLeastThreshold(serverHost, 1*time.Minute)
 	idaaslog.Debug.PrintfLn("Check JWT is expired: %s", !valid)
 	return !valid
 }
@@ -126,10 +168,11 @@ type SimpleJwtClaims struct {
 	ExpirationAt int64  `json:"exp"` // Unix Epoch(seconds)
 }
 
-func (t *SimpleJwtClaims) IsValidAtLeastThreshold(thresholdDuration time.Duration) bool {
+// IsValidAtLeastThreshold serverHost is the server issued the JWT, local time is compensated with its clock skew
+func (t *SimpleJwtClaims) IsValidAtLeastThreshold(serverHost string, thresholdDuration time.Duration) bool {
 	idaaslog.Debug.PrintfLn("Check JWT is valid, expiration: %s, threshold: %d ms",
 		t.ExpirationAt, thresholdDuration.Milliseconds())
-	valid := (t.ExpirationAt - time.Now().Unix()) > int64(thresholdDuration.Seconds())
+	valid := (t.ExpirationAt - utils.NowFor(serverHost).Unix()) > int64(thresholdDuration.Seconds())
 	idaaslog.Info.PrintfLn("Check JWT is valid: %s", valid)
 	return valid
 }
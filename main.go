@@ -3,11 +3,20 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/configure"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/console"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/docker_credential"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/kube_credential"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/presign"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/qr"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/serve"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/setup_kubeconfig"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/show_signer_public_key"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/token_file_writer"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/validate_config"
 
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/clean_cache"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/execute"
@@ -16,6 +25,7 @@ import (
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/show_profile"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/show_token"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/version"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/commands/whoami"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
@@ -40,6 +50,8 @@ func innerMain() error {
 	app := &cli.App{
 		Name:  "alibaba-cloud-idaas",
 		Usage: "Alibaba Cloud IDaaS command line util",
+		// profile may be inline JSON config with commas
+		DisableSliceFlagSeparator: true,
 		Commands: []*cli.Command{
 			fetch_token.BuildCommand(),
 			show_token.BuildCommand(),
@@ -51,6 +63,15 @@ func innerMain() error {
 			show_signer_public_key.BuildCommand(),
 			serve.BuildCommand(),
 			qr.BuildCommand(),
+			kube_credential.BuildCommand(),
+			setup_kubeconfig.BuildCommand(),
+			console.BuildCommand(),
+			whoami.BuildCommand(),
+			docker_credential.BuildCommand(),
+			presign.BuildCommand(),
+			token_file_writer.BuildCommand(),
+			configure.BuildCommand(),
+			validate_config.BuildCommand(),
 		},
 		Action: func(context *cli.Context) error {
 			printBanner()
@@ -63,7 +84,12 @@ func innerMain() error {
 			return nil
 		},
 	}
-	if err := app.Run(os.Args); err != nil {
+	args := os.Args
+	// invoked as docker-credential-alibaba-cloud-idaas get|store|erase
+	if strings.TrimSuffix(filepath.Base(args[0]), ".exe") == docker_credential.HelperName {
+		args = append([]string{args[0], "docker-credential"}, args[1:]...)
+	}
+	if err := app.Run(args); err != nil {
 		utils.Stderr.Fprintf("%s\n", idaaslog.DumpError(err))
 		os.Exit(1)
 	}
@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -117,7 +118,7 @@ type FetchOpenIdConfigurationOptions struct {
 // - RFC6749
 // - RFC8628
 // - RFC7523
-func FetchToken(tokenEndpoint string, options *FetchTokenOptions) (*TokenResponse, *ErrorResponse, error) {
+func FetchToken(ctx context.Context, tokenEndpoint string, options *FetchTokenOptions) (*TokenResponse, *ErrorResponse, error) {
 	parameter := map[string]string{}
 	parameter["client_id"] = options.ClientId
 	if options.ClientSecret != "" {
@@ -148,7 +149,7 @@ func FetchToken(tokenEndpoint string, options *FetchTokenOptions) (*TokenRespons
 		parameter["application_federated_credential_name"] = options.ApplicationFederatedCredentialName
 	}
 	idaaslog.Unsafe.PrintfLn("Fetch token: %s, with parameter: %+v", tokenEndpoint, parameter)
-	statusCode, token, err := utils.PostHttp(tokenEndpoint, parameter)
+	statusCode, token, err := utils.PostHttp(ctx, tokenEndpoint, parameter)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Failed to fetch token, error: %v", err)
 		return nil, nil, errors.Wrapf(err, "failed to fetch token from: %s", tokenEndpoint)
@@ -172,7 +173,7 @@ func FetchToken(tokenEndpoint string, options *FetchTokenOptions) (*TokenRespons
 
 // FetchOpenIdConfiguration
 // specification: https://openid.net/specs/openid-connect-discovery-1_0.html
-func FetchOpenIdConfiguration(issuer string, fetchOptions *FetchOpenIdConfigurationOptions) (*OpenIdConfiguration, error) {
+func FetchOpenIdConfiguration(ctx context.Context, issuer string, fetchOptions *FetchOpenIdConfigurationOptions) (*OpenIdConfiguration, error) {
 	discovery := issuer + "/.well-known/openid-configuration"
 	idaaslog.Info.PrintfLn("OIDC discovery URL: %s", discovery)
 	options := &utils.ReadCacheOptions{
@@ -182,7 +183,7 @@ func FetchOpenIdConfiguration(issuer string, fetchOptions *FetchOpenIdConfigurat
 		},
 		FetchContent: func() (int, string, error) {
 			idaaslog.Debug.PrintfLn("GET discovery from URL: %s", discovery)
-			return utils.GetHttp(discovery)
+			return utils.GetHttp(ctx, discovery)
 		},
 		// OpenID configuration allows expired
 		AllowExpired: true,
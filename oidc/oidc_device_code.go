@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
@@ -24,11 +25,11 @@ type FetchDeviceCodeOptions struct {
 	Scope    string
 }
 
-func FetchTokenViaDeviceCodeFlow(issuer string, options *FetchDeviceCodeFlowOptions) (*TokenResponse, error) {
+func FetchTokenViaDeviceCodeFlow(ctx context.Context, issuer string, options *FetchDeviceCodeFlowOptions) (*TokenResponse, error) {
 	fetchOpenIdConfigurationOptions := &FetchOpenIdConfigurationOptions{
 		ForceNew: options.ForceNew,
 	}
-	openIdConfiguration, err := FetchOpenIdConfiguration(issuer, fetchOpenIdConfigurationOptions)
+	openIdConfiguration, err := FetchOpenIdConfiguration(ctx, issuer, fetchOpenIdConfigurationOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to fetch open id configuration, issuer: %s", issuer)
 	}
@@ -39,7 +40,7 @@ func FetchTokenViaDeviceCodeFlow(issuer string, options *FetchDeviceCodeFlowOpti
 	fetchDeviceCodeOptions := &FetchDeviceCodeOptions{
 		ClientId: options.ClientId,
 	}
-	deviceCodeResponse, deviceCodeErrorResponse, err := FetchDeviceCodeWithRetry(deviceAuthorization, fetchDeviceCodeOptions)
+	deviceCodeResponse, deviceCodeErrorResponse, err := FetchDeviceCodeWithRetry(ctx, deviceAuthorization, fetchDeviceCodeOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to fetch device code from: %s", deviceAuthorization)
 	}
@@ -80,13 +81,16 @@ func FetchTokenViaDeviceCodeFlow(issuer string, options *FetchDeviceCodeFlowOpti
 	tokenErrorCounting := 0
 	for i := 0; i < 100; i++ {
 		idaaslog.Debug.PrintfLn("Sleep %d s, #%d", sleepInterval, i)
-		utils.SleepSeconds(sleepInterval)
+		err := utils.SleepSeconds(ctx, sleepInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "fetch token via device code flow canceled")
+		}
 
-		tokenResponse, tokenErrorResponse, err := FetchToken(openIdConfiguration.TokenEndpoint, fetchTokenOptions)
+		tokenResponse, tokenErrorResponse, err := FetchToken(ctx, openIdConfiguration.TokenEndpoint, fetchTokenOptions)
 		if err != nil {
 			tokenErrorCounting++
 			if tokenErrorCounting > 3 {
-				return nil, errors.Errorf("failed to fetch token with response: %w", err)
+				return nil, errors.Wrap(err, "failed to fetch token with response")
 			}
 			// LOGGING ...
 			continue
@@ -114,13 +118,13 @@ func FetchTokenViaDeviceCodeFlow(issuer string, options *FetchDeviceCodeFlowOpti
 	return nil, errors.Errorf("failed to fetch token")
 }
 
-func FetchDeviceCodeWithRetry(deviceAuthorization string, options *FetchDeviceCodeOptions) (
+func FetchDeviceCodeWithRetry(ctx context.Context, deviceAuthorization string, options *FetchDeviceCodeOptions) (
 	deviceCodeResponse *DeviceCodeResponse, errorResponse *ErrorResponse, err error) {
 
 	// try 3 times
 	for i := 0; i < 3; i++ {
-		deviceCodeResponse, errorResponse, err = FetchDeviceCode(deviceAuthorization, options)
-		if err == nil {
+		deviceCodeResponse, errorResponse, err = FetchDeviceCode(ctx, deviceAuthorization, options)
+		if err == nil || ctx.Err() != nil {
 			return
 		}
 		idaaslog.Warn.PrintfLn("Failed to fetch device code #%d, error: %v", i, err)
@@ -128,7 +132,7 @@ func FetchDeviceCodeWithRetry(deviceAuthorization string, options *FetchDeviceCo
 	return
 }
 
-func FetchDeviceCode(deviceAuthorization string, options *FetchDeviceCodeOptions) (
+func FetchDeviceCode(ctx context.Context, deviceAuthorization string, options *FetchDeviceCodeOptions) (
 	*DeviceCodeResponse, *ErrorResponse, error) {
 
 	parameter := map[string]string{}
@@ -138,7 +142,7 @@ func FetchDeviceCode(deviceAuthorization string, options *FetchDeviceCodeOptions
 	} else {
 		parameter["scope"] = options.Scope
 	}
-	statusCode, deviceCode, err := utils.PostHttp(deviceAuthorization, parameter)
+	statusCode, deviceCode, err := utils.PostHttp(ctx, deviceAuthorization, parameter)
 	if err != nil {
 		idaaslog.Error.PrintfLn("Failed to fetch device code, error: %v", err)
 		return nil, nil, err
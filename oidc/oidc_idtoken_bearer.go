@@ -1,11 +1,13 @@
 package oidc
 
+import "context"
+
 type FetchTokenIdTokenBearerOptions struct {
 	*FetchTokenCommonOptions
 	IdToken string
 }
 
-func FetchTokenIdTokenBearer(tokenEndpoint string, options *FetchTokenIdTokenBearerOptions) (*TokenResponse, *ErrorResponse, error) {
+func FetchTokenIdTokenBearer(ctx context.Context, tokenEndpoint string, options *FetchTokenIdTokenBearerOptions) (*TokenResponse, *ErrorResponse, error) {
 	fetchTokenOptions := &FetchTokenOptions{
 		ClientId:                           options.ClientId,
 		GrantType:                          options.GrantType,
@@ -15,5 +17,5 @@ func FetchTokenIdTokenBearer(tokenEndpoint string, options *FetchTokenIdTokenBea
 		ApplicationFederatedCredentialName: options.ApplicationFederatedCredentialName,
 	}
 
-	return FetchToken(tokenEndpoint, fetchTokenOptions)
+	return FetchToken(ctx, tokenEndpoint, fetchTokenOptions)
 }
@@ -1,11 +1,13 @@
 package oidc
 
+import "context"
+
 type FetchTokenPkcs7BearerOptions struct {
 	*FetchTokenCommonOptions
 	Pkcs7 string
 }
 
-func FetchTokenPkcs7Bearer(tokenEndpoint string, options *FetchTokenPkcs7BearerOptions) (*TokenResponse, *ErrorResponse, error) {
+func FetchTokenPkcs7Bearer(ctx context.Context, tokenEndpoint string, options *FetchTokenPkcs7BearerOptions) (*TokenResponse, *ErrorResponse, error) {
 	fetchTokenOptions := &FetchTokenOptions{
 		ClientId:                           options.ClientId,
 		GrantType:                          options.GrantType,
@@ -15,5 +17,5 @@ func FetchTokenPkcs7Bearer(tokenEndpoint string, options *FetchTokenPkcs7BearerO
 		ApplicationFederatedCredentialName: options.ApplicationFederatedCredentialName,
 	}
 
-	return FetchToken(tokenEndpoint, fetchTokenOptions)
+	return FetchToken(ctx, tokenEndpoint, fetchTokenOptions)
 }
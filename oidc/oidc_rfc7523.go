@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"context"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/signer"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
@@ -12,7 +13,7 @@ type FetchTokenRfc7523Options struct {
 	JwtSigner signer.JwtSigner
 }
 
-func FetchTokenRfc7523(tokenEndpoint string, options *FetchTokenRfc7523Options) (*TokenResponse, *ErrorResponse, error) {
+func FetchTokenRfc7523(ctx context.Context, tokenEndpoint string, options *FetchTokenRfc7523Options) (*TokenResponse, *ErrorResponse, error) {
 	jwtSingerOptions := &signer.JwtSignerOptions{
 		Issuer:   options.ClientId,
 		Audience: options.TokenEndpoint,
@@ -34,5 +35,5 @@ func FetchTokenRfc7523(tokenEndpoint string, options *FetchTokenRfc7523Options)
 		ClientAssertion:     jwtToken,
 	}
 
-	return FetchToken(tokenEndpoint, fetchTokenOptions)
+	return FetchToken(ctx, tokenEndpoint, fetchTokenOptions)
 }
@@ -1,6 +1,7 @@
 package oidc
 
 import (
+	"context"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/signer"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
 	"github.com/pkg/errors"
@@ -14,7 +15,7 @@ type FetchTokenX509JwtBearerOptions struct {
 	JwtSigner       signer.JwtSigner
 }
 
-func FetchTokenX509JwtBearer(tokenEndpoint string, options *FetchTokenX509JwtBearerOptions) (*TokenResponse, *ErrorResponse, error) {
+func FetchTokenX509JwtBearer(ctx context.Context, tokenEndpoint string, options *FetchTokenX509JwtBearerOptions) (*TokenResponse, *ErrorResponse, error) {
 	jwtSingerOptions := &signer.JwtSignerOptions{
 		Issuer:   options.ClientId,
 		Audience: options.TokenEndpoint,
@@ -39,5 +40,5 @@ func FetchTokenX509JwtBearer(tokenEndpoint string, options *FetchTokenX509JwtBea
 		ApplicationFederatedCredentialName: options.ApplicationFederatedCredentialName,
 	}
 
-	return FetchToken(tokenEndpoint, fetchTokenOptions)
+	return FetchToken(ctx, tokenEndpoint, fetchTokenOptions)
 }
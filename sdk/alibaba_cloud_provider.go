@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aliyun/credentials-go/credentials/providers"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/alibaba_cloud"
+)
+
+const AlibabaCloudProviderName = "alibaba_cloud_idaas"
+
+var _ providers.CredentialsProvider = (*AlibabaCloudCredentialsProvider)(nil)
+
+// AlibabaCloudCredentialsProvider implements credentials-go CredentialsProvider, use it with Alibaba Cloud SDKs via
+// credentials.FromCredentialsProvider(sdk.AlibabaCloudProviderName, provider)
+type AlibabaCloudCredentialsProvider struct {
+	client  *Client
+	profile string
+}
+
+func (c *Client) AlibabaCloudCredentialsProvider(profile string) *AlibabaCloudCredentialsProvider {
+	return &AlibabaCloudCredentialsProvider{
+		client:  c,
+		profile: profile,
+	}
+}
+
+func (p *AlibabaCloudCredentialsProvider) GetCredentials() (*providers.Credentials, error) {
+	return p.GetCredentialsWithContext(context.Background())
+}
+
+func (p *AlibabaCloudCredentialsProvider) GetCredentialsWithContext(ctx context.Context) (
+	*providers.Credentials, error) {
+	credential, err := p.client.FetchCredential(ctx, p.profile, nil)
+	if err != nil {
+		return nil, err
+	}
+	stsToken, ok := credential.(*alibaba_cloud.StsToken)
+	if !ok {
+		return nil, credentialTypeError(credential, "Alibaba Cloud")
+	}
+	return &providers.Credentials{
+		AccessKeyId:     stsToken.AccessKeyId,
+		AccessKeySecret: stsToken.AccessKeySecret,
+		SecurityToken:   stsToken.StsToken,
+		ProviderName:    AlibabaCloudProviderName,
+	}, nil
+}
+
+func (p *AlibabaCloudCredentialsProvider) GetProviderName() string {
+	return AlibabaCloudProviderName
+}
@@ -0,0 +1,45 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const AwsProviderSource = "AlibabaCloudIDaaS"
+
+var _ awssdk.CredentialsProvider = (*AwsCredentialsProvider)(nil)
+
+// AwsCredentialsProvider implements AWS SDK CredentialsProvider, wrap it with aws.NewCredentialsCache
+// to reuse credentials until expiring
+type AwsCredentialsProvider struct {
+	client  *Client
+	profile string
+}
+
+func (c *Client) AwsCredentialsProvider(profile string) *AwsCredentialsProvider {
+	return &AwsCredentialsProvider{
+		client:  c,
+		profile: profile,
+	}
+}
+
+func (p *AwsCredentialsProvider) Retrieve(ctx context.Context) (awssdk.Credentials, error) {
+	credential, err := p.client.FetchCredential(ctx, p.profile, nil)
+	if err != nil {
+		return awssdk.Credentials{}, err
+	}
+	awsStsToken, ok := credential.(*aws.AwsStsToken)
+	if !ok {
+		return awssdk.Credentials{}, credentialTypeError(credential, "AWS")
+	}
+	return awssdk.Credentials{
+		AccessKeyID:     awsStsToken.AccessKeyId,
+		SecretAccessKey: awsStsToken.SecretAccessKey,
+		SessionToken:    awsStsToken.SessionToken,
+		Source:          AwsProviderSource,
+		CanExpire:       true,
+		Expires:         awsStsToken.Expiration,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Package sdk fetches cloud credentials of alibaba-cloud-idaas profiles in-process, for Go programs which
+// use the same profiles without shelling out to `alibaba-cloud-idaas fetch-token`.
+//
+// Tokens are cached in the same encrypted cache files as the command line tool.
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/cloud/cloud_common"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/config"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/utils"
+	"github.com/pkg/errors"
+)
+
+type Options struct {
+	// Config optional, config content, default read from ~/.aliyun/alibaba-cloud-idaas.json
+	Config []byte
+	// HttpClient optional, used by OIDC, STS and other HTTP requests
+	HttpClient *http.Client
+}
+
+type FetchCredentialOptions struct {
+	ForceNew   bool
+	PolicyFile string // optional, override session policy, only for Alibaba Cloud
+}
+
+type Client struct {
+	cloudCredentialConfig *config.CloudCredentialConfig
+	httpClient            *http.Client
+}
+
+func New(options *Options) (*Client, error) {
+	client := &Client{}
+	if options == nil {
+		return client, nil
+	}
+	if len(options.Config) > 0 {
+		cloudCredentialConfig, err := config.ParseCloudCredentialConfig(options.Config)
+		if err != nil {
+			return nil, err
+		}
+		client.cloudCredentialConfig = cloudCredentialConfig
+	}
+	client.httpClient = options.HttpClient
+	return client, nil
+}
+
+// FetchCredential fetch credential of profile, default profile is used when profile is empty,
+// returned credential is *alibaba_cloud.StsToken, *aws.AwsStsToken, *gcp.GcpToken, *azure.AzureToken
+// or *oidc.OidcToken
+func (c *Client) FetchCredential(ctx context.Context, profile string, options *FetchCredentialOptions) (
+	cloud_common.Credential, error) {
+	if options == nil {
+		options = &FetchCredentialOptions{}
+	}
+	profile, cloudStsConfig, err := c.FindProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	fetchOptions := &cloud.FetchCloudStsOptions{
+		ForceNew:   options.ForceNew,
+		PolicyFile: options.PolicyFile,
+	}
+	return cloud.FetchCloudSts(c.withHttpClient(ctx), profile, cloudStsConfig, fetchOptions)
+}
+
+// FindProfile find profile from config content, or from default config file when config content is not set
+func (c *Client) FindProfile(profile string) (string, *config.CloudStsConfig, error) {
+	if c.cloudCredentialConfig == nil {
+		return config.FindProfile(profile)
+	}
+	profile, cloudStsConfig := c.cloudCredentialConfig.FindProfile(profile)
+	if cloudStsConfig == nil {
+		return profile, nil, fmt.Errorf("profile: %s not found", profile)
+	}
+	return profile, cloudStsConfig, nil
+}
+
+func (c *Client) withHttpClient(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.httpClient == nil {
+		return ctx
+	}
+	return utils.WithHttpClient(ctx, c.httpClient)
+}
+
+func credentialTypeError(credential cloud_common.Credential, expected string) error {
+	return errors.Errorf("profile credential is %s, %s is required", credential.CloudName(), expected)
+}
@@ -175,7 +175,8 @@ func (s *ExJwtSigner) SignJwtWithOptions(claim map[string]interface{}, options *
 	} else if options.CustomJti != nil {
 		claim["jti"] = options.CustomJti()
 	}
-	nowSeconds := time.Now().Unix()
+	// compensated with clock skew of audience(token endpoint), server rejects assertion issued in the future or expired
+	nowSeconds := utils.NowFor(options.Audience).Unix()
 	claim["iat"] = nowSeconds
 	claim["exp"] = nowSeconds + int64(options.Validity.Seconds())
 
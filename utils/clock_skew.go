@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
+)
+
+const (
+	// ClockSkewTolerance skew under tolerance is ignored, HTTP Date header only has seconds precision
+	ClockSkewTolerance = 2 * time.Second
+	// ClockSkewWarnThreshold warn when skew exceeds threshold
+	ClockSkewWarnThreshold = 1 * time.Minute
+	// ClockSkewMaxAge skew observed before max age is ignored, local clock may have been synced
+	ClockSkewMaxAge = 7 * 24 * time.Hour
+
+	clockSkewCacheKey = "server_dates"
+)
+
+// ClockSkew estimated skew between server time and local time, server time = local time + skew
+type ClockSkew struct {
+	SkewMillis int64 `json:"skew_millis"`
+	ObservedAt int64 `json:"observed_at"` // Unix Epoch(milliseconds), local time
+}
+
+// clockSkews skew of every server host, servers do not share skew as their clocks may differ,
+// e.g. the metadata server of a VM, IdP and STS
+var clockSkews = map[string]*ClockSkew{}
+var clockSkewMutex sync.Mutex
+var clockSkewLoadOnce sync.Once
+var clockSkewWarnOnce sync.Once
+
+// NowFor returns local time compensated with clock skew of server host, use it for signing requests sent to
+// the server and checking expiry of credentials issued by the server, host may be a URL
+func NowFor(host string) time.Time {
+	return time.Now().Add(GetClockSkew(host))
+}
+
+// UntilFor like time.Until, compensated with clock skew of server host
+func UntilFor(host string, t time.Time) time.Duration {
+	return t.Sub(NowFor(host))
+}
+
+// GetClockSkew returns clock skew estimate of server host, 0 when it is not observed, loaded from cache at first call
+func GetClockSkew(host string) time.Duration {
+	if os.Getenv(constants.EnvDisableClockSkew) == "true" {
+		return 0
+	}
+	hostname := normalizeClockSkewHost(host)
+	if hostname == "" {
+		return 0
+	}
+	clockSkewLoadOnce.Do(loadClockSkews)
+	clockSkewMutex.Lock()
+	defer clockSkewMutex.Unlock()
+	clockSkew := clockSkews[hostname]
+	if clockSkew == nil || time.Since(time.UnixMilli(clockSkew.ObservedAt)) > ClockSkewMaxAge {
+		return 0
+	}
+	return time.Duration(clockSkew.SkewMillis) * time.Millisecond
+}
+
+// ObserveServerDate estimate clock skew from response Date header, requestTime is local time before request sent
+func ObserveServerDate(resp *http.Response, requestTime time.Time) {
+	if resp == nil {
+		return
+	}
+	host := ""
+	if resp.Request != nil && resp.Request.URL != nil {
+		host = resp.Request.URL.Host
+	}
+	ObserveServerDateHeader(resp.Header.Get("Date"), requestTime, time.Now(), host)
+}
+
+// ObserveServerDateHeader estimate clock skew from Date header(RFC 7231), e.g. response headers of cloud SDKs
+func ObserveServerDateHeader(date string, requestTime, responseTime time.Time, host string) {
+	if date == "" {
+		return
+	}
+	serverDate, err := http.ParseTime(date)
+	if err != nil {
+		idaaslog.Debug.PrintfLn("Parse server date: %s failed: %v", date, err)
+		return
+	}
+	ObserveServerTime(serverDate, requestTime, responseTime, host)
+}
+
+// ObserveServerTime estimate clock skew of server host from server time, skew under ClockSkewTolerance
+// (or round trip time) is treated as no skew, local servers are ignored as they share the same clock
+func ObserveServerTime(serverTime, requestTime, responseTime time.Time, host string) {
+	hostname := normalizeClockSkewHost(host)
+	if hostname == "" {
+		return
+	}
+	if ip := net.ParseIP(hostname); hostname == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return
+	}
+	UpdateClockSkew(hostname, estimateClockSkew(serverTime, requestTime, responseTime))
+}
+
+// estimateClockSkew server time is truncated to seconds, compare with the middle of round trip
+func estimateClockSkew(serverTime, requestTime, responseTime time.Time) time.Duration {
+	roundTrip := responseTime.Sub(requestTime)
+	localTime := requestTime.Add(roundTrip / 2)
+	skew := serverTime.Add(500 * time.Millisecond).Sub(localTime)
+	if skew.Abs() <= max(ClockSkewTolerance, roundTrip) {
+		return 0
+	}
+	return skew
+}
+
+// UpdateClockSkew update clock skew estimate of server host, write to cache when changed
+func UpdateClockSkew(host string, skew time.Duration) {
+	hostname := normalizeClockSkewHost(host)
+	if hostname == "" {
+		return
+	}
+	clockSkewLoadOnce.Do(loadClockSkews)
+	clockSkewMutex.Lock()
+	defer clockSkewMutex.Unlock()
+	var previousSkew time.Duration
+	if previousClockSkew := clockSkews[hostname]; previousClockSkew != nil {
+		previousSkew = time.Duration(previousClockSkew.SkewMillis) * time.Millisecond
+	}
+	clockSkews[hostname] = &ClockSkew{
+		SkewMillis: skew.Milliseconds(),
+		ObservedAt: time.Now().UnixMilli(),
+	}
+
+	if skew.Abs() > ClockSkewWarnThreshold {
+		clockSkewWarnOnce.Do(func() {
+			Stderr.Fprintf("[WARN] Local clock is skewed %s from server %s, compensated, please sync clock\n",
+				skew.Round(time.Second), hostname)
+		})
+	}
+	if (skew - previousSkew).Abs() <= ClockSkewTolerance {
+		return
+	}
+	idaaslog.Warn.PrintfLn("Clock skew of %s changed: %s -> %s", hostname, previousSkew, skew)
+	// other processes may have observed other hosts
+	cachedClockSkews := readClockSkews()
+	cachedClockSkews[hostname] = clockSkews[hostname]
+	clockSkewsJson, err := json.Marshal(cachedClockSkews)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Marshal clock skews failed: %v", err)
+		return
+	}
+	err = writeCacheFile(constants.CategoryClockSkew, clockSkewCacheKey, clockSkewsJson)
+	if err != nil {
+		idaaslog.Error.PrintfLn("Write clock skews failed: %v", err)
+	}
+}
+
+func loadClockSkews() {
+	cachedClockSkews := readClockSkews()
+	clockSkewMutex.Lock()
+	defer clockSkewMutex.Unlock()
+	for hostname, clockSkew := range cachedClockSkews {
+		if _, ok := clockSkews[hostname]; !ok {
+			clockSkews[hostname] = clockSkew
+		}
+	}
+	idaaslog.Debug.PrintfLn("Load clock skews of %d host(s)", len(cachedClockSkews))
+}
+
+func readClockSkews() map[string]*ClockSkew {
+	cachedClockSkews := map[string]*ClockSkew{}
+	content, err := readCacheFile(constants.CategoryClockSkew, clockSkewCacheKey)
+	if err != nil || content == nil {
+		return cachedClockSkews
+	}
+	err = json.Unmarshal(content, &cachedClockSkews)
+	if err != nil {
+		idaaslog.Warn.PrintfLn("Parse clock skews failed: %v, ignore error", err)
+		return map[string]*ClockSkew{}
+	}
+	for hostname, clockSkew := range cachedClockSkews {
+		if clockSkew == nil {
+			delete(cachedClockSkews, hostname)
+		}
+	}
+	return cachedClockSkews
+}
+
+// normalizeClockSkewHost returns lower case hostname of host, host:port or URL
+func normalizeClockSkewHost(host string) string {
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return ""
+		}
+		host = u.Host
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
+)
+
+func TestEstimateClockSkew(t *testing.T) {
+	requestTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		serverTime time.Time
+		roundTrip  time.Duration
+		expected   time.Duration
+	}{
+		{name: "no skew", serverTime: requestTime, roundTrip: time.Second, expected: 0},
+		{name: "under tolerance", serverTime: requestTime.Add(2 * time.Second), roundTrip: time.Second, expected: 0},
+		{name: "server ahead", serverTime: requestTime.Add(time.Minute), roundTrip: time.Second, expected: time.Minute},
+		{name: "server behind", serverTime: requestTime.Add(-time.Minute), roundTrip: time.Second, expected: -time.Minute},
+		{name: "under round trip", serverTime: requestTime.Add(8 * time.Second), roundTrip: 10 * time.Second, expected: 0},
+		{
+			name:       "over round trip",
+			serverTime: requestTime.Add(20 * time.Second),
+			roundTrip:  10 * time.Second,
+			expected:   15500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew := estimateClockSkew(tt.serverTime, requestTime, requestTime.Add(tt.roundTrip))
+			if skew != tt.expected {
+				t.Errorf("expected skew: %s, got: %s", tt.expected, skew)
+			}
+		})
+	}
+}
+
+func TestNormalizeClockSkewHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{host: "", expected: ""},
+		{host: "sts.aliyuncs.com", expected: "sts.aliyuncs.com"},
+		{host: "STS.Aliyuncs.com:443", expected: "sts.aliyuncs.com"},
+		{host: "https://idaas.example.com/api/v2/token?a=b", expected: "idaas.example.com"},
+		{host: "http://127.0.0.1:18777", expected: "127.0.0.1"},
+		{host: "[::1]:8080", expected: "::1"},
+		{host: "https://[::1]:8080/token", expected: "::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			hostname := normalizeClockSkewHost(tt.host)
+			if hostname != tt.expected {
+				t.Errorf("expected: %s, got: %s", tt.expected, hostname)
+			}
+		})
+	}
+}
+
+func TestGetClockSkew(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(constants.EnvDisableClockSkew, "")
+	requestTime := time.Now()
+	responseTime := requestTime.Add(time.Second)
+	serverTime := requestTime.Add(5 * time.Minute)
+	ObserveServerTime(serverTime, requestTime, responseTime, "https://sts.example.com/")
+	ObserveServerTime(serverTime, requestTime, responseTime, "127.0.0.1:18777")
+	ObserveServerTime(serverTime, requestTime, responseTime, "localhost:18778")
+	UpdateClockSkew("expired.example.com", time.Hour)
+	clockSkewMutex.Lock()
+	clockSkews["expired.example.com"].ObservedAt = time.Now().Add(-ClockSkewMaxAge - time.Minute).UnixMilli()
+	clockSkewMutex.Unlock()
+
+	tests := []struct {
+		name     string
+		host     string
+		expected time.Duration
+	}{
+		{name: "observed host", host: "sts.example.com", expected: 5 * time.Minute},
+		{name: "observed host with port", host: "STS.example.com:443", expected: 5 * time.Minute},
+		{name: "observed host URL", host: "https://sts.example.com/token", expected: 5 * time.Minute},
+		{name: "other host", host: "idaas.example.com", expected: 0},
+		{name: "loopback ignored", host: "127.0.0.1", expected: 0},
+		{name: "localhost ignored", host: "localhost", expected: 0},
+		{name: "expired skew", host: "expired.example.com", expected: 0},
+		{name: "empty host", host: "", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew := GetClockSkew(tt.host)
+			if skew != tt.expected {
+				t.Errorf("expected skew: %s, got: %s", tt.expected, skew)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(constants.EnvDisableClockSkew, "true")
+		if skew := GetClockSkew("sts.example.com"); skew != 0 {
+			t.Errorf("expected skew: 0, got: %s", skew)
+		}
+		if until := UntilFor("sts.example.com", requestTime.Add(time.Hour)); until > time.Hour {
+			t.Errorf("expected until not compensated, got: %s", until)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		cachedClockSkews := readClockSkews()
+		if clockSkew := cachedClockSkews["sts.example.com"]; clockSkew == nil || clockSkew.SkewMillis != 300000 {
+			t.Errorf("expected cached skew of sts.example.com, got: %v", clockSkew)
+		}
+		if _, ok := cachedClockSkews["127.0.0.1"]; ok {
+			t.Errorf("loopback skew should not be cached")
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// WriteFileAtomic writes content to temp file in the same dir then renames it, readers never see partial content
+func WriteFileAtomic(filename string, content []byte, perm os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+"-*")
+	if err != nil {
+		return errors.Wrapf(err, "create temp file of: %s failed", filename)
+	}
+	tempFilename := tempFile.Name()
+	defer func() {
+		// removed when rename failed
+		_ = os.Remove(tempFilename)
+	}()
+	if _, err = tempFile.Write(content); err != nil {
+		_ = tempFile.Close()
+		return errors.Wrapf(err, "write temp file: %s failed", tempFilename)
+	}
+	if err = tempFile.Close(); err != nil {
+		return errors.Wrapf(err, "close temp file: %s failed", tempFilename)
+	}
+	if err = os.Chmod(tempFilename, perm); err != nil {
+		return errors.Wrapf(err, "chmod temp file: %s failed", tempFilename)
+	}
+	if err = os.Rename(tempFilename, filename); err != nil {
+		return errors.Wrapf(err, "rename temp file: %s to: %s failed", tempFilename, filename)
+	}
+	return nil
+}
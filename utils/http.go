@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/constants"
 	"github.com/aliyunidaas/alibaba-cloud-idaas/idaaslog"
@@ -23,8 +26,25 @@ const (
 
 var UserAgent = getUserAgent()
 
-func PostHttp(postUrl string, parameters map[string]string) (int, string, error) {
-	client := BuildHttpClient()
+type httpClientContextKey struct{}
+
+// WithHttpClient returns context carries http client, which is used by requests made with the context
+func WithHttpClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientContextKey{}, client)
+}
+
+// GetHttpClient returns http client carried by context, or a new default http client
+func GetHttpClient(ctx context.Context) *http.Client {
+	if ctx != nil {
+		if client, ok := ctx.Value(httpClientContextKey{}).(*http.Client); ok && client != nil {
+			return client
+		}
+	}
+	return BuildHttpClient()
+}
+
+func PostHttp(ctx context.Context, postUrl string, parameters map[string]string) (int, string, error) {
+	client := GetHttpClient(ctx)
 	postBody := ""
 	for key, value := range parameters {
 		if len(postBody) > 0 {
@@ -32,17 +52,19 @@ func PostHttp(postUrl string, parameters map[string]string) (int, string, error)
 		}
 		postBody += url.QueryEscape(key) + "=" + url.QueryEscape(value)
 	}
-	req, err := http.NewRequest(HttpMethodPost, postUrl, strings.NewReader(postBody))
+	req, err := http.NewRequestWithContext(ctx, HttpMethodPost, postUrl, strings.NewReader(postBody))
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "new request: %s", postUrl)
 	}
 	req.Header.Set("User-Agent", UserAgent)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	requestTime := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "do post request: %s", postUrl)
 	}
 	defer resp.Body.Close()
+	ObserveServerDate(resp, requestTime)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "read response body: %s", postUrl)
@@ -50,18 +72,49 @@ func PostHttp(postUrl string, parameters map[string]string) (int, string, error)
 	return resp.StatusCode, string(body), nil
 }
 
-func GetHttp(getUrl string) (int, string, error) {
-	client := BuildHttpClient()
-	req, err := http.NewRequest(HttpMethodGet, getUrl, nil)
+func PostJsonHttp(ctx context.Context, postUrl string, headers map[string]string, body any) (int, string, error) {
+	client := GetHttpClient(ctx)
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "marshal request body: %s", postUrl)
+	}
+	req, err := http.NewRequestWithContext(ctx, HttpMethodPost, postUrl, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "new request: %s", postUrl)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	requestTime := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "do post request: %s", postUrl)
+	}
+	defer resp.Body.Close()
+	ObserveServerDate(resp, requestTime)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "read response body: %s", postUrl)
+	}
+	return resp.StatusCode, string(respBody), nil
+}
+
+func GetHttp(ctx context.Context, getUrl string) (int, string, error) {
+	client := GetHttpClient(ctx)
+	req, err := http.NewRequestWithContext(ctx, HttpMethodGet, getUrl, nil)
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "new request: %s", getUrl)
 	}
 	req.Header.Set("User-Agent", UserAgent)
+	requestTime := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "do get request: %s", getUrl)
 	}
 	defer resp.Body.Close()
+	ObserveServerDate(resp, requestTime)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return 0, "", errors.Wrapf(err, "read response body: %s", getUrl)
@@ -69,16 +122,18 @@ func GetHttp(getUrl string) (int, string, error) {
 	return resp.StatusCode, string(body), nil
 }
 
-func FetchAsString(client *http.Client, method, endpoint string, headers map[string]string) (string, error) {
-	body, err := Fetch(client, method, endpoint, headers)
+func FetchAsString(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string) (
+	string, error) {
+	body, err := Fetch(ctx, client, method, endpoint, headers)
 	if err != nil {
 		return "", err
 	}
 	return string(body), nil
 }
 
-func Fetch(client *http.Client, method, endpoint string, headers map[string]string) ([]byte, error) {
-	req, err := http.NewRequest(method, endpoint, nil)
+func Fetch(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string) (
+	[]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "new request: "+endpoint)
 	}
@@ -88,11 +143,13 @@ func Fetch(client *http.Client, method, endpoint string, headers map[string]stri
 	}
 	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "3600")
 
+	requestTime := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, errors.Wrapf(err, "do %s request: %s", method, endpoint)
 	}
 	defer resp.Body.Close()
+	ObserveServerDate(resp, requestTime)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, errors.Wrapf(err, "read response body: %s", endpoint)
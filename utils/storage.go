@@ -324,6 +324,19 @@ func readCacheFile(category, key string) ([]byte, error) {
 	return os.ReadFile(cacheFile)
 }
 
+// RemoveCacheFile remove cache file, cache file not exists is ignored
+func RemoveCacheFile(category, key string) error {
+	cacheFile, err := getCacheFile(category, key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(cacheFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "remove cache file [%s, %s] failed", category, key)
+	}
+	return nil
+}
+
 func getCacheFile(category, key string) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"crypto"
 	"encoding/asn1"
 	"encoding/hex"
@@ -38,14 +39,22 @@ func Sha256ToHex(message string) string {
 	return hex.EncodeToString(hashBytes)
 }
 
-func SleepSeconds(interval int64) {
+// SleepSeconds returns context error when context is done before sleep ends
+func SleepSeconds(ctx context.Context, interval int64) error {
 	sleepInterval := 2
 	if interval <= 0 {
 		sleepInterval = 2
 	} else if interval > 5 {
 		sleepInterval = 5
 	}
-	time.Sleep(time.Duration(sleepInterval) * time.Second)
+	timer := time.NewTimer(time.Duration(sleepInterval) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 type ECDSASignature struct {